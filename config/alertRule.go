@@ -0,0 +1,102 @@
+package config
+
+import "github.com/avanha/pmaas-plugin-netmon/events"
+
+type AlertMetric int
+
+const (
+	// AlertMetricPingRtt is the average ping round trip time, in milliseconds.
+	AlertMetricPingRtt AlertMetric = iota + 1
+
+	// AlertMetricPingPacketLoss is the ping packet loss, in percent.
+	AlertMetricPingPacketLoss
+
+	// AlertMetricInterfaceErrorRate is the combined incoming and outgoing error rate of an interface, in errors
+	// per second.
+	AlertMetricInterfaceErrorRate
+
+	// AlertMetricInterfaceUtilization is the utilization of an interface, in percent of its speed, taking the
+	// busier direction.
+	AlertMetricInterfaceUtilization
+
+	// AlertMetricInterfaceDown fires while an interface's operational status is down.  The threshold is not used.
+	AlertMetricInterfaceDown
+
+	// AlertMetricCertificateExpiryDays is the number of days until the host's TLS certificate expires.  Unlike
+	// the other metrics, the alert fires when the value drops below the threshold.
+	AlertMetricCertificateExpiryDays
+)
+
+func (m AlertMetric) String() string {
+	switch m {
+	case AlertMetricPingRtt:
+		return "Ping RTT"
+	case AlertMetricPingPacketLoss:
+		return "Packet loss"
+	case AlertMetricInterfaceErrorRate:
+		return "Error rate"
+	case AlertMetricInterfaceUtilization:
+		return "Utilization"
+	case AlertMetricInterfaceDown:
+		return "Interface down"
+	case AlertMetricCertificateExpiryDays:
+		return "Certificate expiry"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsInterfaceMetric returns true if the metric is evaluated per interface, rather than per host.
+func (m AlertMetric) IsInterfaceMetric() bool {
+	return m == AlertMetricInterfaceErrorRate ||
+		m == AlertMetricInterfaceUtilization ||
+		m == AlertMetricInterfaceDown
+}
+
+// AlertRule is a declarative condition over a host or interface metric.  An alert fires once the condition
+// has held continuously for DurationSeconds, and resolves as soon as it no longer holds.
+type AlertRule struct {
+	Name            string
+	Metric          AlertMetric
+	Threshold       float64
+	DurationSeconds int
+	Severity        events.AlertSeverity
+
+	// Hosts and HostGroups select the hosts the rule applies to.  If both are empty, it applies to all hosts.
+	Hosts      []string
+	HostGroups []string
+
+	// Interfaces limits interface rules to the interfaces with the given tracking names.  If empty, interface
+	// rules apply to all the configured interfaces of the selected hosts.
+	Interfaces []string
+}
+
+func (r *AlertRule) ForHosts(hostNames ...string) *AlertRule {
+	r.Hosts = append(r.Hosts, hostNames...)
+
+	return r
+}
+
+func (r *AlertRule) ForHostGroups(groupNames ...string) *AlertRule {
+	r.HostGroups = append(r.HostGroups, groupNames...)
+
+	return r
+}
+
+func (r *AlertRule) ForInterfaces(interfaceNames ...string) *AlertRule {
+	r.Interfaces = append(r.Interfaces, interfaceNames...)
+
+	return r
+}
+
+func (r *AlertRule) WithDuration(seconds int) *AlertRule {
+	r.DurationSeconds = seconds
+
+	return r
+}
+
+func (r *AlertRule) WithSeverity(severity events.AlertSeverity) *AlertRule {
+	r.Severity = severity
+
+	return r
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/avanha/pmaas-plugin-netmon/events"
 )
@@ -17,6 +18,102 @@ type Host struct {
 	PingUseIcmp        bool
 	SnmpEnabled        bool
 	NetInterfaces      map[string]*NetInterface
+
+	// ReachabilityDownThreshold is the number of consecutive failed scans required before the host is
+	// declared unreachable.  Values less than 1 are treated as 1.
+	ReachabilityDownThreshold int
+
+	// ReachabilityUpThreshold is the number of consecutive successful scans required before the host is
+	// declared reachable (or degraded) again.  Values less than 1 are treated as 1.
+	ReachabilityUpThreshold int
+
+	// DegradedPacketLossPercent is the ping packet loss, in percent, at or above which a responding host is
+	// considered degraded rather than reachable.  Zero disables the degraded state.
+	DegradedPacketLossPercent float64
+
+	// FlapWindowSeconds is the length of the sliding window used for flap detection.  Zero disables flap
+	// detection.
+	FlapWindowSeconds int
+
+	// FlapThreshold is the number of reachability changes within FlapWindowSeconds that marks the host as
+	// flapping.  The host stops flapping once the number of changes in the window drops to half this value.
+	FlapThreshold int
+
+	// DependsOn lists the names of the hosts this host is reached through, such as the router in front of it.
+	// When all of them are unreachable, this host is considered unreachable due to its parent.
+	DependsOn []string
+
+	// SuppressEventsWhenParentUnreachable prevents reachability change events from being broadcast for
+	// changes caused by a parent becoming unreachable, and for the recovery that follows.
+	SuppressEventsWhenParentUnreachable bool
+
+	// Groups lists the host groups this host belongs to.  Maintenance windows and alert rules can target groups.
+	Groups []string
+
+	// CertificateCheckAddress is the host:port of a TLS service whose certificate expiry is checked with every
+	// scan, for example "www.example.com:443".  Empty disables the check.
+	CertificateCheckAddress string
+
+	// PersistEventJournal stores the host's recent events with its tracked data, so the event journal survives
+	// restarts.
+	PersistEventJournal bool
+
+	// Collectors overrides the settings of the host's data collectors, by collector name, such as "ping",
+	// "snmp" or "certificate".  Collectors that aren't listed run with every scan, if the host's other settings
+	// enable them.
+	Collectors map[string]*CollectorConfig
+
+	eventListeners []EventListener
+}
+
+func (h *Host) AddDependency(parentHostName string) *Host {
+	h.DependsOn = append(h.DependsOn, parentHostName)
+
+	return h
+}
+
+func (h *Host) AddToGroup(groupName string) *Host {
+	h.Groups = append(h.Groups, groupName)
+
+	return h
+}
+
+// ConfigureCollector returns the settings of the named collector, adding them if needed.
+func (h *Host) ConfigureCollector(name string) *CollectorConfig {
+	if h.Collectors == nil {
+		h.Collectors = make(map[string]*CollectorConfig)
+	}
+
+	collectorConfig, ok := h.Collectors[name]
+
+	if !ok {
+		collectorConfig = &CollectorConfig{}
+		h.Collectors[name] = collectorConfig
+	}
+
+	return collectorConfig
+}
+
+// CollectorEnabled returns false if the named collector is disabled for the host.
+func (h *Host) CollectorEnabled(name string) bool {
+	collectorConfig, ok := h.Collectors[name]
+
+	return !ok || !collectorConfig.Disabled
+}
+
+// CollectorInterval returns the configured minimum time between runs of the named collector, or zero.
+func (h *Host) CollectorInterval(name string) time.Duration {
+	collectorConfig, ok := h.Collectors[name]
+
+	if !ok {
+		return 0
+	}
+
+	return time.Duration(collectorConfig.IntervalSeconds) * time.Second
+}
+
+func (h *Host) EventListeners() []EventListener {
+	return slices.Clone(h.eventListeners)
 }
 
 func (h *Host) AddNetInterfaceByName(name string) *NetInterface {
@@ -40,16 +137,94 @@ func (h *Host) AddNetInterfaceByPhysicalAddress(physAddress string) *NetInterfac
 	return netInterface
 }
 
+// CollectorConfig holds the per-host settings of a data collector.
+type CollectorConfig struct {
+	// Disabled prevents the collector from running, even if the host's other settings enable it.
+	Disabled bool
+
+	// IntervalSeconds is the minimum time between runs of the collector.  Zero runs it with every scan.
+	// Intervals shorter than the scan interval have no effect.
+	IntervalSeconds int
+}
+
+func (c *CollectorConfig) Disable() *CollectorConfig {
+	c.Disabled = true
+	return c
+}
+
+func (c *CollectorConfig) WithIntervalSeconds(seconds int) *CollectorConfig {
+	c.IntervalSeconds = seconds
+	return c
+}
+
 const InterfaceByIndex = 1
 const InterfaceByName = 2
 const InterfaceByPhysAddress = 3
 
+// DataCapDirection selects the traffic that counts against an interface's data cap.
+type DataCapDirection int
+
+const (
+	// DataCapInAndOut counts both incoming and outgoing traffic.
+	DataCapInAndOut DataCapDirection = iota
+
+	// DataCapIn only counts incoming traffic.
+	DataCapIn
+
+	// DataCapOut only counts outgoing traffic.
+	DataCapOut
+)
+
+func (d DataCapDirection) String() string {
+	switch d {
+	case DataCapInAndOut:
+		return "In and out"
+	case DataCapIn:
+		return "In"
+	case DataCapOut:
+		return "Out"
+	default:
+		return "Unknown"
+	}
+}
+
+// DefaultDataCapAlertPercents are the percentages of the data cap at which events are raised, unless configured
+// otherwise.
+var DefaultDataCapAlertPercents = []float64{75, 90, 100}
+
 type NetInterface struct {
-	Index                    int32
-	Name                     string
-	PhysAddress              string
-	IdentificationMode       int
-	onAddressChangeListeners []func(event events.HostInterfaceAddressChangeEvent)
+	Index              int32
+	Name               string
+	PhysAddress        string
+	IdentificationMode int
+
+	// ErrorRateThreshold is the combined incoming and outgoing error rate, in errors per second, above which a
+	// HostInterfaceErrorRateEvent is raised.  Zero disables the event.
+	ErrorRateThreshold float64
+
+	// DiscardRateThreshold is the combined incoming and outgoing discard rate, in discards per second, above
+	// which a HostInterfaceDiscardRateEvent is raised.  Zero disables the event.
+	DiscardRateThreshold float64
+
+	// BroadcastStormThreshold is the broadcast packet rate, in packets per second in the busier direction, above
+	// which a HostInterfaceBroadcastStormEvent is raised.  Zero disables the event.
+	BroadcastStormThreshold float64
+
+	// BillingCycleStartDay is the day of the month on which the billing cycle starts.  In months that are
+	// shorter, the cycle starts on the last day of the month.  Values less than 1 are treated as 1.
+	BillingCycleStartDay int
+
+	// DataCap is the number of bytes that may be transferred per billing cycle.  Zero means there is no cap.
+	DataCap uint64
+
+	// DataCapDirection selects the traffic that counts against the data cap.
+	DataCapDirection DataCapDirection
+
+	// DataCapAlertPercents are the percentages of the data cap at which a HostInterfaceDataCapEvent is raised,
+	// once per billing cycle.  DefaultDataCapAlertPercents is used if empty.
+	DataCapAlertPercents []float64
+
+	eventListeners []EventListener
 }
 
 func (i *NetInterface) TrackingName() string {
@@ -65,17 +240,43 @@ func (i *NetInterface) TrackingName() string {
 	}
 }
 
+func (i *NetInterface) WithErrorRateThreshold(errorsPerSecond float64) *NetInterface {
+	i.ErrorRateThreshold = errorsPerSecond
+	return i
+}
+
+func (i *NetInterface) WithDiscardRateThreshold(discardsPerSecond float64) *NetInterface {
+	i.DiscardRateThreshold = discardsPerSecond
+	return i
+}
+
+func (i *NetInterface) WithBroadcastStormThreshold(packetsPerSecond float64) *NetInterface {
+	i.BroadcastStormThreshold = packetsPerSecond
+	return i
+}
+
+func (i *NetInterface) WithBillingCycleStartDay(day int) *NetInterface {
+	i.BillingCycleStartDay = day
+	return i
+}
+
+func (i *NetInterface) WithDataCap(bytes uint64, direction DataCapDirection) *NetInterface {
+	i.DataCap = bytes
+	i.DataCapDirection = direction
+	return i
+}
+
+func (i *NetInterface) WithDataCapAlertPercents(percents ...float64) *NetInterface {
+	i.DataCapAlertPercents = percents
+	return i
+}
+
 func (i *NetInterface) AddOnIpAddressChangeListener(eventListener func(event events.HostInterfaceAddressChangeEvent)) {
-	if i.onAddressChangeListeners == nil {
-		i.onAddressChangeListeners = make([]func(event events.HostInterfaceAddressChangeEvent), 1)
-		i.onAddressChangeListeners[0] = eventListener
-	} else {
-		i.onAddressChangeListeners = append(i.onAddressChangeListeners, eventListener)
-	}
+	AddNetInterfaceEventListener(i, eventListener, nil)
 }
 
-func (i *NetInterface) OnAddressChangeListeners() []func(event events.HostInterfaceAddressChangeEvent) {
-	return slices.Clone(i.onAddressChangeListeners)
+func (i *NetInterface) EventListeners() []EventListener {
+	return slices.Clone(i.eventListeners)
 }
 
 func GetInterfaceNameKey(name string) string {
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/events"
+)
+
+// EventListener is a listener for a single type of event, registered in the configuration.  Listeners are created
+// with AddHostEventListener and AddNetInterfaceEventListener, and invoked on the server's main goroutine.
+type EventListener struct {
+	eventType reflect.Type
+	accepts   func(event any) bool
+	invoke    func(event any)
+}
+
+func newEventListener[E any](listener func(event E), predicate func(event E) bool) EventListener {
+	return EventListener{
+		eventType: reflect.TypeFor[E](),
+		accepts: func(event any) bool {
+			typedEvent, ok := event.(E)
+
+			return ok && (predicate == nil || predicate(typedEvent))
+		},
+		invoke: func(event any) {
+			listener(event.(E))
+		},
+	}
+}
+
+func (l *EventListener) EventType() reflect.Type {
+	return l.eventType
+}
+
+// Accepts returns true if the event is of the listener's type, and passes its predicate.
+func (l *EventListener) Accepts(event any) bool {
+	return l.accepts(event)
+}
+
+// Invoke passes the event to the listener.  The event must be one the listener accepts.
+func (l *EventListener) Invoke(event any) {
+	l.invoke(event)
+}
+
+// AddHostEventListener adds a listener for events of type E raised by the host, including those of its
+// interfaces.  If predicate is not nil, only the events it returns true for are passed to the listener.
+func AddHostEventListener[E any](host *Host, listener func(event E), predicate func(event E) bool) {
+	host.eventListeners = append(host.eventListeners, newEventListener(listener, predicate))
+}
+
+// AddNetInterfaceEventListener adds a listener for events of type E raised by the interface.  E should be one of
+// the interface events, which embed events.HostInterfaceEvent.  If predicate is not nil, only the events it
+// returns true for are passed to the listener.
+func AddNetInterfaceEventListener[E any](netInterface *NetInterface, listener func(event E), predicate func(event E) bool) {
+	netInterface.eventListeners = append(netInterface.eventListeners, newEventListener(listener, predicate))
+}
+
+// ReachabilityChangesTo returns a predicate that accepts changes to the given reachability, such as
+// data.ReachabilityUnreachable.
+func ReachabilityChangesTo(reachability int) func(event events.HostReachabilityChangeEvent) bool {
+	return func(event events.HostReachabilityChangeEvent) bool {
+		return event.NewValue == reachability
+	}
+}
+
+// InterfaceStatusChangesTo returns a predicate that accepts changes to the given interface status, such as "Down".
+func InterfaceStatusChangesTo(status string) func(event events.HostInterfaceStatusChangeEvent) bool {
+	return func(event events.HostInterfaceStatusChangeEvent) bool {
+		return event.NewValue == status && event.OldValue != status
+	}
+}
+
+// InterfaceFails returns a predicate that accepts changes to a failed interface status, Down or Lower Layer Down,
+// while ignoring interfaces that were disabled administratively.
+func InterfaceFails() func(event events.HostInterfaceStatusChangeEvent) bool {
+	return func(event events.HostInterfaceStatusChangeEvent) bool {
+		return !event.IsAdminDown() && data.IsFailedStatus(event.NewValue) && !data.IsFailedStatus(event.OldValue)
+	}
+}
+
+// PacketLossAtLeast returns a predicate that accepts changes to a packet loss of at least the given percentage.
+func PacketLossAtLeast(percent float64) func(event events.HostPingPacketLossChangeEvent) bool {
+	return func(event events.HostPingPacketLossChangeEvent) bool {
+		return event.NewValue >= percent
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/events"
+)
+
+func TestAddHostEventListener_Predicate_OnlyAcceptsMatchingEvents(t *testing.T) {
+	pluginConfig := PluginConfig{}
+	host := pluginConfig.AddHost("router", "192.168.1.1")
+	var received []events.HostReachabilityChangeEvent
+	AddHostEventListener(host, func(event events.HostReachabilityChangeEvent) {
+		received = append(received, event)
+	}, ReachabilityChangesTo(data.ReachabilityUnreachable))
+
+	listeners := host.EventListeners()
+
+	if len(listeners) != 1 {
+		t.Fatalf("Expected 1 listener, got %d", len(listeners))
+	}
+
+	down := events.HostReachabilityChangeEvent{NewValue: data.ReachabilityUnreachable}
+	up := events.HostReachabilityChangeEvent{NewValue: data.ReachabilityReachable}
+
+	if !listeners[0].Accepts(down) {
+		t.Errorf("Expected the listener to accept a change to unreachable")
+	}
+
+	if listeners[0].Accepts(up) {
+		t.Errorf("Expected the listener not to accept a change to reachable")
+	}
+
+	if listeners[0].Accepts(events.HostFlappingChangeEvent{}) {
+		t.Errorf("Expected the listener not to accept another event type")
+	}
+
+	listeners[0].Invoke(down)
+
+	if len(received) != 1 || received[0].NewValue != data.ReachabilityUnreachable {
+		t.Errorf("Expected the event to be passed to the listener, got %v", received)
+	}
+}
+
+func TestAddOnIpAddressChangeListener_AddsTypedListener(t *testing.T) {
+	pluginConfig := PluginConfig{}
+	netInterface := pluginConfig.AddHost("router", "192.168.1.1").AddNetInterfaceByName("eth0")
+	netInterface.AddOnIpAddressChangeListener(func(event events.HostInterfaceAddressChangeEvent) {})
+	AddNetInterfaceEventListener(netInterface, func(event events.HostInterfaceStatusChangeEvent) {},
+		InterfaceStatusChangesTo("Down"))
+
+	listeners := netInterface.EventListeners()
+
+	if len(listeners) != 2 {
+		t.Fatalf("Expected 2 listeners, got %d", len(listeners))
+	}
+
+	if !listeners[0].Accepts(events.HostInterfaceAddressChangeEvent{}) {
+		t.Errorf("Expected the listener to accept address changes")
+	}
+
+	if listeners[1].Accepts(events.HostInterfaceStatusChangeEvent{NewValue: "Up"}) {
+		t.Errorf("Expected the listener not to accept a change to Up")
+	}
+}
+
+func TestInterfaceFails_AdminDown_NotAccepted(t *testing.T) {
+	predicate := InterfaceFails()
+
+	if !predicate(events.HostInterfaceStatusChangeEvent{OldValue: "Up", NewValue: "Lower Layer Down", NewAdminStatus: "Up"}) {
+		t.Errorf("Expected a change to Lower Layer Down to be accepted")
+	}
+
+	if predicate(events.HostInterfaceStatusChangeEvent{OldValue: "Up", NewValue: "Down", NewAdminStatus: "Down"}) {
+		t.Errorf("Expected a change to admin down not to be accepted")
+	}
+
+	if predicate(events.HostInterfaceStatusChangeEvent{OldValue: "Down", NewValue: "Down", NewAdminStatus: "Up"}) {
+		t.Errorf("Expected an admin status change of a down interface not to be accepted")
+	}
+}
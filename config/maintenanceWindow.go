@@ -0,0 +1,36 @@
+package config
+
+// MaintenanceWindow describes a recurring period during which hosts are under planned maintenance.  Outages during
+// maintenance are not counted as downtime, and events are either suppressed or tagged with the window's name.
+type MaintenanceWindow struct {
+	Name string
+
+	// Schedule is a cron expression ("minute hour day-of-month month day-of-week") for the start of the window,
+	// for example "0 3 * * 0" for every Sunday at 3am.
+	Schedule string
+
+	DurationMinutes int
+
+	// TimeZone is the IANA name of the time zone the schedule is evaluated in.  Empty means local time.
+	TimeZone string
+
+	// Hosts and HostGroups select the hosts the window applies to.  If both are empty, it applies to all hosts.
+	Hosts      []string
+	HostGroups []string
+
+	// SuppressEvents prevents events from being broadcast during the window.  Otherwise, events are broadcast but
+	// tagged with the window's name.
+	SuppressEvents bool
+}
+
+func (w *MaintenanceWindow) ForHosts(hostNames ...string) *MaintenanceWindow {
+	w.Hosts = append(w.Hosts, hostNames...)
+
+	return w
+}
+
+func (w *MaintenanceWindow) ForHostGroups(groupNames ...string) *MaintenanceWindow {
+	w.HostGroups = append(w.HostGroups, groupNames...)
+
+	return w
+}
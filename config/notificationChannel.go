@@ -0,0 +1,109 @@
+package config
+
+import "reflect"
+
+type NotificationChannelType int
+
+const (
+	NotificationChannelWebhook NotificationChannelType = iota + 1
+	NotificationChannelEmail
+	NotificationChannelNtfy
+	NotificationChannelGotify
+)
+
+// NotificationChannel delivers selected events to people, via a webhook, email, or a push notification service.
+type NotificationChannel struct {
+	Name string
+	Type NotificationChannelType
+
+	// Url is the webhook URL, the ntfy topic URL, such as https://ntfy.sh/mytopic, or the Gotify server URL.
+	Url string
+
+	// Headers are added to webhook requests, for example for authentication.
+	Headers map[string]string
+
+	// BodyTemplate is a text/template for the webhook request body.  It's executed with a value that has the
+	// fields EventType, HostName, Title, Message, Time and Event, and a json function that encodes a value as
+	// JSON.  Empty sends a JSON object with all of those fields.
+	BodyTemplate string
+
+	// Token authenticates push notifications.  It's sent as a bearer token to ntfy, and as the application
+	// token to Gotify.
+	Token string
+
+	// SmtpAddress is the host:port of the SMTP server used by email channels.
+	SmtpAddress  string
+	SmtpUsername string
+	SmtpPassword string
+	From         string
+	To           []string
+
+	// EventTypes selects the events delivered through the channel.  If empty, all events are delivered.
+	EventTypes []reflect.Type
+
+	// Hosts and HostGroups select the hosts whose events are delivered.  If both are empty, events of all hosts
+	// are delivered.
+	Hosts      []string
+	HostGroups []string
+
+	// MaxAttempts is the number of delivery attempts made before a notification is given up on.
+	MaxAttempts int
+
+	// RetryBackoffSeconds is the delay before the first retry.  It doubles with every further retry.
+	RetryBackoffSeconds int
+
+	// RateLimitPerMinute is the maximum number of notifications sent within any minute.  Notifications beyond
+	// that are dropped, which protects the recipients from event storms.  Zero means unlimited.
+	RateLimitPerMinute int
+}
+
+// ForEvents selects the event types delivered through the channel, given as sample values, for example
+// events.HostReachabilityChangeEvent{}.
+func (c *NotificationChannel) ForEvents(sampleEvents ...any) *NotificationChannel {
+	for _, sampleEvent := range sampleEvents {
+		c.EventTypes = append(c.EventTypes, reflect.TypeOf(sampleEvent))
+	}
+
+	return c
+}
+
+func (c *NotificationChannel) ForHosts(hostNames ...string) *NotificationChannel {
+	c.Hosts = append(c.Hosts, hostNames...)
+
+	return c
+}
+
+func (c *NotificationChannel) ForHostGroups(groupNames ...string) *NotificationChannel {
+	c.HostGroups = append(c.HostGroups, groupNames...)
+
+	return c
+}
+
+func (c *NotificationChannel) WithHeader(name string, value string) *NotificationChannel {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+
+	c.Headers[name] = value
+
+	return c
+}
+
+func (c *NotificationChannel) WithBodyTemplate(bodyTemplate string) *NotificationChannel {
+	c.BodyTemplate = bodyTemplate
+
+	return c
+}
+
+func (c *NotificationChannel) WithToken(token string) *NotificationChannel {
+	c.Token = token
+
+	return c
+}
+
+func (c *NotificationChannel) WithSmtpAuth(username string, password string) *NotificationChannel {
+	c.SmtpUsername = username
+	c.SmtpPassword = password
+
+	return c
+}
@@ -1,7 +1,29 @@
 package config
 
+import (
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/events"
+)
+
 type PluginConfig struct {
-	Hosts []Host
+	Hosts              []Host
+	MaintenanceWindows []MaintenanceWindow
+	AlertRules         []AlertRule
+	Notifications      []NotificationChannel
+
+	// AdHocMaintenanceSuppressEvents controls whether events are suppressed, rather than tagged, during
+	// maintenance windows started on demand.
+	AdHocMaintenanceSuppressEvents bool
+
+	// RateHistoryRetention is the number of points kept at each resolution of the interface traffic histories.
+	RateHistoryRetention data.RateHistoryRetention
+
+	// MaxConcurrentScans limits the number of hosts scanned at the same time.  MaxConcurrentPings and
+	// MaxConcurrentSnmpWalks limit the number of pings and SNMP collections among those scans.  Zero means no
+	// limit.
+	MaxConcurrentScans     int
+	MaxConcurrentPings     int
+	MaxConcurrentSnmpWalks int
 }
 
 func (c *PluginConfig) AddHost(name string, ipAddress string) *Host {
@@ -14,9 +36,76 @@ func (c *PluginConfig) AddHost(name string, ipAddress string) *Host {
 		PingUseIcmp:        false,
 		SnmpEnabled:        true,
 		NetInterfaces:      make(map[string]*NetInterface),
+
+		ReachabilityDownThreshold: 3,
+		ReachabilityUpThreshold:   2,
+		DegradedPacketLossPercent: 25.0,
+		FlapWindowSeconds:         3600,
+		FlapThreshold:             6,
+
+		SuppressEventsWhenParentUnreachable: true,
+		PersistEventJournal:                 true,
 	}
 
 	c.Hosts = append(c.Hosts, host)
 
 	return &c.Hosts[len(c.Hosts)-1]
 }
+
+func (c *PluginConfig) AddMaintenanceWindow(name string, schedule string, durationMinutes int) *MaintenanceWindow {
+	window := MaintenanceWindow{
+		Name:            name,
+		Schedule:        schedule,
+		DurationMinutes: durationMinutes,
+		SuppressEvents:  true,
+	}
+
+	c.MaintenanceWindows = append(c.MaintenanceWindows, window)
+
+	return &c.MaintenanceWindows[len(c.MaintenanceWindows)-1]
+}
+
+func (c *PluginConfig) AddAlertRule(name string, metric AlertMetric, threshold float64) *AlertRule {
+	rule := AlertRule{
+		Name:      name,
+		Metric:    metric,
+		Threshold: threshold,
+		Severity:  events.AlertSeverityWarning,
+	}
+
+	c.AlertRules = append(c.AlertRules, rule)
+
+	return &c.AlertRules[len(c.AlertRules)-1]
+}
+
+func (c *PluginConfig) AddWebhookChannel(name string, url string) *NotificationChannel {
+	return c.addNotificationChannel(NotificationChannel{Name: name, Type: NotificationChannelWebhook, Url: url})
+}
+
+func (c *PluginConfig) AddEmailChannel(name string, smtpAddress string, from string, to ...string) *NotificationChannel {
+	return c.addNotificationChannel(NotificationChannel{
+		Name:        name,
+		Type:        NotificationChannelEmail,
+		SmtpAddress: smtpAddress,
+		From:        from,
+		To:          to,
+	})
+}
+
+func (c *PluginConfig) AddNtfyChannel(name string, topicUrl string) *NotificationChannel {
+	return c.addNotificationChannel(NotificationChannel{Name: name, Type: NotificationChannelNtfy, Url: topicUrl})
+}
+
+func (c *PluginConfig) AddGotifyChannel(name string, serverUrl string, token string) *NotificationChannel {
+	return c.addNotificationChannel(
+		NotificationChannel{Name: name, Type: NotificationChannelGotify, Url: serverUrl, Token: token})
+}
+
+func (c *PluginConfig) addNotificationChannel(channel NotificationChannel) *NotificationChannel {
+	channel.MaxAttempts = 5
+	channel.RetryBackoffSeconds = 10
+	channel.RateLimitPerMinute = 10
+	c.Notifications = append(c.Notifications, channel)
+
+	return &c.Notifications[len(c.Notifications)-1]
+}
@@ -0,0 +1,20 @@
+package data
+
+import (
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/events"
+)
+
+// Alert is a fired alert, which is the state of a single alert rule for a single host, or host interface.
+// NetInterfaceName is empty for host level rules.
+type Alert struct {
+	RuleName         string
+	Severity         events.AlertSeverity
+	HostName         string
+	NetInterfaceName string
+	Description      string
+	Value            float64
+	Threshold        float64
+	FiredTime        time.Time
+}
@@ -0,0 +1,210 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutageLogRetention is how far back outages are retained in the outage log.  It covers the longest
+// availability period.
+const OutageLogRetention = 366 * 24 * time.Hour
+
+// OutageLogMaxLength is the maximum length of the encoded outage log.  The oldest outages are dropped
+// once the log grows beyond this size.
+const OutageLogMaxLength = 16000
+
+// Outage is a single period during which a host was unreachable.  EndTime is zero while the outage is
+// ongoing.
+type Outage struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (o *Outage) Ongoing() bool {
+	return o.EndTime.IsZero()
+}
+
+// DurationWithin returns the portion of the outage that overlaps the [start, end) interval.  Ongoing
+// outages are considered to extend to end.
+func (o *Outage) DurationWithin(start time.Time, end time.Time) time.Duration {
+	outageEnd := o.EndTime
+
+	if outageEnd.IsZero() || outageEnd.After(end) {
+		outageEnd = end
+	}
+
+	outageStart := o.StartTime
+
+	if outageStart.Before(start) {
+		outageStart = start
+	}
+
+	if !outageEnd.After(outageStart) {
+		return 0
+	}
+
+	return outageEnd.Sub(outageStart)
+}
+
+// AvailabilityPeriod describes the availability of a host over a trailing period ending at End.
+type AvailabilityPeriod struct {
+	Name         string
+	Start        time.Time
+	End          time.Time
+	Observed     time.Duration
+	Downtime     time.Duration
+	OutageCount  int
+	Availability float64
+	Mtbf         time.Duration
+	Mttr         time.Duration
+}
+
+type availabilityPeriodSpec struct {
+	name     string
+	duration time.Duration
+}
+
+var availabilityPeriodSpecs = []availabilityPeriodSpec{
+	{name: "Day", duration: 24 * time.Hour},
+	{name: "Week", duration: 7 * 24 * time.Hour},
+	{name: "Month", duration: 30 * 24 * time.Hour},
+	{name: "Year", duration: 365 * 24 * time.Hour},
+}
+
+// CalcAvailability computes availability, downtime, mean time between failures, and mean time to recover for
+// the period of the given length ending at now.  The period is clipped to the time monitoring started, so that a
+// newly added host isn't reported as unavailable for the time before it was monitored.
+func CalcAvailability(
+	name string, outages []Outage, monitoringStartTime time.Time, now time.Time, period time.Duration) AvailabilityPeriod {
+	result := AvailabilityPeriod{
+		Name:         name,
+		Start:        now.Add(-period),
+		End:          now,
+		Availability: 100.0,
+	}
+
+	if !monitoringStartTime.IsZero() && monitoringStartTime.After(result.Start) {
+		result.Start = monitoringStartTime
+	}
+
+	if !now.After(result.Start) {
+		return result
+	}
+
+	result.Observed = now.Sub(result.Start)
+	var repairTime time.Duration
+	repairCount := 0
+
+	for i := range outages {
+		downtime := outages[i].DurationWithin(result.Start, now)
+
+		if downtime == 0 {
+			continue
+		}
+
+		result.Downtime += downtime
+
+		// Only count failures that started within the period, but include the downtime of one that started
+		// before it.
+		if !outages[i].StartTime.Before(result.Start) {
+			result.OutageCount++
+		}
+
+		if !outages[i].Ongoing() {
+			repairTime += outages[i].EndTime.Sub(outages[i].StartTime)
+			repairCount++
+		}
+	}
+
+	result.Availability = 100.0 * float64(result.Observed-result.Downtime) / float64(result.Observed)
+
+	if result.OutageCount > 0 {
+		result.Mtbf = (result.Observed - result.Downtime) / time.Duration(result.OutageCount)
+	}
+
+	if repairCount > 0 {
+		result.Mttr = repairTime / time.Duration(repairCount)
+	}
+
+	return result
+}
+
+// CalcAvailabilityReport computes the availability for each of the standard periods (day, week, month and year).
+func CalcAvailabilityReport(outages []Outage, monitoringStartTime time.Time, now time.Time) []AvailabilityPeriod {
+	result := make([]AvailabilityPeriod, len(availabilityPeriodSpecs))
+
+	for i, spec := range availabilityPeriodSpecs {
+		result[i] = CalcAvailability(spec.name, outages, monitoringStartTime, now, spec.duration)
+	}
+
+	return result
+}
+
+// EncodeOutageLog serializes outages into a compact string of semicolon separated start-end pairs, using Unix
+// seconds.  The end is omitted for an ongoing outage.  The oldest outages are dropped if the result would exceed
+// OutageLogMaxLength.
+func EncodeOutageLog(outages []Outage) string {
+	entries := make([]string, len(outages))
+	length := 0
+	first := len(outages)
+
+	for i := len(outages) - 1; i >= 0; i-- {
+		entry := strconv.FormatInt(outages[i].StartTime.Unix(), 10) + "-"
+
+		if !outages[i].Ongoing() {
+			entry += strconv.FormatInt(outages[i].EndTime.Unix(), 10)
+		}
+
+		if length+len(entry)+1 > OutageLogMaxLength {
+			break
+		}
+
+		entries[i] = entry
+		length += len(entry) + 1
+		first = i
+	}
+
+	return strings.Join(entries[first:], ";")
+}
+
+// DecodeOutageLog parses a string produced by EncodeOutageLog.
+func DecodeOutageLog(outageLog string) ([]Outage, error) {
+	if outageLog == "" {
+		return []Outage{}, nil
+	}
+
+	entries := strings.Split(outageLog, ";")
+	result := make([]Outage, 0, len(entries))
+
+	for _, entry := range entries {
+		startValue, endValue, found := strings.Cut(entry, "-")
+
+		if !found {
+			return nil, fmt.Errorf("invalid outage log entry \"%s\"", entry)
+		}
+
+		start, err := strconv.ParseInt(startValue, 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid outage start in entry \"%s\": %w", entry, err)
+		}
+
+		outage := Outage{StartTime: time.Unix(start, 0)}
+
+		if endValue != "" {
+			end, err := strconv.ParseInt(endValue, 10, 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid outage end in entry \"%s\": %w", entry, err)
+			}
+
+			outage.EndTime = time.Unix(end, 0)
+		}
+
+		result = append(result, outage)
+	}
+
+	return result, nil
+}
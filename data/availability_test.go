@@ -0,0 +1,146 @@
+package data
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var availabilityNow = time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+func TestCalcAvailability_NoOutages_FullAvailability(t *testing.T) {
+	result := CalcAvailability("Day", []Outage{}, time.Time{}, availabilityNow, 24*time.Hour)
+
+	if result.Availability != 100.0 {
+		t.Errorf("Expected 100.0, got %f", result.Availability)
+	}
+
+	if result.Observed != 24*time.Hour {
+		t.Errorf("Expected %v, got %v", 24*time.Hour, result.Observed)
+	}
+}
+
+func TestCalcAvailability_OutagesWithinPeriod_ComputesMetrics(t *testing.T) {
+	outages := []Outage{
+		{StartTime: availabilityNow.Add(-10 * time.Hour), EndTime: availabilityNow.Add(-10*time.Hour + 30*time.Minute)},
+		{StartTime: availabilityNow.Add(-2 * time.Hour), EndTime: availabilityNow.Add(-2*time.Hour + 90*time.Minute)},
+	}
+
+	result := CalcAvailability("Day", outages, time.Time{}, availabilityNow, 24*time.Hour)
+
+	if result.Downtime != 2*time.Hour {
+		t.Errorf("Expected downtime %v, got %v", 2*time.Hour, result.Downtime)
+	}
+
+	if result.OutageCount != 2 {
+		t.Errorf("Expected 2 outages, got %d", result.OutageCount)
+	}
+
+	expectedAvailability := 100.0 * 22.0 / 24.0
+
+	if result.Availability != expectedAvailability {
+		t.Errorf("Expected %f, got %f", expectedAvailability, result.Availability)
+	}
+
+	if result.Mtbf != 11*time.Hour {
+		t.Errorf("Expected MTBF %v, got %v", 11*time.Hour, result.Mtbf)
+	}
+
+	if result.Mttr != time.Hour {
+		t.Errorf("Expected MTTR %v, got %v", time.Hour, result.Mttr)
+	}
+}
+
+func TestCalcAvailability_OutageSpanningPeriodStart_CountsOnlyOverlap(t *testing.T) {
+	outages := []Outage{
+		{StartTime: availabilityNow.Add(-25 * time.Hour), EndTime: availabilityNow.Add(-23 * time.Hour)},
+	}
+
+	result := CalcAvailability("Day", outages, time.Time{}, availabilityNow, 24*time.Hour)
+
+	if result.Downtime != time.Hour {
+		t.Errorf("Expected downtime %v, got %v", time.Hour, result.Downtime)
+	}
+
+	if result.OutageCount != 0 {
+		t.Errorf("Expected 0 outages starting in the period, got %d", result.OutageCount)
+	}
+}
+
+func TestCalcAvailability_OngoingOutage_ExtendsToNow(t *testing.T) {
+	outages := []Outage{
+		{StartTime: availabilityNow.Add(-6 * time.Hour)},
+	}
+
+	result := CalcAvailability("Day", outages, availabilityNow.Add(-12*time.Hour), availabilityNow, 24*time.Hour)
+
+	if result.Observed != 12*time.Hour {
+		t.Errorf("Expected observed %v, got %v", 12*time.Hour, result.Observed)
+	}
+
+	if result.Availability != 50.0 {
+		t.Errorf("Expected 50.0, got %f", result.Availability)
+	}
+
+	if result.Mttr != 0 {
+		t.Errorf("Expected no MTTR for an ongoing outage, got %v", result.Mttr)
+	}
+}
+
+func TestEncodeOutageLog_RoundTrip(t *testing.T) {
+	outages := []Outage{
+		{StartTime: time.Unix(1696939200, 0), EndTime: time.Unix(1696939500, 0)},
+		{StartTime: time.Unix(1696942800, 0)},
+	}
+
+	encoded := EncodeOutageLog(outages)
+
+	if encoded != "1696939200-1696939500;1696942800-" {
+		t.Errorf("Unexpected encoding %s", encoded)
+	}
+
+	decoded, err := DecodeOutageLog(encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.EqualFunc(outages, decoded, func(a Outage, b Outage) bool {
+		return a.StartTime.Equal(b.StartTime) && a.EndTime.Equal(b.EndTime)
+	}) {
+		t.Errorf("Expected %v, got %v", outages, decoded)
+	}
+}
+
+func TestEncodeOutageLog_TooLong_DropsOldest(t *testing.T) {
+	outages := make([]Outage, 1000)
+
+	for i := range outages {
+		outages[i].StartTime = time.Unix(int64(1696939200+i*600), 0)
+		outages[i].EndTime = outages[i].StartTime.Add(time.Minute)
+	}
+
+	encoded := EncodeOutageLog(outages)
+
+	if len(encoded) > OutageLogMaxLength {
+		t.Errorf("Expected at most %d characters, got %d", OutageLogMaxLength, len(encoded))
+	}
+
+	decoded, err := DecodeOutageLog(encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded[len(decoded)-1].StartTime.Equal(outages[len(outages)-1].StartTime) {
+		t.Errorf("Expected the most recent outage to be retained")
+	}
+}
+
+func TestDecodeOutageLog_Invalid_ReturnsError(t *testing.T) {
+	_, err := DecodeOutageLog("abc")
+
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+}
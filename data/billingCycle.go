@@ -0,0 +1,41 @@
+package data
+
+import "time"
+
+// BillingCycleStart returns the start of the billing cycle containing t, in t's location.  Cycles start at
+// midnight on startDay of each month, or on the month's last day if it is shorter.  Values of startDay less than
+// 1 are treated as 1.
+func BillingCycleStart(t time.Time, startDay int) time.Time {
+	start := cycleStartInMonth(t.Year(), t.Month(), startDay, t.Location())
+
+	if t.Before(start) {
+		return cycleStartInMonth(t.Year(), t.Month()-1, startDay, t.Location())
+	}
+
+	return start
+}
+
+// BillingCycleEnd returns the end of the billing cycle that starts at cycleStart, which is the start of the
+// next cycle.
+func BillingCycleEnd(cycleStart time.Time, startDay int) time.Time {
+	return cycleStartInMonth(cycleStart.Year(), cycleStart.Month()+1, startDay, cycleStart.Location())
+}
+
+func cycleStartInMonth(year int, month time.Month, startDay int, location *time.Location) time.Time {
+	// Day zero of the next month is the last day of this one
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, location).Day()
+
+	return time.Date(year, month, min(max(startDay, 1), lastDay), 0, 0, 0, 0, location)
+}
+
+// ProjectedUsage extrapolates the usage to date to the end of the billing cycle, assuming the average rate
+// since the start of the cycle is kept up.
+func ProjectedUsage(usage uint64, cycleStart time.Time, cycleEnd time.Time, now time.Time) uint64 {
+	elapsed := now.Sub(cycleStart)
+
+	if elapsed <= 0 || !now.Before(cycleEnd) {
+		return usage
+	}
+
+	return uint64(float64(usage) * float64(cycleEnd.Sub(cycleStart)) / float64(elapsed))
+}
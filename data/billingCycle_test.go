@@ -0,0 +1,53 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBillingCycleStart_BeforeStartDay_ReturnsPreviousMonth(t *testing.T) {
+	result := BillingCycleStart(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), 17)
+	expected := time.Date(2023, 12, 17, 0, 0, 0, 0, time.UTC)
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBillingCycleStart_OnStartDay_ReturnsSameDay(t *testing.T) {
+	result := BillingCycleStart(time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC), 17)
+	expected := time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBillingCycleStart_ShortMonth_StartsOnLastDay(t *testing.T) {
+	result := BillingCycleStart(time.Date(2023, 2, 28, 8, 0, 0, 0, time.UTC), 31)
+	expected := time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	end := BillingCycleEnd(result, 31)
+	expected = time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	if !end.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, end)
+	}
+}
+
+func TestProjectedUsage_PartwayThroughCycle_Extrapolates(t *testing.T) {
+	start := time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC)
+	end := BillingCycleEnd(start, 17)
+
+	if result := ProjectedUsage(300, start, end, start.Add(3*24*time.Hour)); result != 3000 {
+		t.Errorf("Expected 3000, got %d", result)
+	}
+
+	if result := ProjectedUsage(300, start, end, end); result != 300 {
+		t.Errorf("Expected 300, got %d", result)
+	}
+}
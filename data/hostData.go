@@ -9,6 +9,7 @@ const (
 	ReachabilityUnknown = iota
 	ReachabilityReachable
 	ReachabilityUnreachable
+	ReachabilityDegraded
 )
 
 type HostData struct {
@@ -29,10 +30,47 @@ type HostData struct {
 	LastPingReachableStartTime    time.Time
 	LastPingPartialPacketLossTime time.Time
 	NetInterfaceDataList          []NetInterfaceData
-	Reachability                  int `track:"always,dataType=int"`
+	Reachability                  int           `track:"always,dataType=int"`
+	MonitoringStartTime           time.Time     `track:"onchange"`
+	TotalDowntime                 time.Duration `track:"always,dataType=bigint"`
+	OutageLog                     string        `track:"onchange,dataType=varchar,maxLength=16000"`
+	MaintenanceWindow             string        `track:"always,maxLength=100"`
+	EventJournal                  string        `track:"onchange,dataType=varchar,maxLength=16000"`
+	MaintenanceEndTime            time.Time
+	HostAdHocMaintenance          bool
 	LastReachabilityChangeTime    time.Time
 	UnreachableStartCount         int
 	LastUnreachableStartTime      time.Time
+	PendingReachability           int
+	PendingReachabilityCount      int
+	PendingReachabilityStartTime  time.Time
+	DependsOn                     []string
+	RootCauseHost                 string
+	Flapping                      bool
+	FlappingStartCount            int
+	LastFlappingStartTime         time.Time
+	LastFlappingEndTime           time.Time
+	CertificateStatus             string
+	CertificateExpiryTime         time.Time
+
+	// SnmpInterval is the current interval between SNMP collections.  While the host is unreachable, SNMP is
+	// retried less and less often, and SnmpBackoff is true.
+	SnmpInterval time.Duration
+	SnmpBackoff  bool
+}
+
+// ReachabilityName returns the display name of a reachability value.
+func ReachabilityName(value int) string {
+	switch value {
+	case ReachabilityUnreachable:
+		return "Down"
+	case ReachabilityReachable:
+		return "Up"
+	case ReachabilityDegraded:
+		return "Degraded"
+	default:
+		return "Unknown"
+	}
 }
 
 var HostDataType = reflect.TypeOf((*HostData)(nil)).Elem()
@@ -48,7 +86,33 @@ func HostDataToInsertArgs(genericDataPointer *any) ([]any, error) {
 		int64(data.PingRttMax),
 		int64(data.PingRttStdDev),
 		data.Reachability,
+		timeEmptyToNil(data.MonitoringStartTime),
+		int64(data.TotalDowntime),
+		stringEmptyToNil(data.OutageLog),
+		stringEmptyToNil(data.MaintenanceWindow),
+		stringEmptyToNil(data.EventJournal),
 	}
 
 	return args, nil
 }
+
+// InMaintenance returns true if the sample was taken during a maintenance window.
+func (d *HostData) InMaintenance() bool {
+	return d.MaintenanceWindow != ""
+}
+
+// GetOutages returns the decoded outage log.  An invalid log is treated as empty.
+func (d *HostData) GetOutages() []Outage {
+	outages, err := DecodeOutageLog(d.OutageLog)
+
+	if err != nil {
+		return []Outage{}
+	}
+
+	return outages
+}
+
+// GetAvailabilityReport computes the availability of the host over the standard periods ending at now.
+func (d *HostData) GetAvailabilityReport(now time.Time) []AvailabilityPeriod {
+	return CalcAvailabilityReport(d.GetOutages(), d.MonitoringStartTime, now)
+}
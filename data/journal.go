@@ -0,0 +1,86 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JournalMaxEntries is the number of events retained per host in the event journal.
+const JournalMaxEntries = 100
+
+// JournalLogMaxLength is the maximum length of the encoded event journal.  The oldest entries are dropped once the
+// journal grows beyond this size.
+const JournalLogMaxLength = 16000
+
+// JournalEntry records a single event broadcast by the plugin.
+type JournalEntry struct {
+	Time             time.Time `json:"time"`
+	HostName         string    `json:"host"`
+	NetInterfaceName string    `json:"interface,omitempty"`
+	EventType        string    `json:"type"`
+	Message          string    `json:"message"`
+}
+
+// JournalFilter selects journal entries.  Empty fields match all entries.
+type JournalFilter struct {
+	HostName         string
+	NetInterfaceName string
+	EventType        string
+	Since            time.Time
+	Until            time.Time
+
+	// Limit is the maximum number of entries returned.  Zero means no limit.
+	Limit int
+}
+
+// Matches returns true if the entry satisfies every criterion of the filter.
+func (f *JournalFilter) Matches(entry *JournalEntry) bool {
+	return (f.HostName == "" || f.HostName == entry.HostName) &&
+		(f.NetInterfaceName == "" || f.NetInterfaceName == entry.NetInterfaceName) &&
+		(f.EventType == "" || f.EventType == entry.EventType) &&
+		(f.Since.IsZero() || !entry.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || entry.Time.Before(f.Until))
+}
+
+// EncodeJournal serializes journal entries as a JSON array.  The oldest entries are dropped if the result would
+// exceed JournalLogMaxLength.
+func EncodeJournal(entries []JournalEntry) string {
+	encodedEntries := make([]string, len(entries))
+	length := 2
+	first := len(entries)
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		encodedEntry, err := json.Marshal(&entries[i])
+
+		if err != nil || length+len(encodedEntry)+1 > JournalLogMaxLength {
+			break
+		}
+
+		encodedEntries[i] = string(encodedEntry)
+		length += len(encodedEntry) + 1
+		first = i
+	}
+
+	if first == len(entries) {
+		return ""
+	}
+
+	return "[" + strings.Join(encodedEntries[first:], ",") + "]"
+}
+
+// DecodeJournal parses a string produced by EncodeJournal.
+func DecodeJournal(journalLog string) ([]JournalEntry, error) {
+	result := make([]JournalEntry, 0)
+
+	if journalLog == "" {
+		return result, nil
+	}
+
+	if err := json.Unmarshal([]byte(journalLog), &result); err != nil {
+		return nil, fmt.Errorf("invalid event journal: %w", err)
+	}
+
+	return result, nil
+}
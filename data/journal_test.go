@@ -0,0 +1,98 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var journalTime = time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+func TestEncodeJournal_RoundTrip_PreservesEntries(t *testing.T) {
+	entries := []JournalEntry{
+		{Time: journalTime, HostName: "router", EventType: "HostReachabilityChangeEvent", Message: "down"},
+		{Time: journalTime.Add(time.Minute), HostName: "router", NetInterfaceName: "eth0", EventType: "T", Message: "up"},
+	}
+
+	result, err := DecodeJournal(EncodeJournal(entries))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(result))
+	}
+
+	if !result[1].Time.Equal(entries[1].Time) || result[1].NetInterfaceName != "eth0" || result[1].Message != "up" {
+		t.Errorf("Expected %v, got %v", entries[1], result[1])
+	}
+}
+
+func TestEncodeJournal_Empty_ReturnsEmptyString(t *testing.T) {
+	if result := EncodeJournal([]JournalEntry{}); result != "" {
+		t.Errorf("Expected empty string, got %s", result)
+	}
+}
+
+func TestEncodeJournal_TooLong_DropsOldestEntries(t *testing.T) {
+	entries := make([]JournalEntry, 0)
+
+	for i := 0; i < 100; i++ {
+		entries = append(entries, JournalEntry{
+			Time:     journalTime.Add(time.Duration(i) * time.Minute),
+			HostName: "router",
+			Message:  strings.Repeat("x", 500),
+		})
+	}
+
+	encoded := EncodeJournal(entries)
+
+	if len(encoded) > JournalLogMaxLength {
+		t.Errorf("Expected at most %d characters, got %d", JournalLogMaxLength, len(encoded))
+	}
+
+	result, err := DecodeJournal(encoded)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result) == 0 || len(result) == len(entries) {
+		t.Fatalf("Expected some entries to be dropped, got %d", len(result))
+	}
+
+	if !result[len(result)-1].Time.Equal(entries[len(entries)-1].Time) {
+		t.Errorf("Expected the newest entry to be kept, got %v", result[len(result)-1].Time)
+	}
+}
+
+func TestDecodeJournal_Invalid_ReturnsError(t *testing.T) {
+	if _, err := DecodeJournal("[{"); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestJournalFilter_Matches_AppliesAllCriteria(t *testing.T) {
+	entry := JournalEntry{Time: journalTime, HostName: "router", NetInterfaceName: "eth0", EventType: "T"}
+	filters := []struct {
+		filter   JournalFilter
+		expected bool
+	}{
+		{JournalFilter{}, true},
+		{JournalFilter{HostName: "router", NetInterfaceName: "eth0", EventType: "T"}, true},
+		{JournalFilter{HostName: "switch"}, false},
+		{JournalFilter{NetInterfaceName: "eth1"}, false},
+		{JournalFilter{EventType: "U"}, false},
+		{JournalFilter{Since: journalTime}, true},
+		{JournalFilter{Since: journalTime.Add(time.Second)}, false},
+		{JournalFilter{Until: journalTime}, false},
+		{JournalFilter{Until: journalTime.Add(time.Second)}, true},
+	}
+
+	for _, f := range filters {
+		if result := f.filter.Matches(&entry); result != f.expected {
+			t.Errorf("Expected %t for %+v, got %t", f.expected, f.filter, result)
+		}
+	}
+}
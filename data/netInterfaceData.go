@@ -12,6 +12,18 @@ import (
 const NetInterfaceDataHistorySize = 64
 const NetInterfaceDailyHistorySize = 64
 
+// Interface status names, used for both the operational status (ifOperStatus) and the administrative status
+// (ifAdminStatus), which only has the first three values.
+const (
+	InterfaceStatusUp             = "Up"
+	InterfaceStatusDown           = "Down"
+	InterfaceStatusTesting        = "Testing"
+	InterfaceStatusUnknown        = "Unknown"
+	InterfaceStatusDormant        = "Dormant"
+	InterfaceStatusNotPresent     = "Not Present"
+	InterfaceStatusLowerLayerDown = "Lower Layer Down"
+)
+
 type NetInterfaceData struct {
 	Index                     uint32   `track:"onchange"`
 	Name                      string   `track:"onchange,maxLength=255"`
@@ -30,25 +42,112 @@ type NetInterfaceData struct {
 	DiscardsIn                uint64    `track:"always"`
 	DiscardsOut               uint64    `track:"always"`
 	LastUpdateTime            time.Time `track:"always"`
+	BandwidthPercentiles      string    `track:"onchange,dataType=varchar,maxLength=16000"`
+	BillingCycleStart         time.Time `track:"onchange"`
+	CycleBytesIn              uint64    `track:"always"`
+	CycleBytesOut             uint64    `track:"always"`
+	Speed                     uint64    `track:"onchange"`
+	Duplex                    string    `track:"onchange,maxLength=30"`
+	Mtu                       int32     `track:"onchange"`
+	AdminStatus               string    `track:"onchange,maxLength=30"`
+	LastChangeTime            time.Time `track:"onchange"`
+	BillingCycleEnd           time.Time
+	DataCap                   uint64
+	DataCapUsage              uint64
+	ProjectedDataCapUsage     uint64
+	DataCapPercentReached     float64
+	Percentiles               BandwidthPercentiles
+	UnicastPacketsIn          uint64
+	UnicastPacketsOut         uint64
+	MulticastPacketsIn        uint64
+	MulticastPacketsOut       uint64
+	BroadcastPacketsIn        uint64
+	BroadcastPacketsOut       uint64
+	BroadcastStorm            bool
+	ErrorRate                 float64
+	ErrorRatio                float64
+	ErrorRateExceeded         bool
+	DiscardRate               float64
+	DiscardRateExceeded       bool
+	Utilization               float64
+	BytesInRate               uint64
+	BytesOutRate              uint64
 	CurrentHistoryIndex       uint
-	BytesInRateHistory        [NetInterfaceDataHistorySize]uint64
-	BytesOutRateHistory       [NetInterfaceDataHistorySize]uint64
+	BytesInHistory            *RateHistory
+	BytesOutHistory           *RateHistory
+	ErrorsInRateHistory       [NetInterfaceDataHistorySize]float64
+	ErrorsOutRateHistory      [NetInterfaceDataHistorySize]float64
+	DiscardsInRateHistory     [NetInterfaceDataHistorySize]float64
+	DiscardsOutRateHistory    [NetInterfaceDataHistorySize]float64
+	UnicastInRateHistory      [NetInterfaceDataHistorySize]float64
+	UnicastOutRateHistory     [NetInterfaceDataHistorySize]float64
+	MulticastInRateHistory    [NetInterfaceDataHistorySize]float64
+	MulticastOutRateHistory   [NetInterfaceDataHistorySize]float64
+	BroadcastInRateHistory    [NetInterfaceDataHistorySize]float64
+	BroadcastOutRateHistory   [NetInterfaceDataHistorySize]float64
 	CurrentDayIndex           uint
 	DailyBytesIn              [NetInterfaceDailyHistorySize]uint64
 	DailyBytesOut             [NetInterfaceDailyHistorySize]uint64
 }
 
-func (d *NetInterfaceData) GetBytesInRateHistory(limit int) []uint64 {
-	return GetHistory(&d.BytesInRateHistory, d.CurrentHistoryIndex, limit)
+// IsAdminDown returns true if the interface was disabled administratively.
+func (d *NetInterfaceData) IsAdminDown() bool {
+	return d.AdminStatus == InterfaceStatusDown
 }
 
-func (d *NetInterfaceData) GetBytesOutRateHistory(limit int) []uint64 {
-	return GetHistory(&d.BytesOutRateHistory, d.CurrentHistoryIndex, limit)
+// IsFailed returns true if the interface is down, or its lower layer is, even though it's administratively up.
+func (d *NetInterfaceData) IsFailed() bool {
+	return !d.IsAdminDown() && IsFailedStatus(d.Status)
+}
+
+// IsFailedStatus returns true if the operational status means the interface can't pass traffic due to a fault.
+func IsFailedStatus(status string) bool {
+	return status == InterfaceStatusDown || status == InterfaceStatusLowerLayerDown
+}
+
+func (d *NetInterfaceData) GetErrorsInRateHistory(limit int) []float64 {
+	return GetHistory(&d.ErrorsInRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetErrorsOutRateHistory(limit int) []float64 {
+	return GetHistory(&d.ErrorsOutRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetDiscardsInRateHistory(limit int) []float64 {
+	return GetHistory(&d.DiscardsInRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetDiscardsOutRateHistory(limit int) []float64 {
+	return GetHistory(&d.DiscardsOutRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetUnicastInRateHistory(limit int) []float64 {
+	return GetHistory(&d.UnicastInRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetUnicastOutRateHistory(limit int) []float64 {
+	return GetHistory(&d.UnicastOutRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetMulticastInRateHistory(limit int) []float64 {
+	return GetHistory(&d.MulticastInRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetMulticastOutRateHistory(limit int) []float64 {
+	return GetHistory(&d.MulticastOutRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetBroadcastInRateHistory(limit int) []float64 {
+	return GetHistory(&d.BroadcastInRateHistory, d.CurrentHistoryIndex, limit)
+}
+
+func (d *NetInterfaceData) GetBroadcastOutRateHistory(limit int) []float64 {
+	return GetHistory(&d.BroadcastOutRateHistory, d.CurrentHistoryIndex, limit)
 }
 
 // GetHistory retrieves a slice of the recent history data up to the specified limit.  The history is returned
 // in chronological order, with the oldest entry in position zero of the result slice.
-func GetHistory(src *[NetInterfaceDataHistorySize]uint64, currentIndex uint, limit int) []uint64 {
+func GetHistory[T uint64 | float64](src *[NetInterfaceDataHistorySize]T, currentIndex uint, limit int) []T {
 	// Clamp limit to buffer size
 	if limit > NetInterfaceDataHistorySize {
 		limit = NetInterfaceDataHistorySize
@@ -57,7 +156,7 @@ func GetHistory(src *[NetInterfaceDataHistorySize]uint64, currentIndex uint, lim
 	// Use int for slice indexing and calculations
 	curr := int(currentIndex)
 
-	result := make([]uint64, limit)
+	result := make([]T, limit)
 
 	// Calculate start index (chronological start)
 	// Logic: End is at currentIndex. Start is 'limit - 1' steps back.
@@ -144,6 +243,25 @@ func (d *NetInterfaceData) GetCurrentMonthTotalBytes() (uint64, uint64) {
 	return totalIn, totalOut
 }
 
+// GetDataCapUsagePercent returns the usage to date, in percent of the data cap, or zero if there is no cap.
+func (d *NetInterfaceData) GetDataCapUsagePercent() float64 {
+	if d.DataCap == 0 {
+		return 0
+	}
+
+	return 100.0 * float64(d.DataCapUsage) / float64(d.DataCap)
+}
+
+// GetProjectedDataCapPercent returns the projected usage at the end of the billing cycle, in percent of the data
+// cap, or zero if there is no cap.
+func (d *NetInterfaceData) GetProjectedDataCapPercent() float64 {
+	if d.DataCap == 0 {
+		return 0
+	}
+
+	return 100.0 * float64(d.ProjectedDataCapUsage) / float64(d.DataCap)
+}
+
 var NetInterfaceDataType = reflect.TypeOf((*NetInterfaceData)(nil)).Elem()
 
 func NetInterfaceDataToInsertArgs(genericDataPointer *any) ([]any, error) {
@@ -170,6 +288,15 @@ func NetInterfaceDataToInsertArgs(genericDataPointer *any) ([]any, error) {
 		data.DiscardsIn,
 		data.DiscardsOut,
 		timeEmptyToNil(data.LastUpdateTime),
+		stringEmptyToNil(data.BandwidthPercentiles),
+		timeEmptyToNil(data.BillingCycleStart),
+		data.CycleBytesIn,
+		data.CycleBytesOut,
+		data.Speed,
+		stringEmptyToNil(data.Duplex),
+		data.Mtu,
+		stringEmptyToNil(data.AdminStatus),
+		timeEmptyToNil(data.LastChangeTime),
 	}
 	return args, nil
 }
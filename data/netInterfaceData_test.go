@@ -16,6 +16,7 @@ type globals struct {
 	localTimeStamp1 time.Time
 	localTimeStamp2 time.Time
 	data            NetInterfaceData
+	rateHistory     [NetInterfaceDataHistorySize]uint64
 }
 
 var g = globals{}
@@ -49,27 +50,25 @@ func TestMain(m *testing.M) {
 		DiscardsIn:                700,
 		DiscardsOut:               800,
 		LastUpdateTime:            g.localTimeStamp2,
-		BytesInRateHistory:        [64]uint64(genRateHistory(true, 0, 64)),
-		BytesOutRateHistory:       [64]uint64(genRateHistory(false, 63, 64)),
+		Speed:                     1_000_000_000,
+		Duplex:                    "Full",
+		Mtu:                       1500,
+		AdminStatus:               "Up",
+		LastChangeTime:            g.localTimeStamp1,
 	}
+	g.rateHistory = [NetInterfaceDataHistorySize]uint64(genRateHistory(0, NetInterfaceDataHistorySize))
 	os.Exit(m.Run())
 }
 
-func genRateHistory(forward bool, start int, count int) []uint64 {
+func genRateHistory(start int, count int) []uint64 {
 	if count > NetInterfaceDataHistorySize {
 		panic("count cannot be greater than NetInterfaceDataHistorySize")
 	}
 
 	result := make([]uint64, count)
 
-	if forward {
-		for i := 0; i < count; i++ {
-			result[i] = uint64((start + i) % NetInterfaceDataHistorySize)
-		}
-	} else {
-		for i := 0; i < count; i++ {
-			result[i] = uint64((start + NetInterfaceDataHistorySize - i) % NetInterfaceDataHistorySize)
-		}
+	for i := 0; i < count; i++ {
+		result[i] = uint64((start + i) % NetInterfaceDataHistorySize)
 	}
 
 	return result
@@ -97,7 +96,16 @@ func TestNetInterfaceDataToInsertArgs_ConvertsAllValues(t *testing.T) {
 		g.data.ErrorsOut,
 		g.data.DiscardsIn,
 		g.data.DiscardsOut,
-		g.data.LastUpdateTime}
+		g.data.LastUpdateTime,
+		nil,
+		nil,
+		g.data.CycleBytesIn,
+		g.data.CycleBytesOut,
+		g.data.Speed,
+		g.data.Duplex,
+		g.data.Mtu,
+		g.data.AdminStatus,
+		g.data.LastChangeTime}
 
 	if !slices.Equal(args, expectedArgs) {
 		t.Errorf("Expected %v, got %v", expectedArgs, args)
@@ -113,6 +121,9 @@ func TestNetInterfaceDataToInsertArgs_ConvertsEmptyStringsToNil(t *testing.T) {
 	g.data.IpV4Addresses = []string{}
 	g.data.IpAddresses = []net.IP{}
 	g.data.LastUpdateTime = time.Time{}
+	g.data.Duplex = ""
+	g.data.AdminStatus = ""
+	g.data.LastChangeTime = time.Time{}
 
 	var dataAsAny any = g.data
 	args, err := NetInterfaceDataToInsertArgs(&dataAsAny)
@@ -135,6 +146,15 @@ func TestNetInterfaceDataToInsertArgs_ConvertsEmptyStringsToNil(t *testing.T) {
 		g.data.ErrorsOut,
 		g.data.DiscardsIn,
 		g.data.DiscardsOut,
+		nil,
+		nil,
+		nil,
+		g.data.CycleBytesIn,
+		g.data.CycleBytesOut,
+		g.data.Speed,
+		nil,
+		g.data.Mtu,
+		nil,
 		nil}
 
 	if !slices.Equal(args, expectedArgs) {
@@ -142,32 +162,30 @@ func TestNetInterfaceDataToInsertArgs_ConvertsEmptyStringsToNil(t *testing.T) {
 	}
 }
 
-func TestNetInterfaceDataBytesInRateHistory_returnCorrectResults(t *testing.T) {
-	expected := genRateHistory(true, 1, 64)
+func TestNetInterfaceDataGetHistory_returnCorrectResults(t *testing.T) {
+	expected := genRateHistory(1, 64)
 
-	actual := g.data.GetBytesInRateHistory(64)
+	actual := GetHistory(&g.rateHistory, 0, 64)
 
 	if !slices.Equal(expected, actual) {
 		t.Errorf("Expected %v, got %v", expected, actual)
 	}
 }
 
-func TestNetInterfaceDataBytesInRateHistory_midpointInHistoryBuffer_returnCorrectResults(t *testing.T) {
-	expected := genRateHistory(true, 16, 64)
-	g.data.CurrentHistoryIndex = 15
+func TestNetInterfaceDataGetHistory_midpointInHistoryBuffer_returnCorrectResults(t *testing.T) {
+	expected := genRateHistory(16, 64)
 
-	actual := g.data.GetBytesInRateHistory(64)
+	actual := GetHistory(&g.rateHistory, 15, 64)
 
 	if !slices.Equal(expected, actual) {
 		t.Errorf("Expected %v, got %v", expected, actual)
 	}
 }
 
-func TestNetInterfaceDataBytesInRateHistory_midpointInHistoryBuffer_subset_returnCorrectResults(t *testing.T) {
+func TestNetInterfaceDataGetHistory_midpointInHistoryBuffer_subset_returnCorrectResults(t *testing.T) {
 	expected := []uint64{62, 63, 0, 1}
-	g.data.CurrentHistoryIndex = 1
 
-	actual := g.data.GetBytesInRateHistory(4)
+	actual := GetHistory(&g.rateHistory, 1, 4)
 
 	if !slices.Equal(expected, actual) {
 		t.Errorf("Expected %v, got %v", expected, actual)
@@ -0,0 +1,22 @@
+package data
+
+import "time"
+
+const (
+	NotificationDelivered   = "Delivered"
+	NotificationFailed      = "Failed"
+	NotificationRateLimited = "Rate limited"
+	NotificationDropped     = "Dropped"
+)
+
+// NotificationDelivery records the outcome of sending a single notification through a channel.
+type NotificationDelivery struct {
+	Time        time.Time
+	ChannelName string
+	EventType   string
+	HostName    string
+	Title       string
+	Attempts    int
+	Status      string
+	Error       string
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// PercentileSampleInterval is the interval over which rates are averaged into percentile samples, as commonly
+// used for 95th percentile billing.
+const PercentileSampleInterval = 5 * time.Minute
+
+// percentileSamplesKept is the number of largest samples kept per period.  The 95th percentile only depends on
+// the largest 5% of the samples, and a 31-day period has 8928 5-minute samples.
+const percentileSamplesKept = 450
+
+// PercentileTracker computes the 95th percentile of the 5-minute average rates over a billing period, and keeps
+// the result of the previous period.  Only the largest samples are kept, so the state stays small enough to be
+// persisted, while the result is exact.
+type PercentileTracker struct {
+	PeriodStart time.Time `json:"periodStart"`
+	Samples     int       `json:"samples"`
+
+	// Largest holds the largest samples of the current period, in bytes per second, in ascending order
+	Largest []uint64 `json:"largest"`
+
+	IntervalStart time.Time `json:"intervalStart"`
+	IntervalSum   float64   `json:"intervalSum"`
+	IntervalCount int       `json:"intervalCount"`
+
+	PreviousPeriodStart time.Time `json:"previousPeriodStart"`
+	PreviousSamples     int       `json:"previousSamples"`
+	PreviousValue       uint64    `json:"previousValue"`
+}
+
+// Add adds a rate sample.  The samples taken within each 5-minute interval are averaged into a percentile sample
+// once the interval is complete.  periodStart is the start of the billing period the sample belongs to; a new
+// period completes the current one.  Samples must be added in chronological order.
+func (p *PercentileTracker) Add(sampleTime time.Time, rate float64, periodStart time.Time) {
+	intervalStart := sampleTime.Truncate(PercentileSampleInterval)
+
+	if p.IntervalCount != 0 && !intervalStart.Equal(p.IntervalStart) {
+		p.completeInterval()
+	}
+
+	if !periodStart.Equal(p.PeriodStart) {
+		if !p.PeriodStart.IsZero() {
+			p.PreviousPeriodStart = p.PeriodStart
+			p.PreviousSamples = p.Samples
+			p.PreviousValue = p.Value()
+		}
+
+		p.PeriodStart = periodStart
+		p.Samples = 0
+		p.Largest = nil
+	}
+
+	p.IntervalStart = intervalStart
+	p.IntervalSum += rate
+	p.IntervalCount++
+}
+
+func (p *PercentileTracker) completeInterval() {
+	sample := uint64(math.Round(p.IntervalSum / float64(p.IntervalCount)))
+	p.IntervalSum = 0
+	p.IntervalCount = 0
+	p.Samples++
+
+	index, _ := slices.BinarySearch(p.Largest, sample)
+	p.Largest = slices.Insert(p.Largest, index, sample)
+
+	if len(p.Largest) > percentileSamplesKept {
+		p.Largest = slices.Delete(p.Largest, 0, len(p.Largest)-percentileSamplesKept)
+	}
+}
+
+// Value returns the 95th percentile of the completed samples of the current period, or zero if there are none.
+// Following the usual billing convention, the top 5% of the samples are discarded and the highest remaining
+// sample is the result.
+func (p *PercentileTracker) Value() uint64 {
+	if p.Samples == 0 {
+		return 0
+	}
+
+	// Rank of the result, counting from the largest sample
+	rank := p.Samples - int(math.Ceil(0.95*float64(p.Samples))) + 1
+	index := len(p.Largest) - rank
+
+	if index < 0 {
+		// The period is longer than the kept samples allow for
+		index = 0
+	}
+
+	return p.Largest[index]
+}
+
+// BandwidthPercentiles holds the 95th percentile trackers of an interface's incoming and outgoing rates.
+type BandwidthPercentiles struct {
+	In  PercentileTracker `json:"in"`
+	Out PercentileTracker `json:"out"`
+}
+
+// Clone returns a deep copy.
+func (b *BandwidthPercentiles) Clone() BandwidthPercentiles {
+	result := *b
+	result.In.Largest = slices.Clone(b.In.Largest)
+	result.Out.Largest = slices.Clone(b.Out.Largest)
+
+	return result
+}
+
+// EncodeBandwidthPercentiles serializes the trackers for persistence.
+func EncodeBandwidthPercentiles(percentiles *BandwidthPercentiles) string {
+	if percentiles.In.PeriodStart.IsZero() {
+		return ""
+	}
+
+	result, err := json.Marshal(percentiles)
+
+	if err != nil {
+		return ""
+	}
+
+	return string(result)
+}
+
+// DecodeBandwidthPercentiles parses a string produced by EncodeBandwidthPercentiles.
+func DecodeBandwidthPercentiles(value string) (BandwidthPercentiles, error) {
+	var result BandwidthPercentiles
+
+	if value == "" {
+		return result, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return BandwidthPercentiles{}, fmt.Errorf("invalid bandwidth percentiles: %w", err)
+	}
+
+	return result, nil
+}
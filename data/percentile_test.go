@@ -0,0 +1,110 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+var percentileStart = time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// addPercentileSamples adds one sample per 5-minute interval, with the given values, starting at the given time.
+func addPercentileSamples(tracker *PercentileTracker, start time.Time, values []float64) time.Time {
+	sampleTime := start
+
+	for _, value := range values {
+		tracker.Add(sampleTime, value, BillingCycleStart(sampleTime, 1))
+		sampleTime = sampleTime.Add(PercentileSampleInterval)
+	}
+
+	return sampleTime
+}
+
+func TestPercentileTracker_Value_DiscardsTopFivePercent(t *testing.T) {
+	tracker := PercentileTracker{}
+	values := make([]float64, 0)
+
+	// Shuffled order, values 1 to 100
+	for i := 0; i < 100; i++ {
+		values = append(values, float64((i*37)%100+1))
+	}
+
+	// One more sample completes the last interval
+	addPercentileSamples(&tracker, percentileStart, append(values, 0))
+
+	if tracker.Samples != 100 {
+		t.Fatalf("Expected 100 samples, got %d", tracker.Samples)
+	}
+
+	if result := tracker.Value(); result != 95 {
+		t.Errorf("Expected 95, got %d", result)
+	}
+}
+
+func TestPercentileTracker_Add_AveragesSamplesWithinInterval(t *testing.T) {
+	tracker := PercentileTracker{}
+
+	tracker.Add(percentileStart, 100, percentileStart)
+	tracker.Add(percentileStart.Add(time.Minute), 300, percentileStart)
+	tracker.Add(percentileStart.Add(5*time.Minute), 0, percentileStart)
+
+	if tracker.Samples != 1 || tracker.Largest[0] != 200 {
+		t.Errorf("Expected a single sample of 200, got %v", tracker.Largest)
+	}
+}
+
+func TestPercentileTracker_Add_NewPeriodKeepsPreviousResult(t *testing.T) {
+	tracker := PercentileTracker{}
+	end := addPercentileSamples(&tracker, percentileStart.Add(31*24*time.Hour-time.Hour), make([]float64, 12))
+	addPercentileSamples(&tracker, end, []float64{1000, 1000})
+
+	if !tracker.PreviousPeriodStart.Equal(percentileStart) {
+		t.Errorf("Expected %v, got %v", percentileStart, tracker.PreviousPeriodStart)
+	}
+
+	if tracker.PreviousSamples != 12 || tracker.PreviousValue != 0 {
+		t.Errorf("Expected 12 samples with a value of 0, got %d and %d", tracker.PreviousSamples, tracker.PreviousValue)
+	}
+
+	if tracker.Samples != 1 || tracker.Value() != 1000 {
+		t.Errorf("Expected 1 sample with a value of 1000, got %d and %d", tracker.Samples, tracker.Value())
+	}
+}
+
+func TestPercentileTracker_LongPeriod_KeepsOnlyLargestSamples(t *testing.T) {
+	tracker := PercentileTracker{}
+	values := make([]float64, 31*24*12)
+
+	for i := range values {
+		values[i] = float64(i)
+	}
+
+	addPercentileSamples(&tracker, percentileStart, values)
+
+	if len(tracker.Largest) != percentileSamplesKept {
+		t.Errorf("Expected %d samples kept, got %d", percentileSamplesKept, len(tracker.Largest))
+	}
+
+	// The first sample of November completes the month: 8928 samples, valued 0 to 8927, and the 8482nd smallest
+	// is 8481
+	tracker.Add(percentileStart.AddDate(0, 1, 0), 0, percentileStart.AddDate(0, 1, 0))
+
+	if tracker.PreviousSamples != 8928 || tracker.PreviousValue != 8481 {
+		t.Errorf("Expected 8928 samples with a value of 8481, got %d and %d", tracker.PreviousSamples, tracker.PreviousValue)
+	}
+}
+
+func TestEncodeBandwidthPercentiles_RoundTrip_PreservesState(t *testing.T) {
+	percentiles := BandwidthPercentiles{}
+	addPercentileSamples(&percentiles.In, percentileStart, []float64{10, 20, 30})
+	addPercentileSamples(&percentiles.Out, percentileStart, []float64{40, 50, 60})
+
+	result, err := DecodeBandwidthPercentiles(EncodeBandwidthPercentiles(&percentiles))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.In.Samples != 2 || result.Out.Value() != percentiles.Out.Value() || result.In.IntervalSum != 30 {
+		t.Errorf("Expected %+v, got %+v", percentiles, result)
+	}
+}
@@ -0,0 +1,82 @@
+package data
+
+import "time"
+
+// PluginStatus describes the health of the plugin's own monitoring: how busy the scan scheduler is, how long the
+// scans and collections take, and what went wrong with them.
+type PluginStatus struct {
+	ActiveScans         int           `json:"activeScans"`
+	QueuedScans         int           `json:"queuedScans"`
+	CompletedScans      int           `json:"completedScans"`
+	AverageQueueWait    time.Duration `json:"averageQueueWait"`
+	MaxQueueWait        time.Duration `json:"maxQueueWait"`
+	AverageScanDuration time.Duration `json:"averageScanDuration"`
+	MaxScanDuration     time.Duration `json:"maxScanDuration"`
+
+	// EnqueueFailures counts the scan results that couldn't be passed to the plugin's goroutine, and were lost.
+	EnqueueFailures int `json:"enqueueFailures"`
+
+	// Hosts and Collectors are sorted by name.
+	Hosts      []HostScanMetrics  `json:"hosts"`
+	Collectors []CollectorMetrics `json:"collectors"`
+}
+
+// SkippedTicks returns the number of scans skipped by all the hosts, because earlier scans overran.
+func (s PluginStatus) SkippedTicks() int {
+	result := 0
+
+	for _, host := range s.Hosts {
+		result += host.SkippedTicks
+	}
+
+	return result
+}
+
+// Timeouts returns the number of collections that timed out, across all collectors.
+func (s PluginStatus) Timeouts() int {
+	result := 0
+
+	for _, collector := range s.Collectors {
+		result += collector.Timeouts
+	}
+
+	return result
+}
+
+// HostScanMetrics describes the scans of a single host.
+type HostScanMetrics struct {
+	Name             string        `json:"name"`
+	Scans            int           `json:"scans"`
+	LastScanDuration time.Duration `json:"lastScanDuration"`
+	MaxScanDuration  time.Duration `json:"maxScanDuration"`
+
+	// Overruns counts the scans that took longer than the scan interval, and SkippedTicks the scheduled scans
+	// that were dropped as a result.
+	Overruns     int `json:"overruns"`
+	SkippedTicks int `json:"skippedTicks"`
+
+	// BulkWalkFallbacks counts the times SNMP bulk walks failed, and the host was walked one OID at a time instead.
+	BulkWalkFallbacks int `json:"bulkWalkFallbacks"`
+	SnmpPdusSent      int `json:"snmpPdusSent"`
+	SnmpPdusReceived  int `json:"snmpPdusReceived"`
+	SnmpRetries       int `json:"snmpRetries"`
+}
+
+// CollectorMetrics describes the runs of a collector, across all hosts.
+type CollectorMetrics struct {
+	Name          string        `json:"name"`
+	Runs          int           `json:"runs"`
+	Errors        int           `json:"errors"`
+	Timeouts      int           `json:"timeouts"`
+	TotalDuration time.Duration `json:"totalDuration"`
+	MaxDuration   time.Duration `json:"maxDuration"`
+}
+
+// AverageDuration returns the mean duration of the collector's runs.
+func (m CollectorMetrics) AverageDuration() time.Duration {
+	if m.Runs == 0 {
+		return 0
+	}
+
+	return m.TotalDuration / time.Duration(m.Runs)
+}
@@ -0,0 +1,234 @@
+package data
+
+import (
+	"slices"
+	"time"
+)
+
+// RateHistoryRetention is the number of points kept at each resolution of a RateHistory.  Values less than 1
+// are replaced with the defaults.
+type RateHistoryRetention struct {
+	RawSamples          int
+	FiveMinuteIntervals int
+	HourlyIntervals     int
+	DailyIntervals      int
+}
+
+// DefaultRateHistoryRetention keeps an hour of raw samples at the default scan interval, a day of 5-minute
+// intervals, a month of hourly intervals and a year of daily intervals.
+var DefaultRateHistoryRetention = RateHistoryRetention{
+	RawSamples:          64,
+	FiveMinuteIntervals: 288,
+	HourlyIntervals:     744,
+	DailyIntervals:      365,
+}
+
+// RatePoint is a single point of a rate history.  Raw points hold one sample.  Consolidated points hold the
+// average and maximum of the samples taken within the interval starting at Time.  Samples is zero for intervals
+// without any samples.
+type RatePoint struct {
+	Time    time.Time
+	Average float64
+	Max     float64
+	Samples int
+}
+
+// RateSeries is the part of a rate history covering a time window, oldest point first.  Resolution is the length
+// of the consolidation interval, or zero for raw samples.
+type RateSeries struct {
+	Resolution time.Duration
+	Points     []RatePoint
+}
+
+// Averages returns the average of each point of the series.
+func (s *RateSeries) Averages() []float64 {
+	result := make([]float64, len(s.Points))
+
+	for i := range s.Points {
+		result[i] = s.Points[i].Average
+	}
+
+	return result
+}
+
+// Maxima returns the maximum of each point of the series.
+func (s *RateSeries) Maxima() []float64 {
+	result := make([]float64, len(s.Points))
+
+	for i := range s.Points {
+		result[i] = s.Points[i].Max
+	}
+
+	return result
+}
+
+type rateArchive struct {
+	interval time.Duration
+	size     int
+	points   []RatePoint
+}
+
+// RateHistory keeps a rate at multiple resolutions, in the manner of a round-robin database: the raw samples,
+// and their 5-minute, hourly and daily consolidations.  Each resolution is a ring with its own retention, so
+// the coarser resolutions cover longer periods.  Intervals are aligned to UTC, so daily intervals start at UTC
+// midnight.  It is not thread safe.
+type RateHistory struct {
+	archives []*rateArchive
+}
+
+// NewRateHistory creates an empty history with the given retention.
+func NewRateHistory(retention RateHistoryRetention) *RateHistory {
+	sizeOrDefault := func(size int, defaultSize int) int {
+		if size < 1 {
+			return defaultSize
+		}
+
+		return size
+	}
+
+	return &RateHistory{
+		archives: []*rateArchive{
+			{
+				interval: 0,
+				size:     sizeOrDefault(retention.RawSamples, DefaultRateHistoryRetention.RawSamples),
+			},
+			{
+				interval: 5 * time.Minute,
+				size:     sizeOrDefault(retention.FiveMinuteIntervals, DefaultRateHistoryRetention.FiveMinuteIntervals),
+			},
+			{
+				interval: time.Hour,
+				size:     sizeOrDefault(retention.HourlyIntervals, DefaultRateHistoryRetention.HourlyIntervals),
+			},
+			{
+				interval: 24 * time.Hour,
+				size:     sizeOrDefault(retention.DailyIntervals, DefaultRateHistoryRetention.DailyIntervals),
+			},
+		},
+	}
+}
+
+// Add records a sample taken at the given time.  Samples must be added in chronological order.
+func (h *RateHistory) Add(sampleTime time.Time, value float64) {
+	if h == nil {
+		return
+	}
+
+	for _, archive := range h.archives {
+		archive.add(sampleTime, value)
+	}
+}
+
+// Clone returns a deep copy of the history, which can be read while the original keeps changing.
+func (h *RateHistory) Clone() *RateHistory {
+	if h == nil {
+		return nil
+	}
+
+	result := &RateHistory{archives: make([]*rateArchive, len(h.archives))}
+
+	for i, archive := range h.archives {
+		result.archives[i] = &rateArchive{
+			interval: archive.interval,
+			size:     archive.size,
+			points:   slices.Clone(archive.points),
+		}
+	}
+
+	return result
+}
+
+// Series returns the points covering the window that ends now, at the finest resolution that covers the whole
+// window with at most maxPoints points.  If no resolution does, the coarsest one is used.
+func (h *RateHistory) Series(window time.Duration, maxPoints int, now time.Time) RateSeries {
+	if h == nil || len(h.archives) == 0 {
+		return RateSeries{Points: []RatePoint{}}
+	}
+
+	start := now.Add(-window)
+
+	for _, archive := range h.archives {
+		if archive.covers(start, window, maxPoints) {
+			return archive.series(start, now)
+		}
+	}
+
+	return h.archives[len(h.archives)-1].series(start, now)
+}
+
+func (a *rateArchive) add(sampleTime time.Time, value float64) {
+	if a.interval != 0 {
+		intervalStart := sampleTime.Truncate(a.interval)
+
+		if len(a.points) != 0 && a.points[len(a.points)-1].Time.Equal(intervalStart) {
+			point := &a.points[len(a.points)-1]
+			point.Samples++
+			point.Average += (value - point.Average) / float64(point.Samples)
+			point.Max = max(point.Max, value)
+
+			return
+		}
+
+		sampleTime = intervalStart
+	}
+
+	a.points = append(a.points, RatePoint{Time: sampleTime, Average: value, Max: value, Samples: 1})
+
+	if len(a.points) > a.size {
+		a.points = slices.Delete(a.points, 0, len(a.points)-a.size)
+	}
+}
+
+// covers returns true if the archive holds the whole window, in at most maxPoints points.  Raw samples aren't
+// taken at a fixed interval, so the raw archive covers a window if its oldest sample precedes the window, or if
+// it hasn't filled up yet, in which case no other archive holds older data.
+func (a *rateArchive) covers(start time.Time, window time.Duration, maxPoints int) bool {
+	if a.interval == 0 {
+		if len(a.points) == 0 {
+			return false
+		}
+
+		count := 0
+		firstInWindow := slices.IndexFunc(a.points, func(point RatePoint) bool { return !point.Time.Before(start) })
+
+		if firstInWindow >= 0 {
+			count = len(a.points) - firstInWindow
+		}
+
+		return count <= maxPoints && (len(a.points) < a.size || !a.points[0].Time.After(start))
+	}
+
+	return a.interval*time.Duration(maxPoints) >= window && a.interval*time.Duration(a.size) >= window
+}
+
+// series returns the archive's points from start to now.  Consolidated archives return a point for every
+// interval, including empty ones, so the points are evenly spaced.
+func (a *rateArchive) series(start time.Time, now time.Time) RateSeries {
+	result := RateSeries{Resolution: a.interval, Points: make([]RatePoint, 0)}
+
+	if a.interval == 0 {
+		for _, point := range a.points {
+			if !point.Time.Before(start) && !point.Time.After(now) {
+				result.Points = append(result.Points, point)
+			}
+		}
+
+		return result
+	}
+
+	index := 0
+
+	for intervalStart := start.Truncate(a.interval); !intervalStart.After(now); intervalStart = intervalStart.Add(a.interval) {
+		for index < len(a.points) && a.points[index].Time.Before(intervalStart) {
+			index++
+		}
+
+		if index < len(a.points) && a.points[index].Time.Equal(intervalStart) {
+			result.Points = append(result.Points, a.points[index])
+		} else {
+			result.Points = append(result.Points, RatePoint{Time: intervalStart})
+		}
+	}
+
+	return result
+}
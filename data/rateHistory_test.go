@@ -0,0 +1,121 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+var rateHistoryStart = time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+
+func newMinuteRateHistory(minutes int) *RateHistory {
+	history := NewRateHistory(DefaultRateHistoryRetention)
+
+	for i := 0; i < minutes; i++ {
+		history.Add(rateHistoryStart.Add(time.Duration(i)*time.Minute), float64(i%10))
+	}
+
+	return history
+}
+
+func TestRateHistory_Add_ConsolidatesAverageAndMax(t *testing.T) {
+	history := newMinuteRateHistory(10)
+	series := history.archives[1].series(rateHistoryStart, rateHistoryStart.Add(9*time.Minute))
+
+	if len(series.Points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(series.Points))
+	}
+
+	if series.Points[0].Average != 2.0 || series.Points[0].Max != 4.0 || series.Points[0].Samples != 5 {
+		t.Errorf("Expected average 2, max 4 of 5 samples, got %+v", series.Points[0])
+	}
+
+	if series.Points[1].Average != 7.0 || series.Points[1].Max != 9.0 {
+		t.Errorf("Expected average 7, max 9, got %+v", series.Points[1])
+	}
+}
+
+func TestRateHistory_Add_DropsPointsBeyondRetention(t *testing.T) {
+	history := NewRateHistory(RateHistoryRetention{RawSamples: 10, FiveMinuteIntervals: 2})
+
+	for i := 0; i < 20; i++ {
+		history.Add(rateHistoryStart.Add(time.Duration(i)*time.Minute), 1.0)
+	}
+
+	if len(history.archives[0].points) != 10 {
+		t.Errorf("Expected 10 raw samples, got %d", len(history.archives[0].points))
+	}
+
+	if len(history.archives[1].points) != 2 {
+		t.Errorf("Expected 2 5-minute intervals, got %d", len(history.archives[1].points))
+	}
+
+	if len(history.archives[2].points) != 1 {
+		t.Errorf("Expected 1 hourly interval, got %d", len(history.archives[2].points))
+	}
+}
+
+func TestRateHistory_Series_PicksResolutionForWindow(t *testing.T) {
+	history := newMinuteRateHistory(60 * 24 * 40)
+	now := rateHistoryStart.Add(60 * 24 * 40 * time.Minute)
+	windows := []struct {
+		window     time.Duration
+		resolution time.Duration
+	}{
+		{time.Hour, 0},
+		{6 * time.Hour, 5 * time.Minute},
+		{7 * 24 * time.Hour, time.Hour},
+		{30 * 24 * time.Hour, 24 * time.Hour},
+	}
+
+	for _, w := range windows {
+		series := history.Series(w.window, 200, now)
+
+		if series.Resolution != w.resolution {
+			t.Errorf("Expected resolution %v for %v, got %v", w.resolution, w.window, series.Resolution)
+		}
+
+		if len(series.Points) == 0 || len(series.Points) > 200 {
+			t.Errorf("Expected up to 200 points for %v, got %d", w.window, len(series.Points))
+		}
+	}
+}
+
+func TestRateHistory_Series_RecentHistoryUsesRawSamples(t *testing.T) {
+	history := newMinuteRateHistory(10)
+	series := history.Series(30*24*time.Hour, 200, rateHistoryStart.Add(10*time.Minute))
+
+	if series.Resolution != 0 || len(series.Points) != 10 {
+		t.Errorf("Expected 10 raw samples, got %d at %v", len(series.Points), series.Resolution)
+	}
+}
+
+func TestRateHistory_Series_FillsEmptyIntervals(t *testing.T) {
+	history := NewRateHistory(DefaultRateHistoryRetention)
+	history.Add(rateHistoryStart, 1.0)
+	history.Add(rateHistoryStart.Add(3*time.Hour), 2.0)
+
+	series := history.archives[2].series(rateHistoryStart, rateHistoryStart.Add(3*time.Hour))
+
+	if len(series.Points) != 4 {
+		t.Fatalf("Expected 4 points, got %d", len(series.Points))
+	}
+
+	if series.Points[1].Samples != 0 || !series.Points[1].Time.Equal(rateHistoryStart.Add(time.Hour)) {
+		t.Errorf("Expected an empty point, got %+v", series.Points[1])
+	}
+
+	if series.Points[3].Average != 2.0 {
+		t.Errorf("Expected 2.0, got %f", series.Points[3].Average)
+	}
+}
+
+func TestRateHistory_Clone_IsIndependent(t *testing.T) {
+	history := newMinuteRateHistory(5)
+	clone := history.Clone()
+
+	history.Add(rateHistoryStart.Add(5*time.Minute), 100.0)
+
+	if len(clone.archives[0].points) != 5 {
+		t.Errorf("Expected 5 raw samples, got %d", len(clone.archives[0].points))
+	}
+}
@@ -9,6 +9,10 @@ import (
 type Host interface {
 	tracking.HistoryAwareTrackable
 	Name() string
+
+	// RequestScan asks the plugin to scan the host now, rather than wait for its next scheduled scan.  Repeated
+	// requests are coalesced, and requested scans are spaced at least a few seconds apart.
+	RequestScan() error
 }
 
 var HostType = reflect.TypeOf((*Host)(nil)).Elem()
@@ -7,7 +7,7 @@ import (
 )
 
 type NetworkInterface interface {
-	tracking.Trackable
+	tracking.HistoryAwareTrackable
 }
 
 var NetworkInterfaceType = reflect.TypeOf((*NetworkInterface)(nil)).Elem()
@@ -1,6 +1,9 @@
 package netmon
 
 import (
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
 	"github.com/avanha/pmaas-plugin-netmon/internal/common"
 	"github.com/avanha/pmaas-spi"
 )
@@ -16,3 +19,53 @@ func (esa *entityStoreAdapter) GetStatusAndEntities() (common.StatusAndEntities,
 		func() common.StatusAndEntities { return common.StatusAndEntities{} },
 		"unable to get status and entities")
 }
+
+func (esa *entityStoreAdapter) QueryEventJournal(filter data.JournalFilter) ([]data.JournalEntry, error) {
+	return spi.ExecValueFunctionOnPluginGoRoutine(
+		esa.parent.container,
+		func() []data.JournalEntry { return esa.parent.queryEventJournal(filter) },
+		func() []data.JournalEntry { return []data.JournalEntry{} },
+		"unable to query event journal")
+}
+
+func (esa *entityStoreAdapter) StartAdHocMaintenance(hostName string, hostGroup string, duration time.Duration) error {
+	result, err := spi.ExecValueFunctionOnPluginGoRoutine(
+		esa.parent.container,
+		func() error { return esa.parent.startAdHocMaintenance(hostName, hostGroup, duration) },
+		func() error { return nil },
+		"unable to start maintenance")
+
+	if err != nil {
+		return err
+	}
+
+	return result
+}
+
+func (esa *entityStoreAdapter) EndAdHocMaintenance(hostName string, hostGroup string) error {
+	result, err := spi.ExecValueFunctionOnPluginGoRoutine(
+		esa.parent.container,
+		func() error { return esa.parent.endAdHocMaintenance(hostName, hostGroup) },
+		func() error { return nil },
+		"unable to end maintenance")
+
+	if err != nil {
+		return err
+	}
+
+	return result
+}
+
+func (esa *entityStoreAdapter) RequestScan(hostName string) error {
+	result, err := spi.ExecValueFunctionOnPluginGoRoutine(
+		esa.parent.container,
+		func() error { return esa.parent.requestScan(hostName) },
+		func() error { return nil },
+		"unable to request scan")
+
+	if err != nil {
+		return err
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package events
+
+import "time"
+
+type AlertSeverity int
+
+const (
+	AlertSeverityInfo AlertSeverity = iota
+	AlertSeverityWarning
+	AlertSeverityCritical
+)
+
+func (s AlertSeverity) String() string {
+	switch s {
+	case AlertSeverityInfo:
+		return "Info"
+	case AlertSeverityWarning:
+		return "Warning"
+	case AlertSeverityCritical:
+		return "Critical"
+	default:
+		return "Unknown"
+	}
+}
+
+// AlertEvent identifies an alert, which is the state of a single rule for a single host, or host interface.
+// NetInterface is the interface's entity id, and is empty for host level rules.
+type AlertEvent struct {
+	HostEvent
+	NetInterface     string
+	NetInterfaceName string
+	RuleName         string
+	Severity         AlertSeverity
+	Value            float64
+	Threshold        float64
+	Description      string
+}
+
+// AlertFiredEvent is broadcast when an alert rule's condition has held for the rule's duration.
+type AlertFiredEvent struct {
+	AlertEvent
+	FiredTime time.Time
+}
+
+// AlertResolvedEvent is broadcast when the condition of a fired alert no longer holds.  Value is the latest
+// value, which no longer crosses the threshold.
+type AlertResolvedEvent struct {
+	AlertEvent
+	FiredTime    time.Time
+	ResolvedTime time.Time
+}
@@ -2,12 +2,23 @@ package events
 
 import (
 	"net"
+	"time"
 
 	"github.com/avanha/pmaas-spi/events"
 )
 
 type HostEvent struct {
 	events.EntityEvent
+
+	// MaintenanceWindow is the name of the maintenance window the host was in when the event occurred, or empty
+	// if it was not in maintenance.
+	MaintenanceWindow string
+}
+
+// GetHostEvent returns the fields common to all host events.  Since every event embeds HostEvent, this allows
+// events to be handled uniformly.
+func (e HostEvent) GetHostEvent() HostEvent {
+	return e
 }
 
 type HostUptimeChangeEvent struct {
@@ -26,19 +37,71 @@ type HostReachabilityChangeEvent struct {
 	HostEvent
 	OldValue int
 	NewValue int
+
+	// RootCauseHost is the name of the host whose outage caused this host to become unreachable, or empty
+	// if this host is unreachable in its own right, or reachable.
+	RootCauseHost string
+}
+
+// HostFlappingChangeEvent is broadcast when a host starts or stops flapping.  While a host is flapping,
+// intermediate HostReachabilityChangeEvents are suppressed.
+type HostFlappingChangeEvent struct {
+	HostEvent
+	OldValue bool
+	NewValue bool
 }
 
 type HostInterfaceEvent struct {
 	HostEvent
-	NetInterface string
+	NetInterface     string
+	NetInterfaceName string
 }
 
+// GetHostInterfaceEvent returns the fields common to all host interface events.
+func (e HostInterfaceEvent) GetHostInterfaceEvent() HostInterfaceEvent {
+	return e
+}
+
+// HostInterfaceStatusChangeEvent is raised when the operational or the administrative status of an interface
+// changes.  OldValue and NewValue are the operational status, such as "Up", "Down" or "Lower Layer Down", while
+// OldAdminStatus and NewAdminStatus are "Up", "Down" or "Testing".
 type HostInterfaceStatusChangeEvent struct {
+	HostInterfaceEvent
+	OldValue       string
+	NewValue       string
+	OldAdminStatus string
+	NewAdminStatus string
+}
+
+// IsAdminDown returns true if the interface is down because it was disabled administratively, rather than due
+// to a fault.
+func (e HostInterfaceStatusChangeEvent) IsAdminDown() bool {
+	return e.NewAdminStatus == "Down"
+}
+
+// HostInterfaceSpeedChangeEvent is raised when the speed of an interface changes, for example when a port
+// renegotiates a lower speed.  Values are in bits per second.
+type HostInterfaceSpeedChangeEvent struct {
+	HostInterfaceEvent
+	OldValue uint64
+	NewValue uint64
+}
+
+// HostInterfaceDuplexChangeEvent is raised when the duplex status of an Ethernet interface changes.  Values are
+// "Full", "Half" or "Unknown".
+type HostInterfaceDuplexChangeEvent struct {
 	HostInterfaceEvent
 	OldValue string
 	NewValue string
 }
 
+// HostInterfaceMtuChangeEvent is raised when the MTU of an interface changes.  Values are in bytes.
+type HostInterfaceMtuChangeEvent struct {
+	HostInterfaceEvent
+	OldValue int32
+	NewValue int32
+}
+
 type HostInterfaceAddressChangeEvent struct {
 	HostInterfaceEvent
 	OldValue []net.IP
@@ -57,18 +120,46 @@ type HostInterfaceTrafficStatsChangeEvent struct {
 	NewPacketsOut uint64
 }
 
-type HostInterfaceErrorStatsChangeEvent struct {
+// HostInterfaceErrorRateEvent is raised when the combined error rate of an interface rises above its configured
+// threshold, and again when it falls back to or below it.  Rates are in errors per second, and ErrorRatio is the
+// number of errors per packet.
+type HostInterfaceErrorRateEvent struct {
+	HostInterfaceEvent
+	ErrorsInRate  float64
+	ErrorsOutRate float64
+	ErrorRatio    float64
+	Threshold     float64
+	Exceeded      bool
+}
+
+// HostInterfaceBroadcastStormEvent is raised when the broadcast packet rate of an interface rises above its
+// configured threshold, and again when it falls back to or below it.  Rates are in packets per second.
+type HostInterfaceBroadcastStormEvent struct {
+	HostInterfaceEvent
+	BroadcastInRate  float64
+	BroadcastOutRate float64
+	Threshold        float64
+	Active           bool
+}
+
+// HostInterfaceDataCapEvent is raised when the usage of an interface reaches one of the configured percentages of
+// its data cap, once per percentage and billing cycle.  Usage, DataCap and ProjectedUsage are in bytes.
+type HostInterfaceDataCapEvent struct {
 	HostInterfaceEvent
-	OldErrorsIn  uint64
-	NewErrorsIn  uint64
-	OldErrorsOut uint64
-	NewErrorsOut uint64
+	Usage          uint64
+	DataCap        uint64
+	Percent        float64
+	ProjectedUsage uint64
+	CycleStart     time.Time
+	CycleEnd       time.Time
 }
 
-type HostInterfaceDiscardStatsChangeEvent struct {
+// HostInterfaceDiscardRateEvent is raised when the combined discard rate of an interface rises above its
+// configured threshold, and again when it falls back to or below it.  Rates are in discards per second.
+type HostInterfaceDiscardRateEvent struct {
 	HostInterfaceEvent
-	OldDiscardsIn  uint64
-	NewDiscardsIn  uint64
-	OldDiscardsOut uint64
-	NewDiscardsOut uint64
+	DiscardsInRate  float64
+	DiscardsOutRate float64
+	Threshold       float64
+	Exceeded        bool
 }
@@ -0,0 +1,294 @@
+package alerting
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+)
+
+type alertState struct {
+	pendingSince time.Time
+	firing       bool
+	alert        data.Alert
+	netInterface string
+}
+
+// Engine evaluates the configured alert rules after every host update, and tracks the state of each alert.  It is
+// not thread safe, and is meant to be used from the plugin's goroutine.
+type Engine struct {
+	rules  []config.AlertRule
+	states map[string]*alertState
+}
+
+// NewEngine creates an Engine for the configured rules.  Invalid rules are skipped and reported via the returned
+// error, but the returned Engine is always usable.
+func NewEngine(rules []config.AlertRule) (*Engine, error) {
+	var errs []error
+	engine := &Engine{
+		rules:  make([]config.AlertRule, 0, len(rules)),
+		states: make(map[string]*alertState),
+	}
+
+	for _, rule := range rules {
+		if err := validateRule(&rule, engine.rules); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		engine.rules = append(engine.rules, rule)
+	}
+
+	return engine, errors.Join(errs...)
+}
+
+func validateRule(rule *config.AlertRule, validRules []config.AlertRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("alert rule name is required")
+	}
+
+	if rule.Metric < config.AlertMetricPingRtt || rule.Metric > config.AlertMetricCertificateExpiryDays {
+		return fmt.Errorf("alert rule %s: invalid metric %d", rule.Name, rule.Metric)
+	}
+
+	if rule.DurationSeconds < 0 {
+		return fmt.Errorf("alert rule %s: duration must not be negative", rule.Name)
+	}
+
+	if slices.ContainsFunc(validRules, func(other config.AlertRule) bool { return other.Name == rule.Name }) {
+		return fmt.Errorf("alert rule %s: duplicate name", rule.Name)
+	}
+
+	return nil
+}
+
+// Evaluate applies the rules that select the host to its current state, and adds AlertFiredEvents and
+// AlertResolvedEvents for the alerts whose state changed.  While the host is in maintenance, pending alerts are
+// reset and no new alerts fire, but alerts that already fired can still resolve.
+func (e *Engine) Evaluate(h *host.Host, now time.Time, events *[]any) {
+	hostData := h.HostData()
+	hostEvent := h.HostEvent()
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+
+		if !appliesTo(rule, h.Name(), h.Groups()) {
+			continue
+		}
+
+		if !rule.Metric.IsInterfaceMetric() {
+			value, ok := hostMetricValue(rule.Metric, &hostData, now)
+
+			if ok {
+				e.update(rule, &hostEvent, "", "", value, hostData.InMaintenance(), now, events)
+			}
+
+			continue
+		}
+
+		for _, netInterface := range h.NetInterfaces() {
+			interfaceData := netInterface.CurrentInterfaceData()
+
+			if len(rule.Interfaces) != 0 && !slices.Contains(rule.Interfaces, interfaceData.Name) {
+				continue
+			}
+
+			value, ok := interfaceMetricValue(rule.Metric, interfaceData)
+
+			if ok {
+				e.update(rule, &hostEvent, netInterface.PmaasEntityId(), interfaceData.Name, value,
+					hostData.InMaintenance(), now, events)
+			}
+		}
+	}
+}
+
+func (e *Engine) update(
+	rule *config.AlertRule,
+	hostEvent *netmonevents.HostEvent,
+	netInterface string,
+	netInterfaceName string,
+	value float64,
+	inMaintenance bool,
+	now time.Time,
+	events *[]any) {
+	key := rule.Name + "|" + hostEvent.Name + "|" + netInterfaceName
+	state, exists := e.states[key]
+
+	if !conditionMet(rule, value) || (inMaintenance && !(exists && state.firing)) {
+		if exists {
+			if state.firing {
+				state.alert.Value = value
+				*events = append(*events, netmonevents.AlertResolvedEvent{
+					AlertEvent:   alertEvent(hostEvent, state),
+					FiredTime:    state.alert.FiredTime,
+					ResolvedTime: now,
+				})
+			}
+
+			delete(e.states, key)
+		}
+
+		return
+	}
+
+	if !exists {
+		state = &alertState{
+			pendingSince: now,
+			netInterface: netInterface,
+			alert: data.Alert{
+				RuleName:         rule.Name,
+				Severity:         rule.Severity,
+				HostName:         hostEvent.Name,
+				NetInterfaceName: netInterfaceName,
+				Threshold:        rule.Threshold,
+			},
+		}
+		e.states[key] = state
+	}
+
+	state.alert.Value = value
+	state.alert.Description = describe(rule, netInterfaceName, value)
+
+	if state.firing || now.Sub(state.pendingSince) < time.Duration(rule.DurationSeconds)*time.Second {
+		return
+	}
+
+	state.firing = true
+	state.alert.FiredTime = now
+	*events = append(*events, netmonevents.AlertFiredEvent{
+		AlertEvent: alertEvent(hostEvent, state),
+		FiredTime:  now,
+	})
+}
+
+func alertEvent(hostEvent *netmonevents.HostEvent, state *alertState) netmonevents.AlertEvent {
+	return netmonevents.AlertEvent{
+		HostEvent:        *hostEvent,
+		NetInterface:     state.netInterface,
+		NetInterfaceName: state.alert.NetInterfaceName,
+		RuleName:         state.alert.RuleName,
+		Severity:         state.alert.Severity,
+		Value:            state.alert.Value,
+		Threshold:        state.alert.Threshold,
+		Description:      state.alert.Description,
+	}
+}
+
+// ActiveAlerts returns the alerts that have fired and not yet resolved, the most severe first, and oldest first
+// within the same severity.
+func (e *Engine) ActiveAlerts() []data.Alert {
+	result := make([]data.Alert, 0)
+
+	for _, state := range e.states {
+		if state.firing {
+			result = append(result, state.alert)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b data.Alert) int {
+		return cmp.Or(
+			cmp.Compare(b.Severity, a.Severity),
+			a.FiredTime.Compare(b.FiredTime),
+			cmp.Compare(a.HostName, b.HostName),
+			cmp.Compare(a.NetInterfaceName, b.NetInterfaceName),
+			cmp.Compare(a.RuleName, b.RuleName))
+	})
+
+	return result
+}
+
+func appliesTo(rule *config.AlertRule, hostName string, hostGroups []string) bool {
+	if len(rule.Hosts) == 0 && len(rule.HostGroups) == 0 {
+		return true
+	}
+
+	if slices.Contains(rule.Hosts, hostName) {
+		return true
+	}
+
+	return slices.ContainsFunc(rule.HostGroups, func(group string) bool { return slices.Contains(hostGroups, group) })
+}
+
+// hostMetricValue returns the current value of a host metric, or false if the latest scan didn't measure it.
+func hostMetricValue(metric config.AlertMetric, hostData *data.HostData, now time.Time) (float64, bool) {
+	switch metric {
+	case config.AlertMetricPingRtt:
+		if hostData.PingPacketsSent == 0 || hostData.PingPacketLoss >= 100.0 {
+			return 0, false
+		}
+
+		return float64(hostData.PingRttAverage) / float64(time.Millisecond), true
+	case config.AlertMetricPingPacketLoss:
+		return hostData.PingPacketLoss, hostData.PingPacketsSent != 0
+	case config.AlertMetricCertificateExpiryDays:
+		if hostData.CertificateExpiryTime.IsZero() {
+			return 0, false
+		}
+
+		return float64(hostData.CertificateExpiryTime.Sub(now)) / float64(24*time.Hour), true
+	default:
+		return 0, false
+	}
+}
+
+// interfaceMetricValue returns the current value of an interface metric, or false if it hasn't been measured.
+func interfaceMetricValue(metric config.AlertMetric, interfaceData *data.NetInterfaceData) (float64, bool) {
+	if interfaceData.LastUpdateTime.IsZero() {
+		return 0, false
+	}
+
+	switch metric {
+	case config.AlertMetricInterfaceErrorRate:
+		return interfaceData.ErrorRate, true
+	case config.AlertMetricInterfaceUtilization:
+		return interfaceData.Utilization, interfaceData.Speed != 0
+	case config.AlertMetricInterfaceDown:
+		// Interfaces that were disabled administratively aren't considered down
+		if interfaceData.IsFailed() {
+			return 1, true
+		}
+
+		return 0, interfaceData.Status != ""
+	default:
+		return 0, false
+	}
+}
+
+func conditionMet(rule *config.AlertRule, value float64) bool {
+	switch rule.Metric {
+	case config.AlertMetricInterfaceDown:
+		return value != 0
+	case config.AlertMetricCertificateExpiryDays:
+		return value < rule.Threshold
+	default:
+		return value > rule.Threshold
+	}
+}
+
+func describe(rule *config.AlertRule, netInterfaceName string, value float64) string {
+	subject := rule.Metric.String()
+
+	if netInterfaceName != "" {
+		subject = fmt.Sprintf("%s of %s", subject, netInterfaceName)
+	}
+
+	switch rule.Metric {
+	case config.AlertMetricInterfaceDown:
+		return fmt.Sprintf("Interface %s is down", netInterfaceName)
+	case config.AlertMetricCertificateExpiryDays:
+		return fmt.Sprintf("Certificate expires in %.1f days, threshold is %.1f days", value, rule.Threshold)
+	case config.AlertMetricPingRtt:
+		return fmt.Sprintf("%s is %.1f ms, above %.1f ms", subject, value, rule.Threshold)
+	case config.AlertMetricInterfaceErrorRate:
+		return fmt.Sprintf("%s is %.2f/s, above %.2f/s", subject, value, rule.Threshold)
+	default:
+		return fmt.Sprintf("%s is %.1f%%, above %.1f%%", subject, value, rule.Threshold)
+	}
+}
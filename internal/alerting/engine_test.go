@@ -0,0 +1,181 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+	"github.com/avanha/pmaas-plugin-netmon/internal/maintenance"
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+var startTime = time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestHost() *host.Host {
+	return host.NewHost("Host_1", config.Host{
+		Name:        "test",
+		IpAddress:   "127.0.0.1",
+		PingEnabled: true,
+		Groups:      []string{"servers"},
+	}, tracking.Config{}, nil)
+}
+
+func updateHost(h *host.Host, scan int, rtt time.Duration) time.Time {
+	now := startTime.Add(time.Duration(scan) * time.Minute)
+	events := make([]any, 0)
+	h.Update(&common.HostData{
+		LastUpdateTime:  now,
+		PingStatus:      "OK",
+		PingPacketsSent: 4,
+		PingRttAvg:      rtt,
+	}, &events)
+
+	return now
+}
+
+func newRttRule() config.AlertRule {
+	pluginConfig := config.PluginConfig{}
+	pluginConfig.AddAlertRule("slow", config.AlertMetricPingRtt, 100.0).
+		ForHostGroups("servers").
+		WithDuration(120).
+		WithSeverity(netmonevents.AlertSeverityCritical)
+
+	return pluginConfig.AlertRules[0]
+}
+
+func TestNewEngine_InvalidRules_SkippedAndReported(t *testing.T) {
+	engine, err := NewEngine([]config.AlertRule{
+		{Name: "", Metric: config.AlertMetricPingRtt},
+		{Name: "bad metric", Metric: 0},
+		{Name: "good", Metric: config.AlertMetricPingRtt},
+		{Name: "good", Metric: config.AlertMetricPingPacketLoss},
+	})
+
+	if err == nil {
+		t.Errorf("Expected an error for the invalid rules")
+	}
+
+	if len(engine.rules) != 1 {
+		t.Errorf("Expected 1 valid rule, got %d", len(engine.rules))
+	}
+}
+
+func TestEvaluate_ConditionHoldsForDuration_FiresThenResolves(t *testing.T) {
+	engine, _ := NewEngine([]config.AlertRule{newRttRule()})
+	h := newTestHost()
+	events := make([]any, 0)
+
+	for i := 0; i < 2; i++ {
+		engine.Evaluate(h, updateHost(h, i, 150*time.Millisecond), &events)
+	}
+
+	if len(events) != 0 || len(engine.ActiveAlerts()) != 0 {
+		t.Fatalf("Expected no alert before the duration elapsed, got %v", events)
+	}
+
+	engine.Evaluate(h, updateHost(h, 2, 150*time.Millisecond), &events)
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %v", events)
+	}
+
+	fired, ok := events[0].(netmonevents.AlertFiredEvent)
+
+	if !ok || fired.RuleName != "slow" || fired.Severity != netmonevents.AlertSeverityCritical || fired.Value != 150.0 {
+		t.Errorf("Unexpected event %v", events[0])
+	}
+
+	if alerts := engine.ActiveAlerts(); len(alerts) != 1 || alerts[0].HostName != "test" {
+		t.Errorf("Expected 1 active alert for test, got %v", alerts)
+	}
+
+	events = events[:0]
+	engine.Evaluate(h, updateHost(h, 3, 150*time.Millisecond), &events)
+	engine.Evaluate(h, updateHost(h, 4, 20*time.Millisecond), &events)
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %v", events)
+	}
+
+	if resolved, ok := events[0].(netmonevents.AlertResolvedEvent); !ok || !resolved.FiredTime.Equal(fired.FiredTime) {
+		t.Errorf("Expected AlertResolvedEvent, got %v", events[0])
+	}
+
+	if alerts := engine.ActiveAlerts(); len(alerts) != 0 {
+		t.Errorf("Expected no active alerts, got %v", alerts)
+	}
+}
+
+func TestEvaluate_ConditionClearsBeforeDuration_DoesNotFire(t *testing.T) {
+	engine, _ := NewEngine([]config.AlertRule{newRttRule()})
+	h := newTestHost()
+	events := make([]any, 0)
+
+	engine.Evaluate(h, updateHost(h, 0, 150*time.Millisecond), &events)
+	engine.Evaluate(h, updateHost(h, 1, 20*time.Millisecond), &events)
+	engine.Evaluate(h, updateHost(h, 2, 150*time.Millisecond), &events)
+	engine.Evaluate(h, updateHost(h, 3, 150*time.Millisecond), &events)
+
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %v", events)
+	}
+}
+
+func TestEvaluate_InMaintenance_DoesNotFire(t *testing.T) {
+	engine, _ := NewEngine([]config.AlertRule{newRttRule()})
+	h := newTestHost()
+	h.SetMaintenance(&maintenance.ActiveWindow{Name: "reboot", EndTime: startTime.Add(time.Hour)})
+	events := make([]any, 0)
+
+	for i := 0; i < 5; i++ {
+		engine.Evaluate(h, updateHost(h, i, 150*time.Millisecond), &events)
+	}
+
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %v", events)
+	}
+}
+
+func TestInterfaceMetricValue_InterfaceDown(t *testing.T) {
+	interfaceData := data.NetInterfaceData{LastUpdateTime: startTime, Status: "Down"}
+	rule := config.AlertRule{Metric: config.AlertMetricInterfaceDown}
+
+	value, ok := interfaceMetricValue(rule.Metric, &interfaceData)
+
+	if !ok || !conditionMet(&rule, value) {
+		t.Errorf("Expected the condition to be met, got %v, %v", value, ok)
+	}
+
+	interfaceData.Status = "Up"
+	value, ok = interfaceMetricValue(rule.Metric, &interfaceData)
+
+	if !ok || conditionMet(&rule, value) {
+		t.Errorf("Expected the condition not to be met, got %v, %v", value, ok)
+	}
+}
+
+func TestInterfaceMetricValue_InterfaceAdminDown_ConditionNotMet(t *testing.T) {
+	interfaceData := data.NetInterfaceData{LastUpdateTime: startTime, Status: "Down", AdminStatus: "Down"}
+	rule := config.AlertRule{Metric: config.AlertMetricInterfaceDown}
+
+	value, ok := interfaceMetricValue(rule.Metric, &interfaceData)
+
+	if !ok || conditionMet(&rule, value) {
+		t.Errorf("Expected the condition not to be met, got %v, %v", value, ok)
+	}
+}
+
+func TestConditionMet_CertificateExpiry_BelowThreshold(t *testing.T) {
+	rule := config.AlertRule{Metric: config.AlertMetricCertificateExpiryDays, Threshold: 14}
+	hostData := data.HostData{CertificateExpiryTime: startTime.Add(10 * 24 * time.Hour)}
+
+	value, ok := hostMetricValue(rule.Metric, &hostData, startTime)
+
+	if !ok || value != 10.0 || !conditionMet(&rule, value) {
+		t.Errorf("Expected 10 days to meet the condition, got %v, %v", value, ok)
+	}
+}
@@ -3,10 +3,17 @@ package common
 import "github.com/avanha/pmaas-plugin-netmon/data"
 
 type StatusAndEntities struct {
-	//Status     data.PluginStatus
-	Hosts []data.HostData
+	Status data.PluginStatus
+	Hosts  []data.HostData
+	Alerts []data.Alert
+
+	// NotificationDeliveries lists the most recent notification deliveries, newest first
+	NotificationDeliveries []data.NotificationDelivery
 }
 
 type EntityStore interface {
 	GetStatusAndEntities() (StatusAndEntities, error)
+
+	// QueryEventJournal returns the recorded events that match the filter, newest first
+	QueryEventJournal(filter data.JournalFilter) ([]data.JournalEntry, error)
 }
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time.  The monitoring code reads the time through a Clock, so tests can control it.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the Clock that returns the system time.
+var SystemClock Clock = systemClock{}
+
+// ManualClock is a Clock whose time only changes when it's set or advanced, for tests.  It is thread safe.
+type ManualClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = now
+}
+
+// Advance moves the time forward by the duration, and returns the new time.
+func (c *ManualClock) Advance(duration time.Duration) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(duration)
+
+	return c.now
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-spi"
+	"github.com/avanha/pmaas-spi/events"
+)
+
+// RegisterEventListeners registers a receiver for the events broadcast by the given source entity, that passes
+// each event to the configured listeners that accept it.  The filter narrows the events further, for example to
+// those of a single interface.  Listeners are invoked on the server's main goroutine.  Returns the receiver's
+// handle, or zero if there are no listeners.
+func RegisterEventListeners(
+	container spi.IPMAASContainer,
+	sourceEntityId string,
+	listeners []config.EventListener,
+	filter func(event any) bool) (int, error) {
+	if len(listeners) == 0 {
+		return 0, nil
+	}
+
+	predicate := func(info *events.EventInfo) bool {
+		if info.SourceEntityId != sourceEntityId || !filter(info.Event) {
+			return false
+		}
+
+		for i := range listeners {
+			if listeners[i].Accepts(info.Event) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	receiver := func(info *events.EventInfo) error {
+		event := info.Event
+		invocations := make([]func(), 0, len(listeners))
+
+		for i := range listeners {
+			if listeners[i].Accepts(event) {
+				invocations = append(invocations, func() {
+					listeners[i].Invoke(event)
+				})
+			}
+		}
+
+		err := container.EnqueueOnServerGoRoutine(invocations)
+
+		if err != nil {
+			return fmt.Errorf("error enqueuing %T listener invocation: %w", event, err)
+		}
+
+		return nil
+	}
+
+	return container.RegisterEventReceiver(predicate, receiver)
+}
@@ -2,6 +2,20 @@ package common
 
 import "time"
 
+// Names of the built-in collectors
+const (
+	CollectorPing        = "ping"
+	CollectorSnmp        = "snmp"
+	CollectorCertificate = "certificate"
+)
+
+// CollectorResult is the outcome of a single run of a collector.  Err is nil if the collector succeeded.
+type CollectorResult struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Err       error
+}
+
 type HostData struct {
 	LastUpdateTime  time.Time
 	SnmpSuccess     bool
@@ -15,4 +29,26 @@ type HostData struct {
 	PingRttMin      time.Duration
 	PingRttMax      time.Duration
 	PingRttStdDev   time.Duration
+
+	CertificateStatus     string
+	CertificateExpiryTime time.Time
+
+	// ScanInterval is the interval between the host's scans.
+	ScanInterval time.Duration
+
+	// SnmpInterval is the current interval between SNMP collections, and SnmpBackoff is true while it's extended
+	// because the host is unreachable.
+	SnmpInterval time.Duration
+	SnmpBackoff  bool
+
+	// CollectorResults holds the result of each collector that ran during the scan, by collector name.
+	// Collectors that are disabled, or weren't due, are absent.
+	CollectorResults map[string]CollectorResult
+}
+
+// Collected returns true if the named collector ran during the scan.
+func (d *HostData) Collected(name string) bool {
+	_, ok := d.CollectorResults[name]
+
+	return ok
 }
@@ -39,6 +39,8 @@ type IfData struct {
 	OutDiscards        uint32
 	Mtu                int32
 	Speed              uint32
+	HighSpeed          uint32
+	DuplexStatus       int32
 	PhysAddress        string
 	AdminStatus        int32
 	OperStatus         int32
@@ -46,6 +48,16 @@ type IfData struct {
 	IpAddresses        []IpMapEntry
 }
 
+// GetSpeed returns the speed in bits per second.  ifSpeed is a 32-bit gauge that reports its maximum value for
+// interfaces faster than 4.294 Gbps, so ifHighSpeed, which is in Mbps, is used for those, if available.
+func (ifd *IfData) GetSpeed() uint64 {
+	if ifd.HighSpeed != 0 && (ifd.Speed == math.MaxUint32 || ifd.Speed == 0) {
+		return uint64(ifd.HighSpeed) * 1_000_000
+	}
+
+	return uint64(ifd.Speed)
+}
+
 func (ifd *IfData) GetInOctets() uint64 {
 	if ifd.HCInOctets != 0 {
 		return ifd.HCInOctets
@@ -91,9 +103,63 @@ func (ifd *IfData) GetAllOutPackets() uint64 {
 		return ifd.HCOutUcastPkts + ifd.HCOutBroadcastPkts + ifd.HCOutMulticastPkts
 	}
 
+	return uint64(ifd.OutUcastPkts)
+}
+
+func (ifd *IfData) GetAllInPacketsMaxValue() uint64 {
+	if ifd.HCInUcastPkts != 0 || ifd.HCInBroadcastPkts != 0 || ifd.HCInMulticastPkts != 0 {
+		return math.MaxUint64
+	}
+
+	return uint64(math.MaxUint32)
+}
+
+func (ifd *IfData) GetAllOutPacketsMaxValue() uint64 {
+	if ifd.HCOutUcastPkts != 0 || ifd.HCOutBroadcastPkts != 0 || ifd.HCOutMulticastPkts != 0 {
+		return math.MaxUint64
+	}
+
+	return uint64(math.MaxUint32)
+}
+
+func (ifd *IfData) GetInUcastPkts() uint64 {
+	if ifd.HCInUcastPkts != 0 {
+		return ifd.HCInUcastPkts
+	}
+
 	return uint64(ifd.InUcastPkts)
 }
 
+func (ifd *IfData) GetInUcastPktsMaxValue() uint64 {
+	if ifd.HCInUcastPkts != 0 {
+		return math.MaxUint64
+	}
+
+	return uint64(math.MaxUint32)
+}
+
+func (ifd *IfData) GetOutUcastPkts() uint64 {
+	if ifd.HCOutUcastPkts != 0 {
+		return ifd.HCOutUcastPkts
+	}
+
+	return uint64(ifd.OutUcastPkts)
+}
+
+func (ifd *IfData) GetOutUcastPktsMaxValue() uint64 {
+	if ifd.HCOutUcastPkts != 0 {
+		return math.MaxUint64
+	}
+
+	return uint64(math.MaxUint32)
+}
+
+// GetErrorsMaxValue returns the maximum value of the error and discard counters, which are only available in
+// the 32-bit ifTable.
+func (ifd *IfData) GetErrorsMaxValue() uint64 {
+	return uint64(math.MaxUint32)
+}
+
 func (ifd *IfData) GetInErrors() uint64 {
 	return uint64(ifd.InErrors)
 }
@@ -0,0 +1,10 @@
+package common
+
+import "time"
+
+// MaintenanceController starts and ends ad-hoc maintenance windows.  Either a host name or a host group is
+// required.
+type MaintenanceController interface {
+	StartAdHocMaintenance(hostName string, hostGroup string, duration time.Duration) error
+	EndAdHocMaintenance(hostName string, hostGroup string) error
+}
@@ -0,0 +1,6 @@
+package common
+
+// ScanRequester requests immediate scans of hosts.
+type ScanRequester interface {
+	RequestScan(hostName string) error
+}
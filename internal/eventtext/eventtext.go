@@ -0,0 +1,143 @@
+package eventtext
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+)
+
+// Describe returns a short summary of an event, suitable for a title, and a longer message.
+func Describe(event any) (string, string) {
+	switch e := event.(type) {
+	case netmonevents.HostReachabilityChangeEvent:
+		summary := fmt.Sprintf("is %s", data.ReachabilityName(e.NewValue))
+		message := fmt.Sprintf("%s changed from %s to %s",
+			e.Name, data.ReachabilityName(e.OldValue), data.ReachabilityName(e.NewValue))
+
+		if e.RootCauseHost != "" {
+			message = fmt.Sprintf("%s, due to %s", message, e.RootCauseHost)
+		}
+
+		return summary, withMaintenance(message, &e.HostEvent)
+	case netmonevents.HostFlappingChangeEvent:
+		if e.NewValue {
+			return "is flapping", withMaintenance(fmt.Sprintf("%s started flapping", e.Name), &e.HostEvent)
+		}
+
+		return "stopped flapping", withMaintenance(fmt.Sprintf("%s stopped flapping", e.Name), &e.HostEvent)
+	case netmonevents.AlertFiredEvent:
+		return fmt.Sprintf("[%s] %s", e.Severity, e.RuleName),
+			withMaintenance(fmt.Sprintf("Alert %s fired: %s", e.RuleName, e.Description), &e.HostEvent)
+	case netmonevents.AlertResolvedEvent:
+		return fmt.Sprintf("[Resolved] %s", e.RuleName),
+			withMaintenance(fmt.Sprintf("Alert %s resolved after %s",
+				e.RuleName, e.ResolvedTime.Sub(e.FiredTime).Round(time.Second)), &e.HostEvent)
+	case netmonevents.HostInterfaceStatusChangeEvent:
+		if e.IsAdminDown() {
+			return "interface is disabled",
+				withMaintenance(fmt.Sprintf("Interface %s of %s was disabled administratively, status changed from %s to %s",
+					e.NetInterfaceName, e.Name, e.OldValue, e.NewValue), &e.HostEvent)
+		}
+
+		return fmt.Sprintf("interface is %s", e.NewValue),
+			withMaintenance(fmt.Sprintf("Interface %s of %s changed from %s to %s",
+				e.NetInterfaceName, e.Name, e.OldValue, e.NewValue), &e.HostEvent)
+	case netmonevents.HostInterfaceSpeedChangeEvent:
+		return fmt.Sprintf("interface speed is %s", FormatSpeed(e.NewValue)),
+			withMaintenance(fmt.Sprintf("Speed of interface %s of %s changed from %s to %s",
+				e.NetInterfaceName, e.Name, FormatSpeed(e.OldValue), FormatSpeed(e.NewValue)), &e.HostEvent)
+	case netmonevents.HostInterfaceDuplexChangeEvent:
+		return fmt.Sprintf("interface duplex is %s", e.NewValue),
+			withMaintenance(fmt.Sprintf("Duplex of interface %s of %s changed from %s to %s",
+				e.NetInterfaceName, e.Name, e.OldValue, e.NewValue), &e.HostEvent)
+	case netmonevents.HostInterfaceMtuChangeEvent:
+		return fmt.Sprintf("interface MTU is %d", e.NewValue),
+			withMaintenance(fmt.Sprintf("MTU of interface %s of %s changed from %d to %d",
+				e.NetInterfaceName, e.Name, e.OldValue, e.NewValue), &e.HostEvent)
+	case netmonevents.HostInterfaceErrorRateEvent:
+		if e.Exceeded {
+			return "interface error rate is high",
+				withMaintenance(fmt.Sprintf("Error rate of interface %s of %s is %.2f/s in, %.2f/s out, above %.2f/s",
+					e.NetInterfaceName, e.Name, e.ErrorsInRate, e.ErrorsOutRate, e.Threshold), &e.HostEvent)
+		}
+
+		return "interface error rate is normal",
+			withMaintenance(fmt.Sprintf("Error rate of interface %s of %s is back to %.2f/s",
+				e.NetInterfaceName, e.Name, e.ErrorsInRate+e.ErrorsOutRate), &e.HostEvent)
+	case netmonevents.HostInterfaceBroadcastStormEvent:
+		if e.Active {
+			return "interface broadcast storm",
+				withMaintenance(fmt.Sprintf("Broadcast storm on interface %s of %s: %.0f pps in, %.0f pps out, above %.0f pps",
+					e.NetInterfaceName, e.Name, e.BroadcastInRate, e.BroadcastOutRate, e.Threshold), &e.HostEvent)
+		}
+
+		return "interface broadcast storm ended",
+			withMaintenance(fmt.Sprintf("Broadcast storm on interface %s of %s ended, %.0f pps in, %.0f pps out",
+				e.NetInterfaceName, e.Name, e.BroadcastInRate, e.BroadcastOutRate), &e.HostEvent)
+	case netmonevents.HostInterfaceDataCapEvent:
+		return fmt.Sprintf("interface reached %.0f%% of data cap", e.Percent),
+			withMaintenance(fmt.Sprintf("Interface %s of %s used %.1f GB of its %.1f GB data cap in the cycle "+
+				"starting %s, projected to reach %.1f GB by %s",
+				e.NetInterfaceName, e.Name, gigabytes(e.Usage), gigabytes(e.DataCap), e.CycleStart.Format("2006-01-02"),
+				gigabytes(e.ProjectedUsage), e.CycleEnd.Format("2006-01-02")), &e.HostEvent)
+	case netmonevents.HostInterfaceDiscardRateEvent:
+		if e.Exceeded {
+			return "interface discard rate is high",
+				withMaintenance(fmt.Sprintf("Discard rate of interface %s of %s is %.2f/s in, %.2f/s out, above %.2f/s",
+					e.NetInterfaceName, e.Name, e.DiscardsInRate, e.DiscardsOutRate, e.Threshold), &e.HostEvent)
+		}
+
+		return "interface discard rate is normal",
+			withMaintenance(fmt.Sprintf("Discard rate of interface %s of %s is back to %.2f/s",
+				e.NetInterfaceName, e.Name, e.DiscardsInRate+e.DiscardsOutRate), &e.HostEvent)
+	default:
+		name := reflect.TypeOf(event).Name()
+
+		return name, fmt.Sprintf("%s: %+v", name, event)
+	}
+}
+
+// FormatSpeed formats a speed in bits per second, using the largest unit that keeps the value at or above one.
+func FormatSpeed(bitsPerSecond uint64) string {
+	switch {
+	case bitsPerSecond >= 1_000_000_000:
+		return fmt.Sprintf("%g Gbps", float64(bitsPerSecond)/1e9)
+	case bitsPerSecond >= 1_000_000:
+		return fmt.Sprintf("%g Mbps", float64(bitsPerSecond)/1e6)
+	case bitsPerSecond >= 1_000:
+		return fmt.Sprintf("%g kbps", float64(bitsPerSecond)/1e3)
+	default:
+		return fmt.Sprintf("%d bps", bitsPerSecond)
+	}
+}
+
+func gigabytes(bytes uint64) float64 {
+	return float64(bytes) / (1 << 30)
+}
+
+func withMaintenance(message string, hostEvent *netmonevents.HostEvent) string {
+	if hostEvent.MaintenanceWindow == "" {
+		return message
+	}
+
+	return fmt.Sprintf("%s (during maintenance window %s)", message, hostEvent.MaintenanceWindow)
+}
+
+// NetInterfaceName returns the name of the interface an event concerns, or empty if it concerns the host as a whole.
+func NetInterfaceName(event any) string {
+	switch e := event.(type) {
+	case netmonevents.AlertFiredEvent:
+		return e.NetInterfaceName
+	case netmonevents.AlertResolvedEvent:
+		return e.NetInterfaceName
+	case interface {
+		GetHostInterfaceEvent() netmonevents.HostInterfaceEvent
+	}:
+		return e.GetHostInterfaceEvent().NetInterfaceName
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,394 @@
+// Package fakecontainer is an in-memory spi.IPMAASContainer for plugin-level tests.  It runs the plugin's and the
+// server's goroutines, keeps track of registered entities, routes and event receivers, and delivers broadcast
+// events to the receivers, as the PMAAS server would.
+package fakecontainer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/avanha/pmaas-spi"
+	"github.com/avanha/pmaas-spi/entity"
+	"github.com/avanha/pmaas-spi/events"
+)
+
+// BroadcastEvent is an event broadcast through the container.
+type BroadcastEvent struct {
+	SourceEntityId string
+	Event          any
+}
+
+type eventReceiver struct {
+	predicate events.EventPredicate
+	receiver  events.EventReceiver
+}
+
+// Container implements spi.IPMAASContainer.  Functions enqueued on the plugin's goroutine run one at a time on a
+// goroutine owned by the container, and so do functions enqueued on the server's goroutine.  Rendering and
+// templates aren't supported.  It is thread safe.
+type Container struct {
+	mutex           sync.Mutex
+	entities        []entity.RegisteredEntityInfo
+	routes          map[string]http.HandlerFunc
+	renderers       map[reflect.Type]spi.EntityRendererFactory
+	contentPrefixes []string
+	staticContent   []string
+	receivers       map[int]eventReceiver
+	receiverCounter int
+	events          []BroadcastEvent
+	queueMutex      sync.RWMutex
+	pluginQueue     chan func()
+	serverQueue     chan func()
+	closed          bool
+	goroutines      sync.WaitGroup
+}
+
+// New creates a container and starts its goroutines.  Call Close to stop them.
+func New() *Container {
+	c := &Container{
+		routes:      make(map[string]http.HandlerFunc),
+		renderers:   make(map[reflect.Type]spi.EntityRendererFactory),
+		receivers:   make(map[int]eventReceiver),
+		pluginQueue: make(chan func(), 1000),
+		serverQueue: make(chan func(), 1000),
+	}
+	c.goroutines.Go(func() { runQueue(c.pluginQueue) })
+	c.goroutines.Go(func() { runQueue(c.serverQueue) })
+
+	return c
+}
+
+func runQueue(queue chan func()) {
+	for f := range queue {
+		f()
+	}
+}
+
+// Close stops the container's goroutines, after they run the functions already enqueued.
+func (c *Container) Close() {
+	c.queueMutex.Lock()
+
+	if c.closed {
+		c.queueMutex.Unlock()
+		return
+	}
+
+	c.closed = true
+	close(c.pluginQueue)
+	close(c.serverQueue)
+	c.queueMutex.Unlock()
+
+	c.goroutines.Wait()
+}
+
+// RunOnPluginGoRoutine runs the function on the plugin's goroutine, and waits for it to complete.  Tests use it to
+// call the plugin as the server would.
+func (c *Container) RunOnPluginGoRoutine(f func()) error {
+	done := make(chan struct{})
+
+	if err := c.EnqueueOnPluginGoRoutine(func() { defer close(done); f() }); err != nil {
+		return err
+	}
+
+	<-done
+
+	return nil
+}
+
+// SyncServerGoRoutine waits until the functions already enqueued on the server's goroutine have run.
+func (c *Container) SyncServerGoRoutine() error {
+	done := make(chan struct{})
+
+	if err := c.EnqueueOnServerGoRoutine([]func(){func() { close(done) }}); err != nil {
+		return err
+	}
+
+	<-done
+
+	return nil
+}
+
+// Entities returns the registered entities, in registration order.
+func (c *Container) Entities() []entity.RegisteredEntityInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Clone(c.entities)
+}
+
+// Routes returns the paths of the added routes.
+func (c *Container) Routes() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := make([]string, 0, len(c.routes))
+
+	for path := range c.routes {
+		result = append(result, path)
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// Route returns the handler added for the path, or nil.
+func (c *Container) Route(path string) http.HandlerFunc {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.routes[path]
+}
+
+// ReceiverCount returns the number of registered event receivers.
+func (c *Container) ReceiverCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.receivers)
+}
+
+// Events returns the broadcast events, oldest first.
+func (c *Container) Events() []BroadcastEvent {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Clone(c.events)
+}
+
+func (c *Container) AddRoute(path string, handlerFunc http.HandlerFunc) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.routes[path] = handlerFunc
+}
+
+// BroadcastEvent records the event, and delivers it to the receivers whose predicate accepts it.  Receivers run
+// on the calling goroutine, before BroadcastEvent returns.
+func (c *Container) BroadcastEvent(entityEventId string, event any) error {
+	c.mutex.Lock()
+	c.events = append(c.events, BroadcastEvent{SourceEntityId: entityEventId, Event: event})
+	receivers := make([]eventReceiver, 0, len(c.receivers))
+
+	for _, handle := range slices.Sorted(maps.Keys(c.receivers)) {
+		receivers = append(receivers, c.receivers[handle])
+	}
+
+	c.mutex.Unlock()
+
+	eventInfo := &events.EventInfo{SourceEntityId: entityEventId, Event: event}
+	var result error
+
+	for _, r := range receivers {
+		if r.predicate(eventInfo) {
+			result = errors.Join(result, r.receiver(eventInfo))
+		}
+	}
+
+	return result
+}
+
+func (c *Container) RenderList(w http.ResponseWriter, _ *http.Request, _ spi.RenderListOptions, _ []interface{}) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+func (c *Container) GetTemplate(templateInfo *spi.TemplateInfo) (spi.CompiledTemplate, error) {
+	return spi.CompiledTemplate{}, fmt.Errorf("template %s: templates are not supported", templateInfo.Name)
+}
+
+func (c *Container) GetEntityRenderer(entityType reflect.Type) (spi.EntityRenderer, error) {
+	c.mutex.Lock()
+	factory, ok := c.renderers[entityType]
+	c.mutex.Unlock()
+
+	if !ok {
+		return spi.EntityRenderer{}, fmt.Errorf("no renderer registered for %v", entityType)
+	}
+
+	return factory()
+}
+
+func (c *Container) RegisterEntityRenderer(entityType reflect.Type, renderFactory spi.EntityRendererFactory) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.renderers[entityType] = renderFactory
+}
+
+func (c *Container) EnableStaticContent(staticContentDir string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.staticContent = append(c.staticContent, staticContentDir)
+}
+
+func (c *Container) ProvideContentFS(_ fs.FS, prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.contentPrefixes = append(c.contentPrefixes, prefix)
+}
+
+// RegisterEntity registers the entity and returns its ID.  The unique data must not be registered already.
+func (c *Container) RegisterEntity(
+	uniqueData string,
+	entityType reflect.Type,
+	name string,
+	stubFactoryFn spi.EntityStubFactoryFunc) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	id := fmt.Sprintf("%s_%s", entityType.Name(), uniqueData)
+
+	if slices.ContainsFunc(c.entities, func(info entity.RegisteredEntityInfo) bool { return info.Id == id }) {
+		return "", fmt.Errorf("entity %s is already registered", id)
+	}
+
+	c.entities = append(c.entities, entity.RegisteredEntityInfo{
+		Id:            id,
+		EntityType:    entityType,
+		Name:          name,
+		StubFactoryFn: stubFactoryFn,
+	})
+
+	return id, nil
+}
+
+func (c *Container) DeregisterEntity(id string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	index := slices.IndexFunc(c.entities, func(info entity.RegisteredEntityInfo) bool { return info.Id == id })
+
+	if index < 0 {
+		return fmt.Errorf("entity %s is not registered", id)
+	}
+
+	c.entities = slices.Delete(c.entities, index, index+1)
+
+	return nil
+}
+
+func (c *Container) AssertEntityType(pmaasEntityId string, entityType reflect.Type) error {
+	info, err := c.entity(pmaasEntityId)
+
+	if err != nil {
+		return err
+	}
+
+	if info.EntityType != entityType {
+		return fmt.Errorf("entity %s is a %v, not a %v", pmaasEntityId, info.EntityType, entityType)
+	}
+
+	return nil
+}
+
+func (c *Container) GetEntities(
+	predicate func(info *entity.RegisteredEntityInfo) bool) ([]entity.RegisteredEntityInfo, error) {
+	result := make([]entity.RegisteredEntityInfo, 0)
+
+	for _, info := range c.Entities() {
+		if predicate(&info) {
+			result = append(result, info)
+		}
+	}
+
+	return result, nil
+}
+
+// InvokeOnEntity creates the entity's stub on the plugin's goroutine, then passes it to the function on the
+// server's goroutine, so the function can call the stub.
+func (c *Container) InvokeOnEntity(id string, function func(entity any)) error {
+	info, err := c.entity(id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.EnqueueOnPluginGoRoutine(func() {
+		stub, err := info.StubFactoryFn()
+
+		if err != nil {
+			fmt.Printf("fake container: Unable to create stub for %s: %v\n", id, err)
+			return
+		}
+
+		if err := c.EnqueueOnServerGoRoutine([]func(){func() { function(stub) }}); err != nil {
+			fmt.Printf("fake container: Unable to invoke function on %s: %v\n", id, err)
+		}
+	})
+}
+
+func (c *Container) entity(id string) (entity.RegisteredEntityInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	index := slices.IndexFunc(c.entities, func(info entity.RegisteredEntityInfo) bool { return info.Id == id })
+
+	if index < 0 {
+		return entity.RegisteredEntityInfo{}, fmt.Errorf("entity %s is not registered", id)
+	}
+
+	return c.entities[index], nil
+}
+
+func (c *Container) RegisterEventReceiver(predicate events.EventPredicate, receiver events.EventReceiver) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.receiverCounter++
+	c.receivers[c.receiverCounter] = eventReceiver{predicate: predicate, receiver: receiver}
+
+	return c.receiverCounter, nil
+}
+
+func (c *Container) DeregisterEventReceiver(receiverHandle int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.receivers[receiverHandle]; !ok {
+		return fmt.Errorf("event receiver %d is not registered", receiverHandle)
+	}
+
+	delete(c.receivers, receiverHandle)
+
+	return nil
+}
+
+func (c *Container) EnqueueOnPluginGoRoutine(f func()) error {
+	return c.enqueue(c.pluginQueue, f)
+}
+
+func (c *Container) EnqueueOnServerGoRoutine(invocations []func()) error {
+	return c.enqueue(c.serverQueue, func() {
+		for _, invocation := range invocations {
+			invocation()
+		}
+	})
+}
+
+func (c *Container) enqueue(queue chan func(), f func()) error {
+	c.queueMutex.RLock()
+	defer c.queueMutex.RUnlock()
+
+	if c.closed {
+		return errors.New("container is closed")
+	}
+
+	queue <- f
+
+	return nil
+}
+
+func (c *Container) ClosedCallbackChannel() chan func() {
+	result := make(chan func())
+	close(result)
+
+	return result
+}
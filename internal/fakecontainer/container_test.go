@@ -0,0 +1,77 @@
+package fakecontainer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/avanha/pmaas-spi/events"
+)
+
+type testEntity struct{}
+
+func TestBroadcastEvent_MatchingReceivers_ReceiveEvent(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	received := make([]any, 0)
+	_, _ = c.RegisterEventReceiver(
+		func(info *events.EventInfo) bool { return info.Event == "accepted" },
+		func(info *events.EventInfo) error {
+			received = append(received, info.Event)
+			return nil
+		})
+
+	_ = c.BroadcastEvent("entity", "rejected")
+	_ = c.BroadcastEvent("entity", "accepted")
+
+	if len(received) != 1 || received[0] != "accepted" {
+		t.Errorf("Expected [accepted], got %v", received)
+	}
+
+	if len(c.Events()) != 2 {
+		t.Errorf("Expected 2 recorded events, got %d", len(c.Events()))
+	}
+}
+
+func TestRegisterEntity_Duplicate_ReturnsError(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	entityType := reflect.TypeFor[testEntity]()
+	id, err := c.RegisterEntity("1", entityType, "one", nil)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if id != "testEntity_1" {
+		t.Errorf("Expected id testEntity_1, got %s", id)
+	}
+
+	if _, err := c.RegisterEntity("1", entityType, "one", nil); err == nil {
+		t.Errorf("Expected an error registering a duplicate entity")
+	}
+
+	if err := c.DeregisterEntity(id); err != nil {
+		t.Errorf("Expected no error deregistering, got %v", err)
+	}
+
+	if len(c.Entities()) != 0 {
+		t.Errorf("Expected no entities, got %v", c.Entities())
+	}
+}
+
+func TestEnqueueOnPluginGoRoutine_Closed_ReturnsError(t *testing.T) {
+	c := New()
+	ran := false
+
+	if err := c.RunOnPluginGoRoutine(func() { ran = true }); err != nil || !ran {
+		t.Errorf("Expected the function to run, got ran = %v, err = %v", ran, err)
+	}
+
+	c.Close()
+
+	if err := c.EnqueueOnPluginGoRoutine(func() {}); err == nil {
+		t.Errorf("Expected an error enqueuing on a closed container")
+	}
+}
@@ -0,0 +1,25 @@
+package fakecontainer
+
+import (
+	"sync/atomic"
+
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+// HistoryRepo is a tracking.TrackableHistoryRepo that returns a fixed sample, or a fixed error.
+type HistoryRepo struct {
+	Sample   tracking.DataSample
+	Err      error
+	requests atomic.Int32
+}
+
+func (r *HistoryRepo) GetMostRecentSample() tracking.HistoryResult[tracking.DataSample] {
+	r.requests.Add(1)
+
+	return tracking.HistoryResult[tracking.DataSample]{Result: r.Sample, Error: r.Err}
+}
+
+// RequestCount returns the number of times the most recent sample was requested.
+func (r *HistoryRepo) RequestCount() int {
+	return int(r.requests.Load())
+}
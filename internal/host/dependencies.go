@@ -0,0 +1,89 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ResolveDependencies links each host to the parents named in its configuration and returns the hosts ordered so
+// that every host comes after its parents.  Hosts that are part of, or depend on,
+// a dependency cycle lose all of their dependencies.  Both cases are reported via the returned error, but the
+// returned slice is always usable.  Unknown parents and hosts depending on themselves are ignored.
+func ResolveDependencies(hosts []*Host) ([]*Host, error) {
+	var errs []error
+	hostsByName := make(map[string]*Host, len(hosts))
+
+	for _, hostInstance := range hosts {
+		hostsByName[hostInstance.Name()] = hostInstance
+	}
+
+	for _, hostInstance := range hosts {
+		hostInstance.parents = nil
+
+		for _, parentName := range hostInstance.DependsOn() {
+			parent, ok := hostsByName[parentName]
+
+			if !ok {
+				errs = append(errs, fmt.Errorf("host %s depends on unknown host %s", hostInstance.Name(), parentName))
+				continue
+			}
+
+			if parent == hostInstance {
+				errs = append(errs, fmt.Errorf("host %s cannot depend on itself", hostInstance.Name()))
+				continue
+			}
+
+			hostInstance.AddParent(parent)
+		}
+	}
+
+	// Kahn's algorithm, repeatedly taking the hosts whose parents have all been placed, in configuration order
+	sorted := make([]*Host, 0, len(hosts))
+	placed := make(map[*Host]bool, len(hosts))
+
+	for len(sorted) < len(hosts) {
+		progress := false
+
+		for _, hostInstance := range hosts {
+			if placed[hostInstance] || !allPlaced(hostInstance.parents, placed) {
+				continue
+			}
+
+			sorted = append(sorted, hostInstance)
+			placed[hostInstance] = true
+			progress = true
+		}
+
+		if !progress {
+			break
+		}
+	}
+
+	if len(sorted) < len(hosts) {
+		remainingNames := make([]string, 0, len(hosts)-len(sorted))
+
+		for _, hostInstance := range hosts {
+			if !placed[hostInstance] {
+				hostInstance.parents = nil
+				sorted = append(sorted, hostInstance)
+				remainingNames = append(remainingNames, hostInstance.Name())
+			}
+		}
+
+		errs = append(errs, fmt.Errorf("dependency cycle involving hosts %s, ignoring their dependencies",
+			strings.Join(remainingNames, ", ")))
+	}
+
+	return sorted, errors.Join(errs...)
+}
+
+func allPlaced(hosts []*Host, placed map[*Host]bool) bool {
+	for _, hostInstance := range hosts {
+		if !placed[hostInstance] {
+			return false
+		}
+	}
+
+	return true
+}
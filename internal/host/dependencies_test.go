@@ -0,0 +1,242 @@
+package host
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+func newDependentHost(name string, dependsOn ...string) *Host {
+	return NewHost("Host_"+name, config.Host{
+		Name:                                name,
+		PingEnabled:                         true,
+		DependsOn:                           dependsOn,
+		SuppressEventsWhenParentUnreachable: true,
+	}, tracking.Config{}, nil)
+}
+
+func hostNames(hosts []*Host) []string {
+	names := make([]string, len(hosts))
+
+	for i, hostInstance := range hosts {
+		names[i] = hostInstance.Name()
+	}
+
+	return names
+}
+
+func TestResolveDependencies_ParentsSortedFirst(t *testing.T) {
+	hosts := []*Host{
+		newDependentHost("server", "switch"),
+		newDependentHost("switch", "router"),
+		newDependentHost("router"),
+	}
+
+	sorted, err := ResolveDependencies(hosts)
+
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"router", "switch", "server"}
+
+	if actual := hostNames(sorted); !slices.Equal(expected, actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	if len(hosts[0].Parents()) != 1 || hosts[0].Parents()[0] != hosts[1] {
+		t.Errorf("Expected server to have switch as its parent")
+	}
+}
+
+func TestResolveDependencies_UnknownParent_Ignored(t *testing.T) {
+	hosts := []*Host{
+		newDependentHost("server", "missing"),
+	}
+
+	sorted, err := ResolveDependencies(hosts)
+
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if len(sorted) != 1 || len(sorted[0].Parents()) != 0 {
+		t.Errorf("Expected the host without parents, got %v", hostNames(sorted))
+	}
+}
+
+func TestResolveDependencies_Cycle_DependenciesDropped(t *testing.T) {
+	hosts := []*Host{
+		newDependentHost("a", "b"),
+		newDependentHost("b", "a"),
+		newDependentHost("c"),
+	}
+
+	sorted, err := ResolveDependencies(hosts)
+
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	expected := []string{"c", "a", "b"}
+
+	if actual := hostNames(sorted); !slices.Equal(expected, actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	if len(hosts[0].Parents()) != 0 || len(hosts[1].Parents()) != 0 {
+		t.Errorf("Expected the cycle's dependencies to be dropped")
+	}
+}
+
+func TestUpdate_ParentUnreachable_ChildEventSuppressed(t *testing.T) {
+	router := newDependentHost("router")
+	server := newDependentHost("server", "router")
+	hosts, _ := ResolveDependencies([]*Host{router, server})
+	events := make([]any, 0)
+
+	for _, hostInstance := range hosts {
+		hostInstance.Update(pingSample(0, 0.0), &events)
+	}
+
+	events = events[:0]
+	router.Update(pingSample(1, 100.0), &events)
+	server.Update(pingSample(1, 100.0), &events)
+
+	if server.data.RootCauseHost != "router" {
+		t.Errorf("Expected root cause router, got \"%s\"", server.data.RootCauseHost)
+	}
+
+	reachabilityEvents := make([]netmonevents.HostReachabilityChangeEvent, 0)
+
+	for _, event := range events {
+		if reachabilityEvent, ok := event.(netmonevents.HostReachabilityChangeEvent); ok {
+			reachabilityEvents = append(reachabilityEvents, reachabilityEvent)
+		}
+	}
+
+	if len(reachabilityEvents) != 1 || reachabilityEvents[0].Name != "router" {
+		t.Errorf("Expected only the router's HostReachabilityChangeEvent, got %v", reachabilityEvents)
+	}
+
+	// Both recover, the server never broadcast its outage, so it doesn't broadcast the recovery either
+	events = events[:0]
+	router.Update(pingSample(2, 0.0), &events)
+	server.Update(pingSample(2, 0.0), &events)
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 1 {
+		t.Errorf("Expected 1 HostReachabilityChangeEvent, got %d", count)
+	}
+}
+
+func TestRefreshDependencyState_ParentRecoversChildStillDown_BroadcastsChildOutage(t *testing.T) {
+	router := newDependentHost("router")
+	server := newDependentHost("server", "router")
+	_, _ = ResolveDependencies([]*Host{router, server})
+	events := make([]any, 0)
+	router.Update(pingSample(0, 0.0), &events)
+	server.Update(pingSample(0, 0.0), &events)
+	router.Update(pingSample(1, 100.0), &events)
+	server.Update(pingSample(1, 100.0), &events)
+
+	events = events[:0]
+	router.Update(pingSample(2, 0.0), &events)
+	events = events[:0]
+	server.RefreshDependencyState(startTime.Add(2*time.Minute), &events)
+
+	if server.data.RootCauseHost != "" {
+		t.Errorf("Expected no root cause, got \"%s\"", server.data.RootCauseHost)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %v", events)
+	}
+
+	event := events[0].(netmonevents.HostReachabilityChangeEvent)
+
+	if event.OldValue != data.ReachabilityReachable || event.NewValue != data.ReachabilityUnreachable {
+		t.Errorf("Expected a change from reachable to unreachable, got %v", event)
+	}
+}
+
+func TestUpdate_ChildDownBeforeParent_ChildEventSuppressed(t *testing.T) {
+	router := newDependentHost("router")
+	server := newDependentHost("server", "router")
+	hosts, _ := ResolveDependencies([]*Host{router, server})
+	events := make([]any, 0)
+	sample := func(scan int, packetLoss float64) *common.HostData {
+		result := pingSample(scan, packetLoss)
+		result.ScanInterval = time.Minute
+
+		return result
+	}
+
+	for _, hostInstance := range hosts {
+		hostInstance.Update(sample(0, 0.0), &events)
+	}
+
+	// The server's scan runs first, and the router's outage is only detected by its own scan
+	events = events[:0]
+	server.Update(sample(1, 100.0), &events)
+	server.RefreshDependencyState(startTime.Add(time.Minute), &events)
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 0 {
+		t.Errorf("Expected the server's change to be held, got %d events", count)
+	}
+
+	routerScan := sample(1, 100.0)
+	routerScan.LastUpdateTime = routerScan.LastUpdateTime.Add(20 * time.Second)
+	router.Update(routerScan, &events)
+	server.RefreshDependencyState(routerScan.LastUpdateTime, &events)
+
+	if server.data.RootCauseHost != "router" {
+		t.Errorf("Expected root cause router, got \"%s\"", server.data.RootCauseHost)
+	}
+
+	// The server's next scan is after the hold, but the router is the root cause
+	server.Update(sample(2, 100.0), &events)
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 1 {
+		t.Errorf("Expected only the router's HostReachabilityChangeEvent, got %v", events)
+	}
+}
+
+func TestUpdate_ChildDownParentUp_ChildEventBroadcastAfterHold(t *testing.T) {
+	router := newDependentHost("router")
+	server := newDependentHost("server", "router")
+	hosts, _ := ResolveDependencies([]*Host{router, server})
+	events := make([]any, 0)
+
+	for _, hostInstance := range hosts {
+		hostInstance.Update(pingSample(0, 0.0), &events)
+	}
+
+	events = events[:0]
+	down := pingSample(1, 100.0)
+	down.ScanInterval = time.Minute
+	server.Update(down, &events)
+	server.RefreshDependencyState(startTime.Add(90*time.Second), &events)
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 0 {
+		t.Errorf("Expected the server's change to be held, got %d events", count)
+	}
+
+	events = events[:0]
+	server.RefreshDependencyState(startTime.Add(2*time.Minute), &events)
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %v", events)
+	}
+
+	event := events[0].(netmonevents.HostReachabilityChangeEvent)
+
+	if event.NewValue != data.ReachabilityUnreachable || event.RootCauseHost != "" {
+		t.Errorf("Expected the server's outage without a root cause, got %v", event)
+	}
+}
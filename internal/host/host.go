@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"iter"
 	"maps"
+	"reflect"
+	"slices"
+	"time"
 
 	"github.com/avanha/pmaas-plugin-netmon/config"
 	"github.com/avanha/pmaas-plugin-netmon/data"
 	"github.com/avanha/pmaas-plugin-netmon/entities"
 	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
 	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/eventtext"
+	"github.com/avanha/pmaas-plugin-netmon/internal/maintenance"
 	"github.com/avanha/pmaas-plugin-netmon/internal/netinterface"
 	spi "github.com/avanha/pmaas-spi"
 	spicommon "github.com/avanha/pmaas-spi/common"
@@ -29,6 +34,17 @@ type Host struct {
 	loadStateDone                     chan bool
 	data                              data.HostData
 	stub                              *stub
+	reachabilityChangeTimes           []time.Time
+	broadcastReachability             int
+	outages                           []data.Outage
+	parents                           []*Host
+	maintenanceSuppressEvents         bool
+	eventListeners                    []config.EventListener
+	eventListenersEventReceiverHandle int
+	journal                           []data.JournalEntry
+	clock                             common.Clock
+	scanRequests                      chan struct{}
+	reachabilityHoldUntil             time.Time
 }
 
 func NewHost(id string, config config.Host, trackingConfig tracking.Config, container spi.IPMAASContainer) *Host {
@@ -37,10 +53,14 @@ func NewHost(id string, config config.Host, trackingConfig tracking.Config, cont
 		config:         config,
 		trackingConfig: trackingConfig,
 		container:      container,
+		clock:          common.SystemClock,
+		scanRequests:   make(chan struct{}, 1),
 		netInterfaces:  make(map[string]*netinterface.NetInterface),
+		eventListeners: config.EventListeners(),
 		data: data.HostData{
 			Name:      config.Name,
 			IpAddress: config.IpAddress,
+			DependsOn: slices.Clone(config.DependsOn),
 		},
 	}
 }
@@ -57,6 +77,24 @@ func (h *Host) IpAddress() string {
 	return h.config.IpAddress
 }
 
+// RequestScan asks the host's monitoring task to scan the host now, rather than at its next tick.  Requests made
+// before the task gets to the pending one are coalesced into it.
+func (h *Host) RequestScan() error {
+	select {
+	case h.scanRequests <- struct{}{}:
+		fmt.Printf("Host [%s]: Scan requested\n", h.id)
+	default:
+		// A scan is already pending
+	}
+
+	return nil
+}
+
+// ScanRequests returns the channel RequestScan signals, for the host's monitoring task.
+func (h *Host) ScanRequests() <-chan struct{} {
+	return h.scanRequests
+}
+
 func (h *Host) NetInterfaces() iter.Seq2[string, *netinterface.NetInterface] {
 	return maps.All(h.netInterfaces)
 }
@@ -85,6 +123,30 @@ func (h *Host) SnmpEnabled() bool {
 	return h.config.SnmpEnabled
 }
 
+func (h *Host) CertificateCheckAddress() string {
+	return h.config.CertificateCheckAddress
+}
+
+func (h *Host) CollectorEnabled(name string) bool {
+	return h.config.CollectorEnabled(name)
+}
+
+func (h *Host) CollectorInterval(name string) time.Duration {
+	return h.config.CollectorInterval(name)
+}
+
+func (h *Host) ReachabilityDownThreshold() int {
+	return max(1, h.config.ReachabilityDownThreshold)
+}
+
+func (h *Host) ReachabilityUpThreshold() int {
+	return max(1, h.config.ReachabilityUpThreshold)
+}
+
+func (h *Host) FlapWindow() time.Duration {
+	return time.Duration(h.config.FlapWindowSeconds) * time.Second
+}
+
 func (h *Host) TrackingConfig() tracking.Config {
 	return h.trackingConfig
 }
@@ -141,21 +203,94 @@ func (h *Host) CloseStubIfPresent() {
 	}
 }
 
+// DependsOn returns the names of the configured parent hosts.
+func (h *Host) DependsOn() []string {
+	return h.config.DependsOn
+}
+
+// AddParent adds a host this host is reached through.  Parents must be added in the order they're configured.
+func (h *Host) AddParent(parent *Host) {
+	h.parents = append(h.parents, parent)
+}
+
+func (h *Host) Parents() []*Host {
+	return h.parents
+}
+
+func (h *Host) Groups() []string {
+	return h.config.Groups
+}
+
+// SetMaintenance sets the maintenance window in effect for the next update, or clears it if window is nil.
+func (h *Host) SetMaintenance(window *maintenance.ActiveWindow) {
+	if window == nil {
+		h.data.MaintenanceWindow = ""
+		h.data.MaintenanceEndTime = time.Time{}
+		h.data.HostAdHocMaintenance = false
+		h.maintenanceSuppressEvents = false
+		return
+	}
+
+	h.data.MaintenanceWindow = window.Name
+	h.data.MaintenanceEndTime = window.EndTime
+	h.data.HostAdHocMaintenance = window.HostAdHoc
+	h.maintenanceSuppressEvents = window.SuppressEvents
+}
+
+// RegisterConfiguredListeners registers the listeners added to the host's configuration.  They receive all the
+// host's events, including those of its interfaces.
+func (h *Host) RegisterConfiguredListeners(container spi.IPMAASContainer) {
+	handle, err := common.RegisterEventListeners(container, h.pmassEntityId, h.eventListeners, func(any) bool {
+		return true
+	})
+
+	if err != nil {
+		panic(fmt.Errorf("failed to register event receiver for host %s: %w", h.id, err))
+	}
+
+	h.eventListenersEventReceiverHandle = handle
+}
+
+func (h *Host) DeregisterConfiguredListeners(container spi.IPMAASContainer) {
+	if h.eventListenersEventReceiverHandle == 0 {
+		return
+	}
+
+	err := container.DeregisterEventReceiver(h.eventListenersEventReceiverHandle)
+
+	if err == nil {
+		h.eventListenersEventReceiverHandle = 0
+	} else {
+		fmt.Printf("Error deregistering receiver for host %s listeners: %s\n", h.id, err)
+	}
+}
+
 func (h *Host) AddNetInterface(key string, netInterface *netinterface.NetInterface) {
+	netInterface.SetClock(h.clock)
 	h.netInterfaces[key] = netInterface
 }
 
-func (h *Host) Update(newData *common.HostData, events *[]any) {
-	h.data.LastUpdateTime = newData.LastUpdateTime
+// Clock returns the clock used to time the host's scans.
+func (h *Host) Clock() common.Clock {
+	return h.clock
+}
 
-	hostEvent := netmonevents.HostEvent{
-		EntityEvent: spievents.EntityEvent{
-			Id:         h.pmassEntityId,
-			EntityType: entities.HostType,
-			Name:       h.Name(),
-		},
+// SetClock replaces the clock used to time the host's scans, including the scans of its interfaces.
+func (h *Host) SetClock(clock common.Clock) {
+	h.clock = clock
+
+	for _, netInterface := range h.netInterfaces {
+		netInterface.SetClock(clock)
 	}
+}
 
+func (h *Host) Update(newData *common.HostData, events *[]any) {
+	h.data.LastUpdateTime = newData.LastUpdateTime
+	h.data.SnmpInterval = newData.SnmpInterval
+	h.data.SnmpBackoff = newData.SnmpBackoff
+
+	firstEventIndex := len(*events)
+	hostEvent := h.HostEvent()
 	pingReachability := data.ReachabilityUnknown
 	snmpReachability := data.ReachabilityUnknown
 
@@ -163,25 +298,319 @@ func (h *Host) Update(newData *common.HostData, events *[]any) {
 		pingReachability = h.updatePingData(newData, &hostEvent, events)
 	}
 
-	if h.SnmpEnabled() {
+	// Collectors with a longer interval don't run with every scan
+	if newData.Collected(common.CollectorSnmp) {
 		snmpReachability = h.updateSnmpData(newData, &hostEvent, events)
 	}
 
-	newReachability := calcReachability(pingReachability, snmpReachability)
+	if newData.Collected(common.CollectorCertificate) {
+		h.data.CertificateStatus = newData.CertificateStatus
+
+		// Keep the last known expiry time if the check failed
+		if !newData.CertificateExpiryTime.IsZero() {
+			h.data.CertificateExpiryTime = newData.CertificateExpiryTime
+		}
+	}
+
+	observedReachability := calcReachability(pingReachability, snmpReachability)
+	oldReachability := h.data.Reachability
+	newReachability, changeStartTime, changed := h.debounceReachability(observedReachability, newData.LastUpdateTime)
+
+	if h.data.MonitoringStartTime.IsZero() {
+		h.data.MonitoringStartTime = newData.LastUpdateTime
+	}
+
+	// Changes during maintenance are expected, and shouldn't count towards flapping
+	if changed && oldReachability != data.ReachabilityUnknown && !h.data.InMaintenance() {
+		h.reachabilityChangeTimes = append(h.reachabilityChangeTimes, newData.LastUpdateTime)
+	}
+
+	wasFlapping := h.data.Flapping
+	h.updateFlapping(newData.LastUpdateTime)
 
-	if h.data.Reachability != newReachability {
+	if !wasFlapping && h.data.Flapping {
+		*events = append(*events, netmonevents.HostFlappingChangeEvent{
+			HostEvent: hostEvent,
+			OldValue:  false,
+			NewValue:  true,
+		})
+	}
+
+	if changed {
 		if newReachability == data.ReachabilityUnreachable {
 			h.data.UnreachableStartCount = h.data.UnreachableStartCount + 1
 			h.data.LastUnreachableStartTime = newData.LastUpdateTime
+
+			// The parents' scans detect a shared outage within a scan interval of this host's
+			if len(h.parents) > 0 && h.config.SuppressEventsWhenParentUnreachable {
+				h.reachabilityHoldUntil = newData.LastUpdateTime.Add(newData.ScanInterval)
+			}
 		}
 
-		*events = append(*events, netmonevents.HostReachabilityChangeEvent{
-			HostEvent: hostEvent,
-			OldValue:  h.data.Reachability,
-			NewValue:  newReachability,
-		})
 		h.data.Reachability = newReachability
 		h.data.LastReachabilityChangeTime = newData.LastUpdateTime
+	} else {
+		changeStartTime = newData.LastUpdateTime
+	}
+
+	h.updateOutageLog(changeStartTime)
+
+	h.data.RootCauseHost = h.calcRootCauseHost()
+
+	if wasFlapping && !h.data.Flapping {
+		*events = append(*events, netmonevents.HostFlappingChangeEvent{
+			HostEvent: hostEvent,
+			OldValue:  true,
+			NewValue:  false,
+		})
+	}
+
+	if h.eventsSuppressed() {
+		*events = (*events)[:firstEventIndex]
+		return
+	}
+
+	h.broadcastReachabilityChange(&hostEvent, newData.LastUpdateTime, events)
+}
+
+// RecordEvent adds a broadcast event to the host's event journal.  Events that report counter changes with every
+// scan are not recorded, as they would crowd out everything else.
+func (h *Host) RecordEvent(event any, eventTime time.Time) {
+	switch event.(type) {
+	case netmonevents.HostUptimeChangeEvent,
+		netmonevents.HostInterfaceTrafficStatsChangeEvent:
+		return
+	}
+
+	_, message := eventtext.Describe(event)
+	h.journal = append(h.journal, data.JournalEntry{
+		Time:             eventTime,
+		HostName:         h.Name(),
+		NetInterfaceName: eventtext.NetInterfaceName(event),
+		EventType:        reflect.TypeOf(event).Name(),
+		Message:          message,
+	})
+
+	if len(h.journal) > data.JournalMaxEntries {
+		h.journal = slices.Delete(h.journal, 0, len(h.journal)-data.JournalMaxEntries)
+	}
+
+	if h.config.PersistEventJournal {
+		h.data.EventJournal = data.EncodeJournal(h.journal)
+	}
+}
+
+// Journal returns the host's recent events, oldest first.
+func (h *Host) Journal() []data.JournalEntry {
+	return slices.Clone(h.journal)
+}
+
+// RefreshDependencyState re-evaluates whether this host is unreachable due to one of its parents.  Parents are
+// scanned independently, so a parent's outage may only be detected after this host's own scan.  The plugin calls
+// this for all hosts, in dependency order, after every update, with the time of the update.
+func (h *Host) RefreshDependencyState(now time.Time, events *[]any) {
+	if len(h.parents) == 0 {
+		return
+	}
+
+	rootCauseHost := h.calcRootCauseHost()
+
+	if rootCauseHost == h.data.RootCauseHost && h.data.Reachability == h.broadcastReachability {
+		return
+	}
+
+	h.data.RootCauseHost = rootCauseHost
+	hostEvent := h.HostEvent()
+	h.broadcastReachabilityChange(&hostEvent, now, events)
+}
+
+// broadcastReachabilityChange adds a HostReachabilityChangeEvent if the reachability differs from the one last
+// broadcast, unless events are currently suppressed.  While suppressed, intermediate changes are not broadcast, and
+// a single consolidated change is broadcast once the suppression ends.  A host that goes down while its parents are
+// up holds its change for a scan interval, in case a parent's scan finds the parent is the root cause.
+func (h *Host) broadcastReachabilityChange(hostEvent *netmonevents.HostEvent, now time.Time, events *[]any) {
+	if h.data.Reachability == h.broadcastReachability {
+		return
+	}
+
+	if h.data.Flapping || h.eventsSuppressed() {
+		return
+	}
+
+	if h.data.RootCauseHost != "" && h.config.SuppressEventsWhenParentUnreachable {
+		return
+	}
+
+	if h.data.Reachability == data.ReachabilityUnreachable && now.Before(h.reachabilityHoldUntil) {
+		return
+	}
+
+	*events = append(*events, netmonevents.HostReachabilityChangeEvent{
+		HostEvent:     *hostEvent,
+		OldValue:      h.broadcastReachability,
+		NewValue:      h.data.Reachability,
+		RootCauseHost: h.data.RootCauseHost,
+	})
+	h.broadcastReachability = h.data.Reachability
+}
+
+// calcRootCauseHost returns the name of the host that is the root cause of this host being unreachable.  That is
+// the case when all of this host's parents are unreachable.  The root cause is the first parent's root cause, or
+// the first parent itself, if it's unreachable in its own right.
+func (h *Host) calcRootCauseHost() string {
+	if h.data.Reachability != data.ReachabilityUnreachable || len(h.parents) == 0 {
+		return ""
+	}
+
+	for _, parent := range h.parents {
+		if parent.data.Reachability != data.ReachabilityUnreachable {
+			return ""
+		}
+	}
+
+	if h.parents[0].data.RootCauseHost != "" {
+		return h.parents[0].data.RootCauseHost
+	}
+
+	return h.parents[0].Name()
+}
+
+// eventsSuppressed returns true if the host is in a maintenance window that suppresses events.
+func (h *Host) eventsSuppressed() bool {
+	return h.data.InMaintenance() && h.maintenanceSuppressEvents
+}
+
+func (h *Host) HostEvent() netmonevents.HostEvent {
+	return netmonevents.HostEvent{
+		EntityEvent: spievents.EntityEvent{
+			Id:         h.pmassEntityId,
+			EntityType: entities.HostType,
+			Name:       h.Name(),
+		},
+		MaintenanceWindow: h.data.MaintenanceWindow,
+	}
+}
+
+// debounceReachability applies the configured up and down thresholds to the reachability observed by the
+// latest scan.  It returns the reachability the host should have after this scan, the time of the first scan
+// that observed it, and whether that differs from the current value.  Scans that could not determine
+// reachability are ignored.
+func (h *Host) debounceReachability(observed int, now time.Time) (int, time.Time, bool) {
+	current := h.data.Reachability
+
+	if observed == data.ReachabilityUnknown {
+		// No reachability collector ran in the scan, because none were due, so a pending change still stands
+		return current, time.Time{}, false
+	}
+
+	if observed == current {
+		h.clearPendingReachability()
+		return current, time.Time{}, false
+	}
+
+	if current == data.ReachabilityUnknown {
+		// Nothing to debounce against, accept the first known state immediately
+		h.clearPendingReachability()
+		return observed, now, true
+	}
+
+	if isUp(observed) == isUp(h.data.PendingReachability) && h.data.PendingReachabilityCount > 0 {
+		h.data.PendingReachabilityCount = h.data.PendingReachabilityCount + 1
+	} else {
+		h.data.PendingReachabilityCount = 1
+		h.data.PendingReachabilityStartTime = now
+	}
+
+	h.data.PendingReachability = observed
+
+	threshold := h.ReachabilityUpThreshold()
+
+	if observed == data.ReachabilityUnreachable {
+		threshold = h.ReachabilityDownThreshold()
+	}
+
+	if h.data.PendingReachabilityCount < threshold {
+		return current, time.Time{}, false
+	}
+
+	startTime := h.data.PendingReachabilityStartTime
+	h.clearPendingReachability()
+
+	return observed, startTime, true
+}
+
+func (h *Host) clearPendingReachability() {
+	h.data.PendingReachability = data.ReachabilityUnknown
+	h.data.PendingReachabilityCount = 0
+	h.data.PendingReachabilityStartTime = time.Time{}
+}
+
+// updateOutageLog records the start or end of an outage.  An outage caused by a reachability change is dated from
+// the first scan that observed the change, rather than the scan that confirmed it.  Time spent in maintenance is
+// not counted, so an ongoing outage ends when maintenance starts, and a new one starts when maintenance ends
+// with the host still unreachable.
+func (h *Host) updateOutageLog(changeStartTime time.Time) {
+	down := h.data.Reachability == data.ReachabilityUnreachable && !h.data.InMaintenance()
+	ongoing := len(h.outages) > 0 && h.outages[len(h.outages)-1].Ongoing()
+
+	if down && !ongoing {
+		h.outages = append(h.outages, data.Outage{StartTime: changeStartTime})
+	} else if !down && ongoing {
+		outage := &h.outages[len(h.outages)-1]
+		outage.EndTime = changeStartTime
+		h.data.TotalDowntime = h.data.TotalDowntime + outage.EndTime.Sub(outage.StartTime)
+	} else {
+		return
+	}
+
+	retentionStart := changeStartTime.Add(-data.OutageLogRetention)
+	h.outages = slices.DeleteFunc(h.outages, func(outage data.Outage) bool {
+		return !outage.Ongoing() && outage.EndTime.Before(retentionStart)
+	})
+	h.data.OutageLog = data.EncodeOutageLog(h.outages)
+}
+
+func isUp(reachability int) bool {
+	return reachability == data.ReachabilityReachable || reachability == data.ReachabilityDegraded
+}
+
+// updateFlapping prunes reachability changes that have fallen out of the flap detection window and
+// updates the flapping state.  The host starts flapping when the number of changes within the window
+// reaches the configured threshold, and stops once it drops to half the threshold.
+func (h *Host) updateFlapping(now time.Time) {
+	window := h.FlapWindow()
+
+	if window <= 0 || h.config.FlapThreshold <= 0 {
+		h.reachabilityChangeTimes = nil
+		h.setFlapping(false, now)
+		return
+	}
+
+	windowStart := now.Add(-window)
+	h.reachabilityChangeTimes = slices.DeleteFunc(h.reachabilityChangeTimes, func(changeTime time.Time) bool {
+		return changeTime.Before(windowStart)
+	})
+	changeCount := len(h.reachabilityChangeTimes)
+
+	if h.data.Flapping {
+		h.setFlapping(changeCount > h.config.FlapThreshold/2, now)
+	} else {
+		h.setFlapping(changeCount >= h.config.FlapThreshold, now)
+	}
+}
+
+func (h *Host) setFlapping(flapping bool, now time.Time) {
+	if h.data.Flapping == flapping {
+		return
+	}
+
+	h.data.Flapping = flapping
+
+	if flapping {
+		h.data.FlappingStartCount = h.data.FlappingStartCount + 1
+		h.data.LastFlappingStartTime = now
+	} else {
+		h.data.LastFlappingEndTime = now
 	}
 }
 
@@ -218,6 +647,10 @@ func (h *Host) updatePingData(newData *common.HostData, hostEvent *netmonevents.
 		return data.ReachabilityUnreachable
 	}
 
+	if h.config.DegradedPacketLossPercent > 0.0 && newData.PingPacketLoss >= h.config.DegradedPacketLossPercent {
+		return data.ReachabilityDegraded
+	}
+
 	return data.ReachabilityReachable
 }
 
@@ -245,6 +678,11 @@ func (h *Host) updateSnmpData(newData *common.HostData, hostEvent *netmonevents.
 }
 
 func calcReachability(pingReachability, snmpReachability int) int {
+	if pingReachability == data.ReachabilityDegraded {
+		// The host responds, but is losing packets
+		return data.ReachabilityDegraded
+	}
+
 	if pingReachability == data.ReachabilityReachable || snmpReachability == data.ReachabilityReachable {
 		return data.ReachabilityReachable
 	}
@@ -294,6 +732,25 @@ var interfaceIdStrategies = []interfaceIdStrategy{
 }
 
 func (h *Host) findInterface(ifData *common.IfData) *netinterface.NetInterface {
+	iface, key := h.matchInterface(ifData)
+
+	if iface != nil {
+		fmt.Printf("Host [%s]: findInterface %s succeeded\n", h.config.Name, key)
+	}
+
+	return iface
+}
+
+// MonitorsInterface returns true if the interface is one of the host's configured interfaces.  Interfaces are only
+// added while the plugin is being configured, so it's safe to call from the monitoring goroutines.
+func (h *Host) MonitorsInterface(ifData *common.IfData) bool {
+	iface, _ := h.matchInterface(ifData)
+
+	return iface != nil
+}
+
+// matchInterface returns the configured interface the interface data belongs to, and the key it was found by.
+func (h *Host) matchInterface(ifData *common.IfData) (*netinterface.NetInterface, string) {
 	for _, strategy := range interfaceIdStrategies {
 		_, key := strategy(ifData)
 
@@ -307,12 +764,11 @@ func (h *Host) findInterface(ifData *common.IfData) *netinterface.NetInterface {
 		if iface == nil {
 			//fmt.Printf("%T findInterface by %s failed\n", p, key)
 		} else {
-			fmt.Printf("Host [%s]: findInterface %s succeeded\n", h.config.Name, key)
-			return iface
+			return iface, key
 		}
 	}
 
-	return nil
+	return nil, ""
 }
 
 func (h *Host) findInterfaceByKey(key string) *netinterface.NetInterface {
@@ -347,6 +803,31 @@ func (h *Host) loadStateFromHistory(historyRepo tracking.TrackableHistoryRepo) {
 
 func (h *Host) initFromSample(hostData data.HostData) {
 	h.data = hostData
+	h.data.DependsOn = slices.Clone(h.config.DependsOn)
+	h.broadcastReachability = hostData.Reachability
+	outages, err := data.DecodeOutageLog(hostData.OutageLog)
+
+	if err != nil {
+		fmt.Printf("Host [%s]: Unable to decode outage log: %v\n", h.id, err)
+		outages = []data.Outage{}
+	}
+
+	h.outages = outages
+
+	if !h.config.PersistEventJournal {
+		h.data.EventJournal = ""
+		return
+	}
+
+	journal, err := data.DecodeJournal(hostData.EventJournal)
+
+	if err != nil {
+		fmt.Printf("Host [%s]: Unable to decode event journal: %v\n", h.id, err)
+		journal = []data.JournalEntry{}
+	}
+
+	// Keep any events recorded before the sample was loaded
+	h.journal = append(journal, h.journal...)
 }
 
 func (h *Host) signalLoadDone(result bool) {
@@ -366,5 +847,5 @@ func (h *Host) WaitForInitialLoad() {
 
 	result := <-initLoadDone
 
-	fmt.Printf("Initial load completed, success = %s", result)
+	fmt.Printf("Initial load completed, success = %v\n", result)
 }
@@ -0,0 +1,440 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/maintenance"
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+var startTime = time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestHost(downThreshold int, upThreshold int, flapWindowSeconds int, flapThreshold int) *Host {
+	return NewHost("Host_1", config.Host{
+		Name:                      "test",
+		IpAddress:                 "127.0.0.1",
+		PingEnabled:               true,
+		ReachabilityDownThreshold: downThreshold,
+		ReachabilityUpThreshold:   upThreshold,
+		DegradedPacketLossPercent: 25.0,
+		FlapWindowSeconds:         flapWindowSeconds,
+		FlapThreshold:             flapThreshold,
+	}, tracking.Config{}, nil)
+}
+
+func pingSample(scan int, packetLoss float64) *common.HostData {
+	return &common.HostData{
+		LastUpdateTime:  startTime.Add(time.Duration(scan) * time.Minute),
+		PingStatus:      "OK",
+		PingPacketsSent: 4,
+		PingPacketLoss:  packetLoss,
+	}
+}
+
+func countEvents[T any](events []any) int {
+	count := 0
+
+	for _, event := range events {
+		if _, ok := event.(T); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestUpdate_FirstSample_SetsReachabilityImmediately(t *testing.T) {
+	h := newTestHost(3, 2, 0, 0)
+	events := make([]any, 0)
+
+	h.Update(pingSample(0, 0.0), &events)
+
+	if h.data.Reachability != data.ReachabilityReachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityReachable, h.data.Reachability)
+	}
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](events) != 1 {
+		t.Errorf("Expected 1 HostReachabilityChangeEvent, got %v", events)
+	}
+}
+
+func TestUpdate_SingleFailure_DoesNotChangeReachability(t *testing.T) {
+	h := newTestHost(3, 2, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+	events = events[:0]
+
+	h.Update(pingSample(1, 100.0), &events)
+
+	if h.data.Reachability != data.ReachabilityReachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityReachable, h.data.Reachability)
+	}
+
+	if h.data.PendingReachability != data.ReachabilityUnreachable || h.data.PendingReachabilityCount != 1 {
+		t.Errorf("Expected pending unreachable count 1, got %d count %d",
+			h.data.PendingReachability, h.data.PendingReachabilityCount)
+	}
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](events) != 0 {
+		t.Errorf("Expected no HostReachabilityChangeEvent, got %v", events)
+	}
+
+	h.Update(pingSample(2, 0.0), &events)
+
+	if h.data.PendingReachabilityCount != 0 {
+		t.Errorf("Expected pending count to reset, got %d", h.data.PendingReachabilityCount)
+	}
+}
+
+func TestUpdate_ConsecutiveFailuresReachThreshold_ChangesReachability(t *testing.T) {
+	h := newTestHost(3, 2, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+	events = events[:0]
+
+	for i := 1; i <= 3; i++ {
+		h.Update(pingSample(i, 100.0), &events)
+	}
+
+	if h.data.Reachability != data.ReachabilityUnreachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityUnreachable, h.data.Reachability)
+	}
+
+	if h.data.UnreachableStartCount != 1 {
+		t.Errorf("Expected UnreachableStartCount 1, got %d", h.data.UnreachableStartCount)
+	}
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](events) != 1 {
+		t.Errorf("Expected 1 HostReachabilityChangeEvent, got %v", events)
+	}
+
+	h.Update(pingSample(4, 0.0), &events)
+
+	if h.data.Reachability != data.ReachabilityUnreachable {
+		t.Errorf("Expected host to remain unreachable after one success, got %d", h.data.Reachability)
+	}
+
+	h.Update(pingSample(5, 0.0), &events)
+
+	if h.data.Reachability != data.ReachabilityReachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityReachable, h.data.Reachability)
+	}
+}
+
+func TestUpdate_PartialPacketLoss_Degraded(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+
+	h.Update(pingSample(1, 50.0), &events)
+
+	if h.data.Reachability != data.ReachabilityDegraded {
+		t.Errorf("Expected %d, got %d", data.ReachabilityDegraded, h.data.Reachability)
+	}
+
+	h.Update(pingSample(2, 10.0), &events)
+
+	if h.data.Reachability != data.ReachabilityReachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityReachable, h.data.Reachability)
+	}
+}
+
+func TestUpdate_FrequentChanges_FlappingSuppressesEvents(t *testing.T) {
+	h := newTestHost(1, 1, 600, 4)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+	events = events[:0]
+
+	// Alternate every minute, four changes within the ten-minute window start flapping
+	for i := 1; i <= 8; i++ {
+		loss := 0.0
+
+		if i%2 == 1 {
+			loss = 100.0
+		}
+
+		h.Update(pingSample(i, loss), &events)
+	}
+
+	if !h.data.Flapping {
+		t.Fatalf("Expected host to be flapping")
+	}
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 3 {
+		t.Errorf("Expected 3 HostReachabilityChangeEvents before flapping, got %d", count)
+	}
+
+	if count := countEvents[netmonevents.HostFlappingChangeEvent](events); count != 1 {
+		t.Errorf("Expected 1 HostFlappingChangeEvent, got %d", count)
+	}
+
+	events = events[:0]
+
+	// Stay up long enough for the changes to age out of the window
+	for i := 9; i <= 20; i++ {
+		h.Update(pingSample(i, 0.0), &events)
+	}
+
+	if h.data.Flapping {
+		t.Errorf("Expected host to stop flapping")
+	}
+
+	if count := countEvents[netmonevents.HostFlappingChangeEvent](events); count != 1 {
+		t.Errorf("Expected 1 HostFlappingChangeEvent, got %d", count)
+	}
+
+	// The last broadcast change was to unreachable, so a consolidated change back to reachable is expected
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 1 {
+		t.Errorf("Expected 1 HostReachabilityChangeEvent, got %d", count)
+	}
+
+	if h.data.FlappingStartCount != 1 {
+		t.Errorf("Expected FlappingStartCount 1, got %d", h.data.FlappingStartCount)
+	}
+}
+
+func TestUpdate_Outage_RecordedFromFirstFailedScan(t *testing.T) {
+	h := newTestHost(3, 2, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+
+	for i := 1; i <= 3; i++ {
+		h.Update(pingSample(i, 100.0), &events)
+	}
+
+	for i := 4; i <= 5; i++ {
+		h.Update(pingSample(i, 0.0), &events)
+	}
+
+	outages := h.data.GetOutages()
+
+	if len(outages) != 1 {
+		t.Fatalf("Expected 1 outage, got %v", outages)
+	}
+
+	if !outages[0].StartTime.Equal(startTime.Add(time.Minute)) {
+		t.Errorf("Expected outage start %v, got %v", startTime.Add(time.Minute), outages[0].StartTime)
+	}
+
+	if !outages[0].EndTime.Equal(startTime.Add(4 * time.Minute)) {
+		t.Errorf("Expected outage end %v, got %v", startTime.Add(4*time.Minute), outages[0].EndTime)
+	}
+
+	if h.data.TotalDowntime != 3*time.Minute {
+		t.Errorf("Expected total downtime %v, got %v", 3*time.Minute, h.data.TotalDowntime)
+	}
+
+	if !h.data.MonitoringStartTime.Equal(startTime) {
+		t.Errorf("Expected monitoring start %v, got %v", startTime, h.data.MonitoringStartTime)
+	}
+}
+
+func TestUpdate_OutageDuringMaintenance_SuppressedAndNotCounted(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+	events = events[:0]
+
+	h.SetMaintenance(&maintenance.ActiveWindow{
+		Name:           "reboot",
+		EndTime:        startTime.Add(3 * time.Minute),
+		SuppressEvents: true,
+	})
+
+	for i := 1; i <= 2; i++ {
+		h.Update(pingSample(i, 100.0), &events)
+	}
+
+	if h.data.Reachability != data.ReachabilityUnreachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityUnreachable, h.data.Reachability)
+	}
+
+	if len(events) != 0 {
+		t.Errorf("Expected no events during maintenance, got %v", events)
+	}
+
+	// Still down after the window ends, so the outage starts now and the change is broadcast
+	h.SetMaintenance(nil)
+	h.Update(pingSample(3, 100.0), &events)
+	h.Update(pingSample(4, 0.0), &events)
+
+	if count := countEvents[netmonevents.HostReachabilityChangeEvent](events); count != 2 {
+		t.Errorf("Expected 2 HostReachabilityChangeEvents, got %d", count)
+	}
+
+	if h.data.TotalDowntime != time.Minute {
+		t.Errorf("Expected total downtime %v, got %v", time.Minute, h.data.TotalDowntime)
+	}
+}
+
+func TestUpdate_MaintenanceWithoutSuppression_TagsEvents(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+	events := make([]any, 0)
+	h.Update(pingSample(0, 0.0), &events)
+	events = events[:0]
+
+	h.SetMaintenance(&maintenance.ActiveWindow{Name: "reboot", EndTime: startTime.Add(time.Hour)})
+	h.Update(pingSample(1, 100.0), &events)
+
+	for _, event := range events {
+		if changeEvent, ok := event.(netmonevents.HostReachabilityChangeEvent); ok {
+			if changeEvent.MaintenanceWindow != "reboot" {
+				t.Errorf("Expected event tagged with reboot, got \"%s\"", changeEvent.MaintenanceWindow)
+			}
+
+			return
+		}
+	}
+
+	t.Errorf("Expected a HostReachabilityChangeEvent, got %v", events)
+}
+
+func TestUpdate_SnmpNotCollected_KeepsSnmpData(t *testing.T) {
+	h := NewHost("Host_1", config.Host{Name: "test", IpAddress: "127.0.0.1", SnmpEnabled: true},
+		tracking.Config{}, nil)
+	events := make([]any, 0)
+	h.Update(&common.HostData{
+		LastUpdateTime:   startTime,
+		SnmpSuccess:      true,
+		SnmpStatus:       "OK",
+		UptimeSeconds:    1000,
+		CollectorResults: map[string]common.CollectorResult{common.CollectorSnmp: {StartTime: startTime}},
+	}, &events)
+
+	// The SNMP collector wasn't due in the next scan
+	h.Update(&common.HostData{LastUpdateTime: startTime.Add(time.Minute)}, &events)
+
+	if h.data.Reachability != data.ReachabilityReachable {
+		t.Errorf("Expected %d, got %d", data.ReachabilityReachable, h.data.Reachability)
+	}
+
+	if h.data.SnmpStatus != "OK" || h.data.UptimeSeconds != 1000 {
+		t.Errorf("Expected the previous SNMP data, got %q, %d", h.data.SnmpStatus, h.data.UptimeSeconds)
+	}
+}
+
+func TestUpdate_SnmpFailingEveryOtherScan_ReachesDownThreshold(t *testing.T) {
+	h := NewHost("Host_1", config.Host{
+		Name:                      "test",
+		IpAddress:                 "127.0.0.1",
+		SnmpEnabled:               true,
+		ReachabilityDownThreshold: 2,
+		ReachabilityUpThreshold:   1,
+	}, tracking.Config{}, nil)
+	events := make([]any, 0)
+	snmpSample := func(scan int, success bool) *common.HostData {
+		scanTime := startTime.Add(time.Duration(scan) * time.Minute)
+
+		return &common.HostData{
+			LastUpdateTime:   scanTime,
+			SnmpSuccess:      success,
+			CollectorResults: map[string]common.CollectorResult{common.CollectorSnmp: {StartTime: scanTime}},
+		}
+	}
+	h.Update(snmpSample(0, true), &events)
+
+	// The SNMP collector's interval is twice the scan interval, so it only runs in every other scan
+	for scan := 1; scan <= 4; scan++ {
+		if scan%2 == 0 {
+			h.Update(snmpSample(scan, false), &events)
+		} else {
+			h.Update(&common.HostData{LastUpdateTime: startTime.Add(time.Duration(scan) * time.Minute)}, &events)
+		}
+	}
+
+	if h.data.Reachability != data.ReachabilityUnreachable {
+		t.Errorf("Expected %d, got %d (pending count %d)",
+			data.ReachabilityUnreachable, h.data.Reachability, h.data.PendingReachabilityCount)
+	}
+
+	if !h.data.LastReachabilityChangeTime.Equal(startTime.Add(4 * time.Minute)) {
+		t.Errorf("Expected the change at the second failure, got %v", h.data.LastReachabilityChangeTime)
+	}
+}
+
+func TestUpdate_SnmpBackoff_ReportedInHostData(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+	events := make([]any, 0)
+	sample := pingSample(0, 100.0)
+	sample.SnmpInterval = 4 * time.Minute
+	sample.SnmpBackoff = true
+
+	h.Update(sample, &events)
+
+	if !h.data.SnmpBackoff || h.data.SnmpInterval != 4*time.Minute {
+		t.Errorf("Expected an SNMP backoff of 4m, got %v, %v", h.data.SnmpBackoff, h.data.SnmpInterval)
+	}
+}
+
+func TestRecordEvent_ReachabilityChange_RecordedAndPersisted(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+	h.config.PersistEventJournal = true
+	events := make([]any, 0)
+
+	h.Update(pingSample(0, 0.0), &events)
+	h.Update(pingSample(1, 100.0), &events)
+
+	for _, event := range events {
+		h.RecordEvent(event, startTime)
+	}
+
+	journal := h.Journal()
+	count := 0
+
+	for _, entry := range journal {
+		if entry.EventType == "HostReachabilityChangeEvent" {
+			count++
+		}
+	}
+
+	if count == 0 {
+		t.Fatalf("Expected the reachability changes to be recorded, got %v", journal)
+	}
+
+	if journal[0].HostName != "test" {
+		t.Errorf("Expected test, got %s", journal[0].HostName)
+	}
+
+	decoded, err := data.DecodeJournal(h.data.EventJournal)
+
+	if err != nil || len(decoded) != len(journal) {
+		t.Errorf("Expected %d persisted entries, got %d (%v)", len(journal), len(decoded), err)
+	}
+}
+
+func TestRecordEvent_ManyEvents_KeepsMostRecent(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+
+	for i := 0; i < data.JournalMaxEntries+10; i++ {
+		h.RecordEvent(netmonevents.HostReachabilityChangeEvent{}, startTime.Add(time.Duration(i)*time.Minute))
+	}
+
+	journal := h.Journal()
+
+	if len(journal) != data.JournalMaxEntries {
+		t.Fatalf("Expected %d entries, got %d", data.JournalMaxEntries, len(journal))
+	}
+
+	if !journal[0].Time.Equal(startTime.Add(10 * time.Minute)) {
+		t.Errorf("Expected the oldest entries to be dropped, got %v", journal[0].Time)
+	}
+
+	if h.data.EventJournal != "" {
+		t.Errorf("Expected the journal not to be persisted")
+	}
+}
+
+func TestRecordEvent_StatsChange_NotRecorded(t *testing.T) {
+	h := newTestHost(1, 1, 0, 0)
+
+	h.RecordEvent(netmonevents.HostInterfaceTrafficStatsChangeEvent{}, startTime)
+
+	if len(h.Journal()) != 0 {
+		t.Errorf("Expected no entries, got %d", len(h.Journal()))
+	}
+}
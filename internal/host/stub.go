@@ -70,3 +70,9 @@ func (s *stub) SetHistoryRepo(trackingHistoryRepo tracking.TrackableHistoryRepo)
 		s.entityWrapperReference.Load(),
 		func(target entities.Host) error { return target.SetHistoryRepo(trackingHistoryRepo) })
 }
+
+func (s *stub) RequestScan() error {
+	return common.ThreadSafeEntityWrapperExecValueFunc(
+		s.entityWrapperReference.Load(),
+		func(target entities.Host) error { return target.RequestScan() })
+}
@@ -0,0 +1,8 @@
+package http
+
+import "github.com/avanha/pmaas-plugin-netmon/data"
+
+// activeAlertsPanel is rendered at the top of the list page.
+type activeAlertsPanel struct {
+	Alerts []data.Alert
+}
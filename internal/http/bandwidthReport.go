@@ -0,0 +1,54 @@
+package http
+
+import (
+	"sort"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+// bandwidthReport is the JSON representation of an interface's 95th percentile rates, in bits per second.
+type bandwidthReport struct {
+	Host                string    `json:"host"`
+	Interface           string    `json:"interface"`
+	PeriodStart         time.Time `json:"periodStart"`
+	Samples             int       `json:"samples"`
+	In95th              uint64    `json:"in95th"`
+	Out95th             uint64    `json:"out95th"`
+	PreviousPeriodStart time.Time `json:"previousPeriodStart,omitzero"`
+	PreviousSamples     int       `json:"previousSamples,omitempty"`
+	PreviousIn95th      uint64    `json:"previousIn95th,omitempty"`
+	PreviousOut95th     uint64    `json:"previousOut95th,omitempty"`
+}
+
+func newBandwidthReports(hosts []data.HostData) []bandwidthReport {
+	result := make([]bandwidthReport, 0)
+
+	for _, hostData := range hosts {
+		for _, interfaceData := range hostData.NetInterfaceDataList {
+			percentiles := &interfaceData.Percentiles
+			result = append(result, bandwidthReport{
+				Host:                hostData.Name,
+				Interface:           interfaceData.Name,
+				PeriodStart:         percentiles.In.PeriodStart,
+				Samples:             percentiles.In.Samples,
+				In95th:              percentiles.In.Value() * 8,
+				Out95th:             percentiles.Out.Value() * 8,
+				PreviousPeriodStart: percentiles.In.PreviousPeriodStart,
+				PreviousSamples:     percentiles.In.PreviousSamples,
+				PreviousIn95th:      percentiles.In.PreviousValue * 8,
+				PreviousOut95th:     percentiles.Out.PreviousValue * 8,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Host != result[j].Host {
+			return result[i].Host < result[j].Host
+		}
+
+		return result[i].Interface < result[j].Interface
+	})
+
+	return result
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"slices"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+type dependencyTreeNode struct {
+	Name          string
+	IpAddress     string
+	Reachability  int
+	RootCauseHost string
+	Children      []*dependencyTreeNode
+}
+
+type dependencyTree struct {
+	Roots []*dependencyTreeNode
+}
+
+// newDependencyTree arranges the hosts into a forest based on their configured dependencies.  Hosts without
+// known parents are roots.  A host with several parents appears under each of them.  Dependency cycles are
+// broken by not descending into a host that's already on the current path.  Hosts that only depend on each other
+// in a cycle aren't reachable from any root, so the first of them, in configuration order, is listed as a root.
+func newDependencyTree(hosts []data.HostData) *dependencyTree {
+	hostsByName := make(map[string]*data.HostData, len(hosts))
+	childrenByName := make(map[string][]*data.HostData, len(hosts))
+
+	for i := range hosts {
+		hostsByName[hosts[i].Name] = &hosts[i]
+	}
+
+	tree := &dependencyTree{
+		Roots: make([]*dependencyTreeNode, 0),
+	}
+	var roots []*data.HostData
+
+	for i := range hosts {
+		hasKnownParent := false
+
+		for _, parentName := range hosts[i].DependsOn {
+			if _, ok := hostsByName[parentName]; ok && parentName != hosts[i].Name {
+				childrenByName[parentName] = append(childrenByName[parentName], &hosts[i])
+				hasKnownParent = true
+			}
+		}
+
+		if !hasKnownParent {
+			roots = append(roots, &hosts[i])
+		}
+	}
+
+	rendered := make(map[string]bool, len(hosts))
+
+	for _, root := range roots {
+		tree.Roots = append(tree.Roots, buildDependencyTreeNode(root, childrenByName, []string{}, rendered))
+	}
+
+	for i := range hosts {
+		if !rendered[hosts[i].Name] {
+			tree.Roots = append(tree.Roots, buildDependencyTreeNode(&hosts[i], childrenByName, []string{}, rendered))
+		}
+	}
+
+	return tree
+}
+
+func buildDependencyTreeNode(
+	hostData *data.HostData,
+	childrenByName map[string][]*data.HostData,
+	path []string,
+	rendered map[string]bool) *dependencyTreeNode {
+	node := &dependencyTreeNode{
+		Name:          hostData.Name,
+		IpAddress:     hostData.IpAddress,
+		Reachability:  hostData.Reachability,
+		RootCauseHost: hostData.RootCauseHost,
+		Children:      make([]*dependencyTreeNode, 0),
+	}
+	path = append(path, hostData.Name)
+	rendered[hostData.Name] = true
+
+	for _, child := range childrenByName[hostData.Name] {
+		if slices.Contains(path, child.Name) {
+			continue
+		}
+
+		node.Children = append(node.Children, buildDependencyTreeNode(child, childrenByName, path, rendered))
+	}
+
+	return node
+}
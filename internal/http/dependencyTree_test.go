@@ -0,0 +1,70 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+func TestNewDependencyTree_NestsChildrenUnderParents(t *testing.T) {
+	tree := newDependencyTree([]data.HostData{
+		{Name: "router"},
+		{Name: "server", DependsOn: []string{"switch"}},
+		{Name: "switch", DependsOn: []string{"router"}},
+		{Name: "standalone", DependsOn: []string{"unknown"}},
+	})
+
+	if len(tree.Roots) != 2 {
+		t.Fatalf("Expected 2 roots, got %d", len(tree.Roots))
+	}
+
+	router := tree.Roots[0]
+
+	if router.Name != "router" || len(router.Children) != 1 || router.Children[0].Name != "switch" {
+		t.Fatalf("Expected router with child switch, got %v", router)
+	}
+
+	if len(router.Children[0].Children) != 1 || router.Children[0].Children[0].Name != "server" {
+		t.Errorf("Expected switch with child server, got %v", router.Children[0])
+	}
+
+	if tree.Roots[1].Name != "standalone" {
+		t.Errorf("Expected standalone root, got %s", tree.Roots[1].Name)
+	}
+}
+
+func TestNewDependencyTree_Cycle_Terminates(t *testing.T) {
+	tree := newDependencyTree([]data.HostData{
+		{Name: "root"},
+		{Name: "a", DependsOn: []string{"root", "b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	})
+
+	if len(tree.Roots) != 1 {
+		t.Fatalf("Expected 1 root, got %d", len(tree.Roots))
+	}
+
+	a := tree.Roots[0].Children[0]
+
+	if a.Name != "a" || len(a.Children) != 1 || len(a.Children[0].Children) != 0 {
+		t.Errorf("Expected the cycle to be cut below b, got %v", a)
+	}
+}
+
+func TestNewDependencyTree_CycleWithoutRoot_ListsFirstHostAsRoot(t *testing.T) {
+	tree := newDependencyTree([]data.HostData{
+		{Name: "router"},
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	})
+
+	if len(tree.Roots) != 2 {
+		t.Fatalf("Expected 2 roots, got %d", len(tree.Roots))
+	}
+
+	a := tree.Roots[1]
+
+	if a.Name != "a" || len(a.Children) != 1 || a.Children[0].Name != "b" || len(a.Children[0].Children) != 0 {
+		t.Errorf("Expected root a with child b, got %v", a)
+	}
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+const journalFormTimeLayout = "2006-01-02T15:04"
+const journalDefaultLimit = 500
+
+// eventJournal is the model of the event timeline page.  The filter fields hold the submitted form values, so the
+// form can be redisplayed as entered.
+type eventJournal struct {
+	HostName         string
+	NetInterfaceName string
+	EventType        string
+	Since            string
+	Until            string
+	Entries          []data.JournalEntry
+}
+
+// parseJournalFilter builds a filter from the query parameters host, interface, type, since, until and limit.
+// Times may be given in RFC 3339 format, or in the local time format of a datetime-local input.
+func parseJournalFilter(query url.Values) (data.JournalFilter, error) {
+	filter := data.JournalFilter{
+		HostName:         query.Get("host"),
+		NetInterfaceName: query.Get("interface"),
+		EventType:        query.Get("type"),
+		Limit:            journalDefaultLimit,
+	}
+	var err error
+
+	if filter.Since, err = parseJournalTime(query.Get("since")); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+
+	if filter.Until, err = parseJournalTime(query.Get("until")); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("invalid limit %s", value)
+		}
+	}
+
+	return filter, nil
+}
+
+func parseJournalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if result, err := time.Parse(time.RFC3339, value); err == nil {
+		return result, nil
+	}
+
+	return time.ParseInLocation(journalFormTimeLayout, value, time.Local)
+}
@@ -1,11 +1,10 @@
 package http
 
 import (
-	"github.com/avanha/pmaas-plugin-netmon/data"
 	"github.com/avanha/pmaas-spi"
 )
 
 type hostInterfaceWithRenderer struct {
-	NetInterface data.NetInterfaceData
+	NetInterface netInterfaceView
 	Renderer     spi.EntityRenderFunc
 }
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+const slaReportMaxOutages = 50
+
+type hostSlaReport struct {
+	data.HostData
+	ReportTime time.Time
+	Periods    []data.AvailabilityPeriod
+
+	// Outages lists the most recent outages, newest first
+	Outages []data.Outage
+}
+
+func newHostSlaReport(hostData data.HostData, now time.Time) *hostSlaReport {
+	outages := hostData.GetOutages()
+	recentOutages := make([]data.Outage, 0, min(len(outages), slaReportMaxOutages))
+
+	for i := len(outages) - 1; i >= 0 && len(recentOutages) < slaReportMaxOutages; i-- {
+		recentOutages = append(recentOutages, outages[i])
+	}
+
+	return &hostSlaReport{
+		HostData:   hostData,
+		ReportTime: now,
+		Periods:    data.CalcAvailabilityReport(outages, hostData.MonitoringStartTime, now),
+		Outages:    recentOutages,
+	}
+}
@@ -2,18 +2,23 @@ package http
 
 import (
 	"embed"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"slices"
 	"sort"
+	"strings"
 	"text/template"
 	"time"
 
 	"strconv"
 
 	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/events"
 	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/eventtext"
 	"github.com/avanha/pmaas-spi"
 )
 
@@ -24,10 +29,16 @@ func RenderHostInterface(interfaceWithRenderer *hostInterfaceWithRenderer) (stri
 	return interfaceWithRenderer.Renderer(&interfaceWithRenderer.NetInterface)
 }
 
+// RenderGraph renders the Tx and Rx rates as overlapping bars.  The bars share the width of the graph, so the
+// series can have any number of points.
 func RenderGraph(data ...[]uint64) (string, error) {
 	result := "<div class=\"bar-graph\">"
 	maxValue := uint64(0)
 
+	if len(data[0]) == 0 {
+		return result + "</div>", nil
+	}
+
 	for i := 0; i < len(data); i++ {
 		seriesMax := slices.Max(data[i])
 		if seriesMax > maxValue {
@@ -35,8 +46,12 @@ func RenderGraph(data ...[]uint64) (string, error) {
 		}
 	}
 
+	// Avoid dividing by zero when there was no traffic at all
+	maxValue = max(maxValue, 1)
+	barWidth := 100.0 / float64(len(data[0]))
+
 	for i := 0; i < len(data[0]); i++ {
-		result += "<div class=\"bar-container\">"
+		result += fmt.Sprintf("<div class=\"bar-container\" style=\"width: %.3f%%;\">", barWidth)
 		result += fmt.Sprintf("<span class=\"data-label\">Tx: %s Rx: %s</span>",
 			FormatBits(data[0][i]), FormatBits(data[1][i]))
 
@@ -56,6 +71,49 @@ func RenderGraph(data ...[]uint64) (string, error) {
 	return result, nil
 }
 
+// RenderPacketGraph renders the packet rates per cast type in one direction, Rx or Tx, as overlapping bars, like
+// RenderGraph.
+func RenderPacketGraph(direction string, unicast []float64, multicast []float64, broadcast []float64) (string, error) {
+	result := "<div class=\"bar-graph\">"
+
+	if len(unicast) == 0 {
+		return result + "</div>", nil
+	}
+
+	series := [][]float64{unicast, multicast, broadcast}
+	maxValue := 0.0
+
+	for i := 0; i < len(series); i++ {
+		maxValue = max(maxValue, slices.Max(series[i]))
+	}
+
+	barWidth := 100.0 / float64(len(unicast))
+
+	for i := 0; i < len(unicast); i++ {
+		result += fmt.Sprintf("<div class=\"bar-container\" style=\"width: %.3f%%;\">", barWidth)
+		result += fmt.Sprintf(
+			"<span class=\"data-label\">%s pps Unicast: %.0f Multicast: %.0f Broadcast: %.0f</span>",
+			direction, unicast[i], multicast[i], broadcast[i])
+
+		for j := 0; j < len(series); j++ {
+			height := 0
+
+			if maxValue > 0 {
+				height = int(series[j][i] / maxValue * 100)
+			}
+
+			result += fmt.Sprintf("<div class=\"bar series-%d\" style=\"height: %d%%;\"> </div>", j, height)
+		}
+
+		result += "<div class=\"bar-overlay\"> </div>"
+		result += "</div>"
+	}
+
+	result += "</div>"
+
+	return result, nil
+}
+
 var dataSizeSuffixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB", "ZiB", "YiB"}
 var dataRateSuffixes = []string{"b", "kb", "Mb", "Gb", "Tb", "Pb", "Eb", "Zb", "Yb"}
 
@@ -158,16 +216,7 @@ func FormatShortDuration(duration time.Duration) string {
 }
 
 func FormatReachability(value int) string {
-	switch value {
-	case data.ReachabilityUnreachable:
-		return "Down"
-	case data.ReachabilityReachable:
-		return "Up"
-	case data.ReachabilityUnknown:
-		return "Unknown"
-	default:
-		return "Unknown"
-	}
+	return data.ReachabilityName(value)
 }
 
 func ReachabilityClass(value int) string {
@@ -176,6 +225,8 @@ func ReachabilityClass(value int) string {
 		return "unreachable"
 	case data.ReachabilityReachable:
 		return "reachable"
+	case data.ReachabilityDegraded:
+		return "degraded"
 	case data.ReachabilityUnknown:
 		return "unknown"
 	default:
@@ -183,6 +234,30 @@ func ReachabilityClass(value int) string {
 	}
 }
 
+// FormatAvailability formats an availability percentage.  Three decimal digits are needed to distinguish
+// common SLA targets, such as 99.9% and 99.95%.
+func FormatAvailability(value float64) string {
+	return fmt.Sprintf("%.3f%%", value)
+}
+
+// FormatRate formats a per-second event rate, such as errors per second.
+func FormatRate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// FormatRatio formats a small ratio, such as errors per packet, in scientific notation.
+func FormatRatio(value float64) string {
+	return strconv.FormatFloat(value, 'e', 2, 64)
+}
+
+func SeverityClass(severity events.AlertSeverity) string {
+	return strings.ToLower(severity.String())
+}
+
+func DeliveryStatusClass(status string) string {
+	return strings.ReplaceAll(strings.ToLower(status), " ", "-")
+}
+
 var hostTemplate = spi.TemplateInfo{
 	Name:   "host",
 	Paths:  []string{"templates/host.htmlt"},
@@ -193,6 +268,65 @@ var hostTemplate = spi.TemplateInfo{
 		"FormatShortDuration": FormatShortDuration,
 		"FormatReachability":  FormatReachability,
 		"ReachabilityClass":   ReachabilityClass,
+		"PathEscape":          url.PathEscape,
+		"Join":                strings.Join,
+	},
+}
+
+var slaTemplate = spi.TemplateInfo{
+	Name:   "sla",
+	Paths:  []string{"templates/sla.htmlt"},
+	Styles: []string{"css/sla.css"},
+	FuncMap: template.FuncMap{
+		"FormatDuration":     FormatDuration,
+		"FormatAvailability": FormatAvailability,
+	},
+}
+
+var dependencyTreeTemplate = spi.TemplateInfo{
+	Name:   "dependency_tree",
+	Paths:  []string{"templates/dependency_tree.htmlt"},
+	Styles: []string{"css/dependency_tree.css"},
+	FuncMap: template.FuncMap{
+		"FormatReachability": FormatReachability,
+		"ReachabilityClass":  ReachabilityClass,
+		"PathEscape":         url.PathEscape,
+	},
+}
+
+var activeAlertsTemplate = spi.TemplateInfo{
+	Name:   "active_alerts",
+	Paths:  []string{"templates/active_alerts.htmlt"},
+	Styles: []string{"css/active_alerts.css"},
+	FuncMap: template.FuncMap{
+		"SeverityClass": SeverityClass,
+	},
+}
+
+var pluginStatusTemplate = spi.TemplateInfo{
+	Name:   "plugin_status",
+	Paths:  []string{"templates/plugin_status.htmlt"},
+	Styles: []string{"css/plugin_status.css"},
+	FuncMap: template.FuncMap{
+		"FormatShortDuration": FormatShortDuration,
+	},
+}
+
+var notificationLogTemplate = spi.TemplateInfo{
+	Name:   "notification_log",
+	Paths:  []string{"templates/notification_log.htmlt"},
+	Styles: []string{"css/notification_log.css"},
+	FuncMap: template.FuncMap{
+		"DeliveryStatusClass": DeliveryStatusClass,
+	},
+}
+
+var eventJournalTemplate = spi.TemplateInfo{
+	Name:   "event_journal",
+	Paths:  []string{"templates/event_journal.htmlt"},
+	Styles: []string{"css/event_journal.css"},
+	FuncMap: template.FuncMap{
+		"QueryEscape": url.QueryEscape,
 	},
 }
 
@@ -201,33 +335,72 @@ var netInterfaceTemplate = spi.TemplateInfo{
 	Paths:  []string{"templates/net_interface.htmlt"},
 	Styles: []string{"css/net_interface.css"},
 	FuncMap: template.FuncMap{
-		"FormatBytes": FormatBytes,
-		"FormatBits":  FormatBits,
-		"RenderGraph": RenderGraph,
+		"FormatBytes":       FormatBytes,
+		"FormatBits":        FormatBits,
+		"FormatRate":        FormatRate,
+		"FormatRatio":       FormatRatio,
+		"FormatSpeed":       eventtext.FormatSpeed,
+		"RenderGraph":       RenderGraph,
+		"RenderPacketGraph": RenderPacketGraph,
 	},
 }
 
 type Handler struct {
-	container   spi.IPMAASContainer
-	entityStore common.EntityStore
+	container             spi.IPMAASContainer
+	entityStore           common.EntityStore
+	maintenanceController common.MaintenanceController
+	scanRequester         common.ScanRequester
 }
 
 func NewHandler() *Handler {
 	return &Handler{}
 }
 
-func (h *Handler) Init(container spi.IPMAASContainer, entityStore common.EntityStore) {
+func (h *Handler) Init(
+	container spi.IPMAASContainer,
+	entityStore common.EntityStore,
+	maintenanceController common.MaintenanceController,
+	scanRequester common.ScanRequester) {
 	h.container = container
 	h.entityStore = entityStore
+	h.maintenanceController = maintenanceController
+	h.scanRequester = scanRequester
 	container.ProvideContentFS(&contentFS, "content")
 	container.EnableStaticContent("static")
 	container.AddRoute("/plugins/netmon/", h.handleHttpListRequest)
+	container.AddRoute(slaPathPrefix, h.handleHttpSlaRequest)
+	container.AddRoute("/plugins/netmon/dependencies/", h.handleHttpDependenciesRequest)
+	container.AddRoute("/plugins/netmon/maintenance", h.handleHttpMaintenanceRequest)
+	container.AddRoute("/plugins/netmon/scan", h.handleHttpScanRequest)
+	container.AddRoute("/plugins/netmon/notifications/", h.handleHttpNotificationsRequest)
+	container.AddRoute("/plugins/netmon/events/", h.handleHttpEventsRequest)
+	container.AddRoute("/plugins/netmon/events.json", h.handleHttpEventsJsonRequest)
+	container.AddRoute("/plugins/netmon/bandwidth.json", h.handleHttpBandwidthJsonRequest)
+	container.AddRoute("/plugins/netmon/status.json", h.handleHttpStatusJsonRequest)
 	container.RegisterEntityRenderer(
 		reflect.TypeOf((*hostWithInterfaces)(nil)).Elem(),
 		h.hostDataRendererFactory)
 	container.RegisterEntityRenderer(
-		reflect.TypeOf((*data.NetInterfaceData)(nil)).Elem(),
+		reflect.TypeOf((*netInterfaceView)(nil)).Elem(),
 		h.netInterfaceDataRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*hostSlaReport)(nil)).Elem(),
+		h.slaReportRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*dependencyTree)(nil)).Elem(),
+		h.dependencyTreeRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*activeAlertsPanel)(nil)).Elem(),
+		h.activeAlertsRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*pluginStatusHeader)(nil)).Elem(),
+		h.pluginStatusRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*notificationLog)(nil)).Elem(),
+		h.notificationLogRendererFactory)
+	container.RegisterEntityRenderer(
+		reflect.TypeOf((*eventJournal)(nil)).Elem(),
+		h.eventJournalRendererFactory)
 }
 
 func (h *Handler) handleHttpListRequest(writer http.ResponseWriter, request *http.Request) {
@@ -247,9 +420,13 @@ func (h *Handler) handleHttpListRequest(writer http.ResponseWriter, request *htt
 		panic(fmt.Errorf("netmon handleHttpListRequest: Error retrieving NetInterfaceData renderer: %w", err))
 	}
 
-	// Convert the slice of structs to a slice of any
+	selectedGraphRange := findGraphRange(request.URL.Query().Get("range"))
+	now := time.Now()
+
+	// Convert the slice of structs to a slice of any, with the active alerts at the top
 	entityListSize := len(result.Hosts)
-	entityPointers := make([]any, entityListSize)
+	entityPointers := make([]any, entityListSize+1)
+	entityPointers[0] = &activeAlertsPanel{Alerts: result.Alerts}
 
 	for i := 0; i < entityListSize; i++ {
 		host := hostWithInterfaces{
@@ -261,8 +438,12 @@ func (h *Handler) handleHttpListRequest(writer http.ResponseWriter, request *htt
 
 		for j := 0; j < interfaceListSize; j++ {
 			host.Interfaces[j] = &hostInterfaceWithRenderer{
-				NetInterface: result.Hosts[i].NetInterfaceDataList[j],
-				Renderer:     interfaceRenderer.RenderFunc,
+				NetInterface: netInterfaceView{
+					NetInterfaceData: result.Hosts[i].NetInterfaceDataList[j],
+					GraphRange:       selectedGraphRange,
+					Now:              now,
+				},
+				Renderer: interfaceRenderer.RenderFunc,
 			}
 		}
 
@@ -270,19 +451,231 @@ func (h *Handler) handleHttpListRequest(writer http.ResponseWriter, request *htt
 			return host.Interfaces[i].NetInterface.Name < host.Interfaces[j].NetInterface.Name
 		})
 
-		entityPointers[i] = &host
+		entityPointers[i+1] = &host
 	}
 
 	h.container.RenderList(
 		writer,
 		request,
 		spi.RenderListOptions{
-			Title: "netmon",
-			//Header: &result.Status,
+			Title:  "netmon",
+			Header: &pluginStatusHeader{PluginStatus: result.Status},
 		},
 		entityPointers)
 }
 
+const slaPathPrefix = "/plugins/netmon/sla/"
+
+// handleHttpSlaRequest renders the availability report of a single host, identified by the remainder of the path,
+// for example /plugins/netmon/sla/router.
+func (h *Handler) handleHttpSlaRequest(writer http.ResponseWriter, request *http.Request) {
+	hostName := strings.TrimPrefix(request.URL.Path, slaPathPrefix)
+	result, err := h.entityStore.GetStatusAndEntities()
+
+	if err != nil {
+		panic(fmt.Errorf("netmon handleHttpSlaRequest: Error retrieving entities: %w", err))
+	}
+
+	hostIndex := slices.IndexFunc(result.Hosts, func(hostData data.HostData) bool {
+		return hostData.Name == hostName
+	})
+
+	if hostIndex < 0 {
+		http.NotFound(writer, request)
+		return
+	}
+
+	h.container.RenderList(
+		writer,
+		request,
+		spi.RenderListOptions{
+			Title: fmt.Sprintf("netmon - %s availability", hostName),
+		},
+		[]any{newHostSlaReport(result.Hosts[hostIndex], time.Now())})
+}
+
+func (h *Handler) handleHttpDependenciesRequest(writer http.ResponseWriter, request *http.Request) {
+	result, err := h.entityStore.GetStatusAndEntities()
+
+	if err != nil {
+		panic(fmt.Errorf("netmon handleHttpDependenciesRequest: Error retrieving entities: %w", err))
+	}
+
+	sort.SliceStable(result.Hosts, func(i, j int) bool {
+		return result.Hosts[i].Name < result.Hosts[j].Name
+	})
+
+	h.container.RenderList(
+		writer,
+		request,
+		spi.RenderListOptions{
+			Title: "netmon - host dependencies",
+		},
+		[]any{newDependencyTree(result.Hosts)})
+}
+
+func (h *Handler) handleHttpNotificationsRequest(writer http.ResponseWriter, request *http.Request) {
+	result, err := h.entityStore.GetStatusAndEntities()
+
+	if err != nil {
+		panic(fmt.Errorf("netmon handleHttpNotificationsRequest: Error retrieving entities: %w", err))
+	}
+
+	h.container.RenderList(
+		writer,
+		request,
+		spi.RenderListOptions{
+			Title: "netmon - notifications",
+		},
+		[]any{&notificationLog{Deliveries: result.NotificationDeliveries}})
+}
+
+// handleHttpEventsRequest renders the event timeline, filtered by the query parameters described in
+// parseJournalFilter.
+func (h *Handler) handleHttpEventsRequest(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter, err := parseJournalFilter(query)
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.entityStore.QueryEventJournal(filter)
+
+	if err != nil {
+		panic(fmt.Errorf("netmon handleHttpEventsRequest: Error querying event journal: %w", err))
+	}
+
+	h.container.RenderList(
+		writer,
+		request,
+		spi.RenderListOptions{
+			Title: "netmon - events",
+		},
+		[]any{&eventJournal{
+			HostName:         filter.HostName,
+			NetInterfaceName: filter.NetInterfaceName,
+			EventType:        filter.EventType,
+			Since:            query.Get("since"),
+			Until:            query.Get("until"),
+			Entries:          entries,
+		}})
+}
+
+// handleHttpEventsJsonRequest returns the filtered event journal as a JSON array, newest first.
+func (h *Handler) handleHttpEventsJsonRequest(writer http.ResponseWriter, request *http.Request) {
+	filter, err := parseJournalFilter(request.URL.Query())
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.entityStore.QueryEventJournal(filter)
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(entries); err != nil {
+		fmt.Printf("netmon handleHttpEventsJsonRequest: Error writing response: %v\n", err)
+	}
+}
+
+// handleHttpBandwidthJsonRequest returns the 95th percentile rates of every interface, for the current and the
+// previous billing period, as a JSON array.
+func (h *Handler) handleHttpBandwidthJsonRequest(writer http.ResponseWriter, request *http.Request) {
+	result, err := h.entityStore.GetStatusAndEntities()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(newBandwidthReports(result.Hosts)); err != nil {
+		fmt.Printf("netmon handleHttpBandwidthJsonRequest: Error writing response: %v\n", err)
+	}
+}
+
+// handleHttpStatusJsonRequest writes the plugin's own metrics, such as scan durations, timeouts and skipped ticks,
+// as JSON.  Durations are in nanoseconds.
+func (h *Handler) handleHttpStatusJsonRequest(writer http.ResponseWriter, _ *http.Request) {
+	result, err := h.entityStore.GetStatusAndEntities()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(result.Status); err != nil {
+		fmt.Printf("netmon handleHttpStatusJsonRequest: Error writing response: %v\n", err)
+	}
+}
+
+// handleHttpMaintenanceRequest starts or ends an ad-hoc maintenance window.  It expects a POST with the form
+// fields action (start or end), host or group, and, when starting, minutes.
+func (h *Handler) handleHttpMaintenanceRequest(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", http.MethodPost)
+		http.Error(writer, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hostName := request.FormValue("host")
+	hostGroup := request.FormValue("group")
+	var err error
+
+	switch request.FormValue("action") {
+	case "start":
+		minutes, parseErr := strconv.Atoi(request.FormValue("minutes"))
+
+		if parseErr != nil || minutes <= 0 {
+			http.Error(writer, "Invalid minutes", http.StatusBadRequest)
+			return
+		}
+
+		err = h.maintenanceController.StartAdHocMaintenance(hostName, hostGroup, time.Duration(minutes)*time.Minute)
+	case "end":
+		err = h.maintenanceController.EndAdHocMaintenance(hostName, hostGroup)
+	default:
+		http.Error(writer, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The window takes effect with the host's next scan
+	http.Redirect(writer, request, "/plugins/netmon/", http.StatusSeeOther)
+}
+
+// handleHttpScanRequest requests an immediate scan of a host.  It expects a POST with the form field host.
+func (h *Handler) handleHttpScanRequest(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.Header().Set("Allow", http.MethodPost)
+		http.Error(writer, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.scanRequester.RequestScan(request.FormValue("host")); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The scan runs in the background, so the page shows the previous results until it's refreshed
+	http.Redirect(writer, request, "/plugins/netmon/", http.StatusSeeOther)
+}
+
 func (h *Handler) hostDataRendererFactory() (spi.EntityRenderer, error) {
 	return spi.TemplateBasedRendererFactory(
 		h.container,
@@ -299,8 +692,74 @@ func (h *Handler) netInterfaceDataRendererFactory() (spi.EntityRenderer, error)
 		h.container,
 		&netInterfaceTemplate,
 		func(entity any) bool {
-			_, ok := entity.(*data.NetInterfaceData)
+			_, ok := entity.(*netInterfaceView)
+			return ok
+		},
+		"*netInterfaceView")
+}
+
+func (h *Handler) slaReportRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&slaTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*hostSlaReport)
+			return ok
+		},
+		"*hostSlaReport")
+}
+
+func (h *Handler) dependencyTreeRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&dependencyTreeTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*dependencyTree)
+			return ok
+		},
+		"*dependencyTree")
+}
+
+func (h *Handler) activeAlertsRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&activeAlertsTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*activeAlertsPanel)
+			return ok
+		},
+		"*activeAlertsPanel")
+}
+
+func (h *Handler) pluginStatusRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&pluginStatusTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*pluginStatusHeader)
+			return ok
+		},
+		"*pluginStatusHeader")
+}
+
+func (h *Handler) notificationLogRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&notificationLogTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*notificationLog)
+			return ok
+		},
+		"*notificationLog")
+}
+
+func (h *Handler) eventJournalRendererFactory() (spi.EntityRenderer, error) {
+	return spi.TemplateBasedRendererFactory(
+		h.container,
+		&eventJournalTemplate,
+		func(entity any) bool {
+			_, ok := entity.(*eventJournal)
 			return ok
 		},
-		"*data.NetInterfaceData")
+		"*eventJournal")
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -94,3 +95,27 @@ func TestFormatShortDuration_MultipleMicrosAndNanos_ReturnsWholeMicros(t *testin
 		t.Errorf("Expected %s, got %s", expected, result)
 	}
 }
+
+func TestRenderPacketGraph_EmptySeries_RendersEmptyGraph(t *testing.T) {
+	result, err := RenderPacketGraph("Rx", []float64{}, []float64{}, []float64{})
+
+	if err != nil || result != "<div class=\"bar-graph\"></div>" {
+		t.Errorf("Expected an empty graph, got %s, %v", result, err)
+	}
+}
+
+func TestRenderPacketGraph_Series_SizesBarsAndLabelsDirection(t *testing.T) {
+	result, _ := RenderPacketGraph("Tx", []float64{10, 20, 40, 0}, []float64{0, 0, 0, 0}, []float64{1, 2, 4, 0})
+
+	if count := strings.Count(result, "style=\"width: 25.000%;\""); count != 4 {
+		t.Errorf("Expected 4 bars at 25%% width, got %d", count)
+	}
+
+	if !strings.Contains(result, "Tx pps Unicast: 40 Multicast: 0 Broadcast: 4") {
+		t.Errorf("Expected a Tx label, got %s", result)
+	}
+
+	if !strings.Contains(result, "<div class=\"bar series-0\" style=\"height: 100%;\">") {
+		t.Errorf("Expected the largest bar at full height, got %s", result)
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"slices"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+// graphMaxPoints limits the number of bars in a traffic graph.  The rate history picks the finest resolution
+// that covers the selected range within this limit.
+const graphMaxPoints = 200
+
+type graphRange struct {
+	Name   string
+	Label  string
+	Window time.Duration
+}
+
+var graphRanges = []graphRange{
+	{Name: "hour", Label: "Hour", Window: time.Hour},
+	{Name: "day", Label: "Day", Window: 24 * time.Hour},
+	{Name: "week", Label: "Week", Window: 7 * 24 * time.Hour},
+	{Name: "month", Label: "Month", Window: 30 * 24 * time.Hour},
+}
+
+// findGraphRange returns the named range, or the first (shortest) range if the name is unknown.
+func findGraphRange(name string) graphRange {
+	index := slices.IndexFunc(graphRanges, func(r graphRange) bool { return r.Name == name })
+
+	if index < 0 {
+		return graphRanges[0]
+	}
+
+	return graphRanges[index]
+}
+
+// netInterfaceView is the model of the interface card.  GraphRange selects the time range of the traffic graph.
+type netInterfaceView struct {
+	data.NetInterfaceData
+	GraphRange graphRange
+	Now        time.Time
+}
+
+func (v *netInterfaceView) GraphRanges() []graphRange {
+	return graphRanges
+}
+
+func (v *netInterfaceView) BytesInGraphData() []uint64 {
+	return v.graphData(v.BytesInHistory)
+}
+
+func (v *netInterfaceView) BytesOutGraphData() []uint64 {
+	return v.graphData(v.BytesOutHistory)
+}
+
+func (v *netInterfaceView) graphData(history *data.RateHistory) []uint64 {
+	series := history.Series(v.GraphRange.Window, graphMaxPoints, v.Now)
+	result := make([]uint64, len(series.Points))
+
+	for i, average := range series.Averages() {
+		result[i] = uint64(average)
+	}
+
+	return result
+}
+
+// DataCapUsedWidth returns the width of the used part of the data cap bar, in percent.
+func (v *netInterfaceView) DataCapUsedWidth() float64 {
+	return min(v.GetDataCapUsagePercent(), 100)
+}
+
+// DataCapProjectedWidth returns the width of the projected part of the data cap bar, in percent.
+func (v *netInterfaceView) DataCapProjectedWidth() float64 {
+	return min(v.GetProjectedDataCapPercent(), 100)
+}
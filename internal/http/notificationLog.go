@@ -0,0 +1,7 @@
+package http
+
+import "github.com/avanha/pmaas-plugin-netmon/data"
+
+type notificationLog struct {
+	Deliveries []data.NotificationDelivery
+}
@@ -0,0 +1,8 @@
+package http
+
+import "github.com/avanha/pmaas-plugin-netmon/data"
+
+// pluginStatusHeader is rendered as the list page's header.
+type pluginStatusHeader struct {
+	data.PluginStatus
+}
@@ -0,0 +1,207 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+)
+
+// ActiveWindow describes a maintenance window that's in effect for a host.
+type ActiveWindow struct {
+	Name           string
+	EndTime        time.Time
+	SuppressEvents bool
+	AdHoc          bool
+	// HostAdHoc is true for an ad-hoc window started for the host itself, rather than for one of its groups
+	HostAdHoc bool
+}
+
+type scheduledWindow struct {
+	name           string
+	schedule       *Schedule
+	duration       time.Duration
+	location       *time.Location
+	hosts          []string
+	hostGroups     []string
+	suppressEvents bool
+}
+
+func (w *scheduledWindow) appliesTo(hostName string, hostGroups []string) bool {
+	if len(w.hosts) == 0 && len(w.hostGroups) == 0 {
+		return true
+	}
+
+	if slices.Contains(w.hosts, hostName) {
+		return true
+	}
+
+	return slices.ContainsFunc(w.hostGroups, func(group string) bool { return slices.Contains(hostGroups, group) })
+}
+
+// Manager tracks the configured and ad-hoc maintenance windows.  It is not thread safe, and is meant to be used
+// from the plugin's goroutine.
+type Manager struct {
+	windows             []*scheduledWindow
+	adHocWindows        map[string]ActiveWindow
+	adHocSuppressEvents bool
+}
+
+// NewManager creates a Manager for the configured windows.  Invalid windows are skipped and reported via the
+// returned error, but the returned Manager is always usable.
+func NewManager(windowConfigs []config.MaintenanceWindow, adHocSuppressEvents bool) (*Manager, error) {
+	var errs []error
+	manager := &Manager{
+		windows:             make([]*scheduledWindow, 0, len(windowConfigs)),
+		adHocWindows:        make(map[string]ActiveWindow),
+		adHocSuppressEvents: adHocSuppressEvents,
+	}
+
+	for _, windowConfig := range windowConfigs {
+		schedule, err := ParseSchedule(windowConfig.Schedule)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("maintenance window %s: %w", windowConfig.Name, err))
+			continue
+		}
+
+		if windowConfig.DurationMinutes <= 0 {
+			errs = append(errs, fmt.Errorf("maintenance window %s: duration must be positive", windowConfig.Name))
+			continue
+		}
+
+		location := time.Local
+
+		if windowConfig.TimeZone != "" {
+			location, err = time.LoadLocation(windowConfig.TimeZone)
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("maintenance window %s: %w", windowConfig.Name, err))
+				continue
+			}
+		}
+
+		manager.windows = append(manager.windows, &scheduledWindow{
+			name:           windowConfig.Name,
+			schedule:       schedule,
+			duration:       time.Duration(windowConfig.DurationMinutes) * time.Minute,
+			location:       location,
+			hosts:          slices.Clone(windowConfig.Hosts),
+			hostGroups:     slices.Clone(windowConfig.HostGroups),
+			suppressEvents: windowConfig.SuppressEvents,
+		})
+	}
+
+	return manager, errors.Join(errs...)
+}
+
+// Active returns the maintenance window in effect for the host at the given time, or nil if there is none.
+// Ad-hoc windows take precedence over scheduled ones.
+func (m *Manager) Active(hostName string, hostGroups []string, t time.Time) *ActiveWindow {
+	if window, ok := m.activeAdHoc(hostKey(hostName), t); ok {
+		return &window
+	}
+
+	for _, group := range hostGroups {
+		if window, ok := m.activeAdHoc(groupKey(group), t); ok {
+			return &window
+		}
+	}
+
+	for _, window := range m.windows {
+		if !window.appliesTo(hostName, hostGroups) {
+			continue
+		}
+
+		// The window is active if it started less than its duration ago
+		start, ok := window.schedule.MostRecentStart(t.In(window.location), window.duration-time.Nanosecond)
+
+		if ok {
+			return &ActiveWindow{
+				Name:           window.name,
+				EndTime:        start.Add(window.duration),
+				SuppressEvents: window.suppressEvents,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) activeAdHoc(key string, t time.Time) (ActiveWindow, bool) {
+	window, ok := m.adHocWindows[key]
+
+	if !ok {
+		return ActiveWindow{}, false
+	}
+
+	if !t.Before(window.EndTime) {
+		delete(m.adHocWindows, key)
+		return ActiveWindow{}, false
+	}
+
+	return window, true
+}
+
+// StartAdHoc starts an on-demand maintenance window for a host or, if hostName is empty, a host group.  Starting
+// a window for a target that already has one replaces it.
+func (m *Manager) StartAdHoc(hostName string, hostGroup string, start time.Time, duration time.Duration) error {
+	key, err := adHocKey(hostName, hostGroup)
+
+	if err != nil {
+		return err
+	}
+
+	if duration <= 0 {
+		return fmt.Errorf("maintenance duration must be positive")
+	}
+
+	m.adHocWindows[key] = ActiveWindow{
+		Name:           "Ad-hoc",
+		EndTime:        start.Add(duration),
+		SuppressEvents: m.adHocSuppressEvents,
+		AdHoc:          true,
+		HostAdHoc:      hostName != "",
+	}
+
+	return nil
+}
+
+// EndAdHoc ends an on-demand maintenance window for a host or, if hostName is empty, a host group.
+func (m *Manager) EndAdHoc(hostName string, hostGroup string) error {
+	key, err := adHocKey(hostName, hostGroup)
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := m.adHocWindows[key]; !ok {
+		return fmt.Errorf("no ad-hoc maintenance window is active for %s%s", hostName, hostGroup)
+	}
+
+	delete(m.adHocWindows, key)
+
+	return nil
+}
+
+func adHocKey(hostName string, hostGroup string) (string, error) {
+	if hostName != "" {
+		return hostKey(hostName), nil
+	}
+
+	if hostGroup != "" {
+		return groupKey(hostGroup), nil
+	}
+
+	return "", fmt.Errorf("either a host or a host group is required")
+}
+
+func hostKey(hostName string) string {
+	return "host:" + hostName
+}
+
+func groupKey(groupName string) string {
+	return "group:" + groupName
+}
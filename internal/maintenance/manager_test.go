@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+)
+
+var sundayMorning = time.Date(2023, 10, 15, 3, 0, 0, 0, time.UTC)
+
+func TestNewManager_InvalidWindow_SkippedAndReported(t *testing.T) {
+	manager, err := NewManager([]config.MaintenanceWindow{
+		{Name: "bad", Schedule: "0 3 * *", DurationMinutes: 60},
+		{Name: "good", Schedule: "0 3 * * 0", DurationMinutes: 60, TimeZone: "UTC"},
+	}, true)
+
+	if err == nil {
+		t.Errorf("Expected error for the invalid window")
+	}
+
+	if window := manager.Active("switch", nil, sundayMorning); window == nil || window.Name != "good" {
+		t.Errorf("Expected the valid window to be active, got %v", window)
+	}
+}
+
+func TestActive_ScheduledWindow_ActiveForDuration(t *testing.T) {
+	manager, _ := NewManager([]config.MaintenanceWindow{
+		{Name: "reboot", Schedule: "0 3 * * 0", DurationMinutes: 30, TimeZone: "UTC", HostGroups: []string{"switches"}},
+	}, true)
+
+	if window := manager.Active("switch", []string{"switches"}, sundayMorning.Add(29*time.Minute)); window == nil {
+		t.Errorf("Expected window to be active")
+	} else if !window.EndTime.Equal(sundayMorning.Add(30 * time.Minute)) {
+		t.Errorf("Expected end time %v, got %v", sundayMorning.Add(30*time.Minute), window.EndTime)
+	}
+
+	if window := manager.Active("switch", []string{"switches"}, sundayMorning.Add(30*time.Minute)); window != nil {
+		t.Errorf("Expected window to have ended, got %v", window)
+	}
+
+	if window := manager.Active("router", []string{"routers"}, sundayMorning); window != nil {
+		t.Errorf("Expected window not to apply to other groups, got %v", window)
+	}
+}
+
+func TestActive_TimeZone_EvaluatedInWindowTimeZone(t *testing.T) {
+	manager, err := NewManager([]config.MaintenanceWindow{
+		{Name: "reboot", Schedule: "0 3 * * 0", DurationMinutes: 30, TimeZone: "America/New_York"},
+	}, true)
+
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	if window := manager.Active("switch", nil, sundayMorning); window != nil {
+		t.Errorf("Expected 03:00 UTC not to be in the window, got %v", window)
+	}
+
+	// 3am EDT is 7am UTC
+	if window := manager.Active("switch", nil, sundayMorning.Add(4*time.Hour)); window == nil {
+		t.Errorf("Expected 07:00 UTC to be in the window")
+	}
+}
+
+func TestStartAdHoc_HostGroup_ActiveUntilEnded(t *testing.T) {
+	manager, _ := NewManager(nil, false)
+
+	if err := manager.StartAdHoc("", "", sundayMorning, time.Hour); err == nil {
+		t.Errorf("Expected error without a host or group")
+	}
+
+	if err := manager.StartAdHoc("", "switches", sundayMorning, time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	window := manager.Active("switch", []string{"switches"}, sundayMorning.Add(time.Minute))
+
+	if window == nil || !window.AdHoc || window.HostAdHoc || window.SuppressEvents {
+		t.Errorf("Expected an ad-hoc window that doesn't suppress events, got %v", window)
+	}
+
+	if err := manager.EndAdHoc("", "switches"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if window := manager.Active("switch", []string{"switches"}, sundayMorning.Add(time.Minute)); window != nil {
+		t.Errorf("Expected no window after ending it, got %v", window)
+	}
+}
+
+func TestStartAdHoc_Host_MarkedAsHostAdHoc(t *testing.T) {
+	manager, _ := NewManager(nil, true)
+
+	if err := manager.StartAdHoc("switch", "", sundayMorning, time.Hour); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	window := manager.Active("switch", []string{"switches"}, sundayMorning.Add(time.Minute))
+
+	if window == nil || !window.HostAdHoc || !window.SuppressEvents {
+		t.Errorf("Expected the host's own ad-hoc window, got %v", window)
+	}
+}
@@ -0,0 +1,172 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with the standard five fields: minute, hour, day of month, month and
+// day of week.  Each field supports *, single values, ranges (a-b), steps (*/n or a-b/n) and comma separated
+// lists.  Day of week is 0-7, with both 0 and 7 meaning Sunday.  As in cron, when both day of month and day of
+// week are restricted, a time matches if either of them matches.
+type Schedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	domStar     bool
+	dowStar     bool
+}
+
+type fieldSpec struct {
+	name     string
+	minValue int
+	maxValue int
+}
+
+var fieldSpecs = [...]fieldSpec{
+	{name: "minute", minValue: 0, maxValue: 59},
+	{name: "hour", minValue: 0, maxValue: 23},
+	{name: "day of month", minValue: 1, maxValue: 31},
+	{name: "month", minValue: 1, maxValue: 12},
+	{name: "day of week", minValue: 0, maxValue: 7},
+}
+
+func ParseSchedule(expression string) (*Schedule, error) {
+	fields := strings.Fields(expression)
+
+	if len(fields) != len(fieldSpecs) {
+		return nil, fmt.Errorf("invalid schedule \"%s\": expected %d fields, got %d",
+			expression, len(fieldSpecs), len(fields))
+	}
+
+	var bits [len(fieldSpecs)]uint64
+
+	for i, field := range fields {
+		value, err := parseField(field, &fieldSpecs[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule \"%s\": %w", expression, err)
+		}
+
+		bits[i] = value
+	}
+
+	// Sunday can be specified as either 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minutes:     bits[0],
+		hours:       bits[1],
+		daysOfMonth: bits[2],
+		months:      bits[3],
+		daysOfWeek:  bits[4],
+		domStar:     strings.HasPrefix(fields[2], "*"),
+		dowStar:     strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseField(field string, spec *fieldSpec) (uint64, error) {
+	var result uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step \"%s\" in %s field", stepPart, spec.name)
+			}
+		}
+
+		low, high := spec.minValue, spec.maxValue
+
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			low, err = parseFieldValue(lowPart, spec)
+
+			if err != nil {
+				return 0, err
+			}
+
+			high = low
+
+			if isRange {
+				high, err = parseFieldValue(highPart, spec)
+
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "a/n" means from a to the end of the range, every n
+				high = spec.maxValue
+			}
+
+			if high < low {
+				return 0, fmt.Errorf("invalid range \"%s\" in %s field", rangePart, spec.name)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			result |= 1 << uint(value)
+		}
+	}
+
+	return result, nil
+}
+
+func parseFieldValue(value string, spec *fieldSpec) (int, error) {
+	result, err := strconv.Atoi(value)
+
+	if err != nil || result < spec.minValue || result > spec.maxValue {
+		return 0, fmt.Errorf("invalid value \"%s\" in %s field, expected %d-%d",
+			value, spec.name, spec.minValue, spec.maxValue)
+	}
+
+	return result, nil
+}
+
+// Matches returns true if the minute containing t is one of the scheduled minutes.  The caller is responsible for
+// converting t to the schedule's time zone.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minutes&(1<<uint(t.Minute())) == 0 ||
+		s.hours&(1<<uint(t.Hour())) == 0 ||
+		s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// MostRecentStart returns the latest scheduled minute at or before t, searching back no further than the
+// given limit.  Returns false if there is no such minute.
+func (s *Schedule) MostRecentStart(t time.Time, limit time.Duration) (time.Time, bool) {
+	candidate := t.Truncate(time.Minute)
+	earliest := t.Add(-limit)
+
+	for !candidate.Before(earliest) {
+		if s.Matches(candidate) {
+			return candidate, true
+		}
+
+		candidate = candidate.Add(-time.Minute)
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,64 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_InvalidExpressions_ReturnError(t *testing.T) {
+	for _, expression := range []string{"", "0 3 * *", "60 * * * *", "0 3 * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(expression); err == nil {
+			t.Errorf("Expected error for \"%s\"", expression)
+		}
+	}
+}
+
+func TestMatches_SundayAsZeroOrSeven_Matches(t *testing.T) {
+	sunday := time.Date(2023, 10, 15, 3, 0, 0, 0, time.UTC)
+
+	for _, expression := range []string{"0 3 * * 0", "0 3 * * 7", "0 3 * * 5-7", "*/15 1-5 * * *"} {
+		schedule, err := ParseSchedule(expression)
+
+		if err != nil {
+			t.Fatalf("Unexpected error for \"%s\": %v", expression, err)
+		}
+
+		if !schedule.Matches(sunday) {
+			t.Errorf("Expected \"%s\" to match %v", expression, sunday)
+		}
+
+		if schedule.Matches(sunday.Add(24 * time.Hour).Add(time.Minute)) {
+			t.Errorf("Expected \"%s\" not to match %v", expression, sunday.Add(24*time.Hour).Add(time.Minute))
+		}
+	}
+}
+
+func TestMatches_DayOfMonthAndDayOfWeek_EitherMatches(t *testing.T) {
+	schedule, _ := ParseSchedule("0 0 1 * 1")
+	firstOfMonth := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2023, 10, 9, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2023, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	if !schedule.Matches(firstOfMonth) || !schedule.Matches(monday) {
+		t.Errorf("Expected both the first of the month and a Monday to match")
+	}
+
+	if schedule.Matches(tuesday) {
+		t.Errorf("Expected %v not to match", tuesday)
+	}
+}
+
+func TestMostRecentStart_WithinLimit_ReturnsStart(t *testing.T) {
+	schedule, _ := ParseSchedule("0 3 * * 0")
+	now := time.Date(2023, 10, 15, 3, 42, 10, 0, time.UTC)
+
+	start, ok := schedule.MostRecentStart(now, time.Hour)
+
+	if !ok || !start.Equal(time.Date(2023, 10, 15, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 03:00, got %v, %v", start, ok)
+	}
+
+	if _, ok := schedule.MostRecentStart(now, 30*time.Minute); ok {
+		t.Errorf("Expected no start within 30 minutes")
+	}
+}
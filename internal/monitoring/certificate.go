@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+)
+
+// certificateCollector retrieves the expiry time of the certificate presented by the host's configured TLS
+// service.
+type certificateCollector struct {
+	target *Target
+}
+
+func newCertificateCollector(target *Target) Collector {
+	return &certificateCollector{target: target}
+}
+
+func (c *certificateCollector) Name() string {
+	return common.CollectorCertificate
+}
+
+func (c *certificateCollector) Enabled() bool {
+	return c.target.Host.CertificateCheckAddress() != ""
+}
+
+func (c *certificateCollector) Interval() time.Duration {
+	return 0
+}
+
+// Collect retrieves the certificate.  The certificate is not verified, since an untrusted or expired certificate
+// still has an expiry time worth reporting.
+func (c *certificateCollector) Collect(ctx context.Context, data *common.HostData) error {
+	address := c.target.Host.CertificateCheckAddress()
+	serverName, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		data.CertificateStatus = fmt.Sprintf("Invalid address: %s", err)
+		return err
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+		Config: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Unable to connect to %s: %v\n", c.target.Name, address, err)
+		data.CertificateStatus = fmt.Sprintf("Unable to connect: %v", err)
+		return err
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			fmt.Printf("monitoring task [%s]: Error closing connection: %v\n", c.target.Name, err)
+		}
+	}()
+
+	certificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+
+	if len(certificates) == 0 {
+		data.CertificateStatus = "No certificate"
+		return errors.New("no certificate")
+	}
+
+	data.CertificateStatus = "OK"
+	data.CertificateExpiryTime = certificates[0].NotAfter
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+)
+
+// Target is the host a monitoring task collects data from.  Collectors can record their own metrics, such as
+// retries, in Metrics.
+type Target struct {
+	Name    string
+	Address string
+	Host    *host.Host
+	Metrics *Metrics
+}
+
+// Collector retrieves one kind of data about a host, such as its ping response or its SNMP data, and stores it in
+// the scan result.  Each monitoring task creates its own collectors, so a collector can keep state between scans.
+// Collectors are only called from the task's goroutine.
+type Collector interface {
+	// Name identifies the collector in the host configuration and in the scan result.
+	Name() string
+
+	// Enabled returns true if the host's settings call for the collector.
+	Enabled() bool
+
+	// Interval returns the minimum time between runs.  Zero runs the collector with every scan.
+	Interval() time.Duration
+
+	// Collect retrieves the data and stores it in data.  The returned error is reported as the collector's
+	// result.
+	Collect(ctx context.Context, data *common.HostData) error
+}
+
+// CollectorFactory creates a collector for a monitoring task's target.
+type CollectorFactory func(target *Target) Collector
+
+type registeredCollector struct {
+	name    string
+	factory CollectorFactory
+}
+
+var collectorRegistryMutex sync.Mutex
+var collectorRegistry = []registeredCollector{
+	{name: common.CollectorPing, factory: newPingCollector},
+	{name: common.CollectorSnmp, factory: newSnmpCollector},
+	{name: common.CollectorCertificate, factory: newCertificateCollector},
+}
+
+// RegisterCollector adds a collector to the ones created by monitoring tasks.  Collectors run in the order they
+// were registered, after the built-in ones.  Registering an existing name replaces its factory.  Tasks that are
+// already running aren't affected.
+func RegisterCollector(name string, factory CollectorFactory) {
+	collectorRegistryMutex.Lock()
+	defer collectorRegistryMutex.Unlock()
+
+	index := slices.IndexFunc(collectorRegistry, func(entry registeredCollector) bool { return entry.name == name })
+
+	if index >= 0 {
+		collectorRegistry[index].factory = factory
+		return
+	}
+
+	collectorRegistry = append(collectorRegistry, registeredCollector{name: name, factory: factory})
+}
+
+// createCollectors creates an instance of each registered collector for the target.
+func createCollectors(target *Target) []Collector {
+	collectorRegistryMutex.Lock()
+	defer collectorRegistryMutex.Unlock()
+
+	result := make([]Collector, len(collectorRegistry))
+
+	for i, entry := range collectorRegistry {
+		result[i] = entry.factory(target)
+	}
+
+	return result
+}
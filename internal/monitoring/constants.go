@@ -4,33 +4,41 @@ package monitoring
 const oidSysUptime = ".1.3.6.1.2.1.1.3.0"
 
 // https://mibs.observium.org/mib/IF-MIB/#ifTable
+// Only the columns we use are walked, so the OIDs below are those of the columns, without an instance.  The instance
+// of every column is the ifIndex.
 const oidIfTable = ".1.3.6.1.2.1.2.2"
-const oidIfTableIfIndex = ".1.3.6.1.2.1.2.2.1.1."
-const oidIfTableIfDescr = ".1.3.6.1.2.1.2.2.1.2."
-const oidIfTableIfInOctets = ".1.3.6.1.2.1.2.2.1.10."
-const oidIfTableIfInUcastPkts = ".1.3.6.1.2.1.2.2.1.11."
-const oidIfTableIfOutOctets = ".1.3.6.1.2.1.2.2.1.16."
-const oidIfTableIfOutUcastPkts = ".1.3.6.1.2.1.2.2.1.17."
-const oidIfTableIfInErrors = ".1.3.6.1.2.1.2.2.1.14."
-const oidIfTableIfOutErrors = ".1.3.6.1.2.1.2.2.1.20."
-const oidIfTableIfInDiscards = ".1.3.6.1.2.1.2.2.1.15."
-const oidIfTableIfOutDiscards = ".1.3.6.1.2.1.2.2.1.21."
-const oidIfTableIfMtu = ".1.3.6.1.2.1.2.2.1.4."
-const oidIfTableIfSpeed = ".1.3.6.1.2.1.2.2.1.5."
-const oidIfTableIfPhysAddress = ".1.3.6.1.2.1.2.2.1.6."
-const oidIfTableIfAdminStatus = ".1.3.6.1.2.1.2.2.1.7."
-const oidIfTableIfOperStatus = ".1.3.6.1.2.1.2.2.1.8."
-const oidIfTableIfLastChange = ".1.3.6.1.2.1.2.2.1.9."
+const oidIfTableIfIndex = ".1.3.6.1.2.1.2.2.1.1"
+const oidIfTableIfDescr = ".1.3.6.1.2.1.2.2.1.2"
+const oidIfTableIfInOctets = ".1.3.6.1.2.1.2.2.1.10"
+const oidIfTableIfInUcastPkts = ".1.3.6.1.2.1.2.2.1.11"
+const oidIfTableIfOutOctets = ".1.3.6.1.2.1.2.2.1.16"
+const oidIfTableIfOutUcastPkts = ".1.3.6.1.2.1.2.2.1.17"
+const oidIfTableIfInErrors = ".1.3.6.1.2.1.2.2.1.14"
+const oidIfTableIfOutErrors = ".1.3.6.1.2.1.2.2.1.20"
+const oidIfTableIfInDiscards = ".1.3.6.1.2.1.2.2.1.15"
+const oidIfTableIfOutDiscards = ".1.3.6.1.2.1.2.2.1.21"
+const oidIfTableIfMtu = ".1.3.6.1.2.1.2.2.1.4"
+const oidIfTableIfSpeed = ".1.3.6.1.2.1.2.2.1.5"
+const oidIfTableIfPhysAddress = ".1.3.6.1.2.1.2.2.1.6"
+const oidIfTableIfAdminStatus = ".1.3.6.1.2.1.2.2.1.7"
+const oidIfTableIfOperStatus = ".1.3.6.1.2.1.2.2.1.8"
+const oidIfTableIfLastChange = ".1.3.6.1.2.1.2.2.1.9"
 
 const oidIfXTable = ".1.3.6.1.2.1.31.1.1"
-const oidIfXTableIfHCInOctets = ".1.3.6.1.2.1.31.1.1.1.6."
-const oidIfXTableIfHCInUcastPkts = ".1.3.6.1.2.1.31.1.1.1.7."
-const oidIfXTableIfHCInMulticastPkts = ".1.3.6.1.2.1.31.1.1.1.8."
-const oidIfXTableIfHCInBroadcastPkts = ".1.3.6.1.2.1.31.1.1.1.9."
-const oidIfXTableIfHCOutOctets = ".1.3.6.1.2.1.31.1.1.1.10."
-const oidIfXTableIfHCOutUcastPkts = ".1.3.6.1.2.1.31.1.1.1.11."
-const oidIfXTableIfHCOutMulticastPkts = ".1.3.6.1.2.1.31.1.1.1.12."
-const oidIfXTableIfHCOutBroadcastPkts = ".1.3.6.1.2.1.31.1.1.1.13."
+const oidIfXTableIfHCInOctets = ".1.3.6.1.2.1.31.1.1.1.6"
+const oidIfXTableIfHCInUcastPkts = ".1.3.6.1.2.1.31.1.1.1.7"
+const oidIfXTableIfHCInMulticastPkts = ".1.3.6.1.2.1.31.1.1.1.8"
+const oidIfXTableIfHCInBroadcastPkts = ".1.3.6.1.2.1.31.1.1.1.9"
+const oidIfXTableIfHCOutOctets = ".1.3.6.1.2.1.31.1.1.1.10"
+const oidIfXTableIfHCOutUcastPkts = ".1.3.6.1.2.1.31.1.1.1.11"
+const oidIfXTableIfHCOutMulticastPkts = ".1.3.6.1.2.1.31.1.1.1.12"
+const oidIfXTableIfHCOutBroadcastPkts = ".1.3.6.1.2.1.31.1.1.1.13"
+const oidIfXTableIfHighSpeed = ".1.3.6.1.2.1.31.1.1.1.15"
+
+// EtherLike-MIB, https://mibs.observium.org/mib/EtherLike-MIB/#dot3StatsTable
+// dot3StatsIndex identifies the same interface as the same value of ifIndex.  Only the duplex status column is
+// walked, the rest of the table holds error counters we don't use.
+const oidDot3StatsDuplexStatus = ".1.3.6.1.2.1.10.7.2.1.19"
 
 const oidIpAddrTable = ".1.3.6.1.2.1.4.20"
 const oidIpAddrTableIpAddEntAddr = ".1.3.6.1.2.1.4.20.1.1."
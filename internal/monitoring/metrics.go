@@ -0,0 +1,180 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+// Metrics accumulates the monitoring tasks' measurements of themselves.  It is shared by the tasks and their
+// collectors, and is thread safe.  The methods of a nil Metrics do nothing, so collectors can record metrics
+// whether or not they're given one.
+type Metrics struct {
+	mutex           sync.Mutex
+	hosts           map[string]*data.HostScanMetrics
+	collectors      map[string]*data.CollectorMetrics
+	enqueueFailures int
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		hosts:      make(map[string]*data.HostScanMetrics),
+		collectors: make(map[string]*data.CollectorMetrics),
+	}
+}
+
+// RecordScan records a completed scan of the host, and the scheduled scans it caused to be skipped.
+func (m *Metrics) RecordScan(hostName string, duration time.Duration, skippedTicks int, overrun bool) {
+	m.updateHost(hostName, func(host *data.HostScanMetrics) {
+		host.Scans++
+		host.LastScanDuration = duration
+		host.MaxScanDuration = max(host.MaxScanDuration, duration)
+		host.SkippedTicks += skippedTicks
+
+		if overrun {
+			host.Overruns++
+		}
+	})
+}
+
+// RecordCollection records a run of the collector.  Errors caused by timeouts are counted as timeouts, as well.
+func (m *Metrics) RecordCollection(collectorName string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	collector, ok := m.collectors[collectorName]
+
+	if !ok {
+		collector = &data.CollectorMetrics{Name: collectorName}
+		m.collectors[collectorName] = collector
+	}
+
+	collector.Runs++
+	collector.TotalDuration += duration
+	collector.MaxDuration = max(collector.MaxDuration, duration)
+
+	if err != nil {
+		collector.Errors++
+
+		if isTimeout(err) {
+			collector.Timeouts++
+		}
+	}
+}
+
+// RecordBulkWalkFallback records that SNMP bulk walks of the host failed, and it's walked one OID at a time.
+func (m *Metrics) RecordBulkWalkFallback(hostName string) {
+	m.updateHost(hostName, func(host *data.HostScanMetrics) { host.BulkWalkFallbacks++ })
+}
+
+// RecordSnmpPdus records the SNMP PDUs exchanged with the host, and the requests retried.
+func (m *Metrics) RecordSnmpPdus(hostName string, sent int, received int, retries int) {
+	m.updateHost(hostName, func(host *data.HostScanMetrics) {
+		host.SnmpPdusSent += sent
+		host.SnmpPdusReceived += received
+		host.SnmpRetries += retries
+	})
+}
+
+// RecordEnqueueFailure records a scan result that couldn't be passed to the plugin's goroutine.
+func (m *Metrics) RecordEnqueueFailure() {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.enqueueFailures++
+}
+
+func (m *Metrics) updateHost(hostName string, update func(host *data.HostScanMetrics)) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	host, ok := m.hosts[hostName]
+
+	if !ok {
+		host = &data.HostScanMetrics{Name: hostName}
+		m.hosts[hostName] = host
+	}
+
+	update(host)
+}
+
+// Status returns the plugin status built from the metrics and the scheduler's metrics.  Either may be nil, before
+// monitoring starts.
+func Status(metrics *Metrics, scheduler *Scheduler) data.PluginStatus {
+	status := data.PluginStatus{
+		Hosts:      make([]data.HostScanMetrics, 0),
+		Collectors: make([]data.CollectorMetrics, 0),
+	}
+
+	if scheduler != nil {
+		schedulerMetrics := scheduler.Metrics()
+		status.ActiveScans = schedulerMetrics.ActiveScans
+		status.QueuedScans = schedulerMetrics.QueuedScans
+		status.CompletedScans = schedulerMetrics.CompletedScans
+		status.AverageQueueWait = schedulerMetrics.AverageQueueWait()
+		status.MaxQueueWait = schedulerMetrics.MaxQueueWait
+		status.AverageScanDuration = schedulerMetrics.AverageScanDuration()
+		status.MaxScanDuration = schedulerMetrics.MaxScanDuration
+	}
+
+	if metrics == nil {
+		return status
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	status.EnqueueFailures = metrics.enqueueFailures
+
+	for _, name := range slices.Sorted(maps.Keys(metrics.hosts)) {
+		status.Hosts = append(status.Hosts, *metrics.hosts[name])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(metrics.collectors)) {
+		status.Collectors = append(status.Collectors, *metrics.collectors[name])
+	}
+
+	return status
+}
+
+// isTimeout returns true if the error was caused by a timeout.  gosnmp doesn't wrap its timeout errors, so they're
+// recognized by their message.
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(err.Error()), "timeout")
+}
+
+// skippedTicks returns the number of scheduled scans a scan that took the elapsed time caused to be skipped.  A
+// time.Ticker keeps one pending tick, and drops the rest, so a scan that overruns by less than an interval skips
+// none, but the next scan starts late.
+func skippedTicks(elapsed time.Duration, interval time.Duration) int {
+	if interval <= 0 || elapsed < 2*interval {
+		return 0
+	}
+
+	return int(elapsed/interval) - 1
+}
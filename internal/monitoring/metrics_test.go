@@ -0,0 +1,130 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+)
+
+func TestSkippedTicks_Elapsed_ReturnsDroppedTicks(t *testing.T) {
+	for _, testCase := range []struct {
+		elapsed  time.Duration
+		expected int
+	}{
+		{30 * time.Second, 0},
+		{90 * time.Second, 0},
+		{150 * time.Second, 1},
+		{300 * time.Second, 4},
+	} {
+		if actual := skippedTicks(testCase.elapsed, time.Minute); actual != testCase.expected {
+			t.Errorf("Expected %d skipped ticks for %v, got %d", testCase.expected, testCase.elapsed, actual)
+		}
+	}
+}
+
+func TestIsTimeout_Errors_RecognizesTimeouts(t *testing.T) {
+	for _, testCase := range []struct {
+		err      error
+		expected bool
+	}{
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("walk: %w", context.DeadlineExceeded), true},
+		{errors.New("request timeout (after 3 retries)"), true},
+		{errors.New("connection refused"), false},
+	} {
+		if actual := isTimeout(testCase.err); actual != testCase.expected {
+			t.Errorf("Expected %v for %v, got %v", testCase.expected, testCase.err, actual)
+		}
+	}
+}
+
+func TestStatus_RecordedMetrics_SortedByName(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.RecordCollection("snmp", 2*time.Second, errors.New("request timeout"))
+	metrics.RecordCollection("ping", time.Second, nil)
+	metrics.RecordCollection("ping", 3*time.Second, nil)
+	metrics.RecordScan("switch", 5*time.Second, 0, false)
+	metrics.RecordScan("router", 150*time.Second, 1, true)
+	metrics.RecordBulkWalkFallback("router")
+	metrics.RecordSnmpPdus("router", 10, 9, 1)
+	metrics.RecordEnqueueFailure()
+
+	status := Status(metrics, nil)
+
+	if len(status.Collectors) != 2 || status.Collectors[0].Name != "ping" || status.Collectors[1].Name != "snmp" {
+		t.Fatalf("Expected collectors ping and snmp, got %+v", status.Collectors)
+	}
+
+	if ping := status.Collectors[0]; ping.Runs != 2 || ping.AverageDuration() != 2*time.Second ||
+		ping.MaxDuration != 3*time.Second {
+		t.Errorf("Expected 2 ping runs averaging 2s, max 3s, got %+v", ping)
+	}
+
+	if snmp := status.Collectors[1]; snmp.Errors != 1 || snmp.Timeouts != 1 {
+		t.Errorf("Expected 1 snmp error and timeout, got %+v", snmp)
+	}
+
+	if len(status.Hosts) != 2 || status.Hosts[0].Name != "router" {
+		t.Fatalf("Expected hosts router and switch, got %+v", status.Hosts)
+	}
+
+	router := status.Hosts[0]
+
+	if router.Overruns != 1 || router.SkippedTicks != 1 || router.BulkWalkFallbacks != 1 ||
+		router.SnmpPdusSent != 10 || router.SnmpPdusReceived != 9 || router.SnmpRetries != 1 {
+		t.Errorf("Expected the router's overrun, fallback and PDUs, got %+v", router)
+	}
+
+	if status.EnqueueFailures != 1 || status.SkippedTicks() != 1 || status.Timeouts() != 1 {
+		t.Errorf("Expected 1 enqueue failure, skipped tick and timeout, got %d, %d and %d",
+			status.EnqueueFailures, status.SkippedTicks(), status.Timeouts())
+	}
+}
+
+func TestMetrics_Nil_RecordsNothing(t *testing.T) {
+	var metrics *Metrics
+	metrics.RecordScan("router", time.Second, 0, false)
+	metrics.RecordCollection("ping", time.Second, nil)
+	metrics.RecordBulkWalkFallback("router")
+	metrics.RecordEnqueueFailure()
+
+	if status := Status(metrics, nil); len(status.Hosts) != 0 || len(status.Collectors) != 0 {
+		t.Errorf("Expected an empty status, got %+v", status)
+	}
+}
+
+func TestRecordScan_Overrun_RecordsSkippedTicks(t *testing.T) {
+	task, _ := newTestTask(config.Host{})
+
+	task.recordScan(30 * time.Second)
+	task.recordScan(150 * time.Second)
+
+	status := Status(task.metrics, nil)
+
+	if len(status.Hosts) != 1 || status.Hosts[0].Overruns != 1 || status.Hosts[0].SkippedTicks != 1 {
+		t.Errorf("Expected 1 overrun and 1 skipped tick, got %+v", status.Hosts)
+	}
+}
+
+func TestScan_Collectors_RecordsCollectorMetrics(t *testing.T) {
+	failing := &fakeCollector{name: "failing", enabled: true, err: errors.New("refused")}
+	task, _ := newTestTask(config.Host{}, failing)
+	task.clock = common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+
+	task.scan(false)
+
+	status := Status(task.metrics, task.scheduler)
+
+	if len(status.Collectors) != 1 || status.Collectors[0].Runs != 1 || status.Collectors[0].Errors != 1 {
+		t.Errorf("Expected 1 failed run, got %+v", status.Collectors)
+	}
+
+	if status.CompletedScans != 1 {
+		t.Errorf("Expected 1 completed scan, got %d", status.CompletedScans)
+	}
+}
@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	probing "github.com/prometheus-community/pro-bing"
+)
+
+// Pinger sends a series of pings to a host and reports the statistics.  probing.Pinger implements it.
+type Pinger interface {
+	Run() error
+	Stop()
+	Statistics() *probing.Statistics
+}
+
+// PingerFactory creates a pinger that sends count pings to the address, and gives up after the timeout.
+type PingerFactory func(address string, count int, timeout time.Duration, privileged bool) (Pinger, error)
+
+func newProbingPinger(address string, count int, timeout time.Duration, privileged bool) (Pinger, error) {
+	pinger, err := probing.NewPinger(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pinger.Count = count
+	pinger.Timeout = timeout
+
+	if privileged {
+		pinger.SetPrivileged(true)
+	}
+
+	return pinger, nil
+}
+
+// pingCollector pings the host, using the host's ping settings.
+type pingCollector struct {
+	target    *Target
+	newPinger PingerFactory
+}
+
+func newPingCollector(target *Target) Collector {
+	return NewPingCollector(target, newProbingPinger)
+}
+
+// NewPingCollector creates a ping collector that sends pings through pingers created by newPinger.  Register it
+// in place of the built-in ping collector to replace the pinger.
+func NewPingCollector(target *Target, newPinger PingerFactory) Collector {
+	return &pingCollector{target: target, newPinger: newPinger}
+}
+
+func (c *pingCollector) Name() string {
+	return common.CollectorPing
+}
+
+func (c *pingCollector) Enabled() bool {
+	return c.target.Host.PingEnabled()
+}
+
+func (c *pingCollector) Interval() time.Duration {
+	return 0
+}
+
+// Collect pings the host.  A host that doesn't respond is a successful collection with 100% packet loss.
+func (c *pingCollector) Collect(ctx context.Context, data *common.HostData) error {
+	fmt.Printf("monitoring task [%s]: Pinging with %d packets %d second timeout\n",
+		c.target.Name, c.target.Host.PingCount(), c.target.Host.PingTimeoutSeconds())
+	pinger, cancelFn, err := c.createPinger(ctx, c.target.Address)
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Failed to create pinger: %s\n", c.target.Name, err)
+		data.PingStatus = fmt.Sprintf("Unable to ping: %s", err)
+		return err
+	}
+
+	defer cancelFn()
+	err = pinger.Run()
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Failed to ping: %s\n", c.target.Name, err)
+		data.PingStatus = fmt.Sprintf("Unable to ping: %s", err)
+		return err
+	}
+
+	stats := pinger.Statistics()
+
+	if stats.PacketsSent == 0 {
+		data.PingStatus = "Cancelled"
+		return errors.New("cancelled")
+	}
+
+	data.PingPacketsSent = stats.PacketsSent
+
+	if stats.PacketLoss >= 100 {
+		data.PingStatus = "Timeout"
+	} else {
+		data.PingStatus = "OK"
+	}
+
+	data.PingPacketLoss = stats.PacketLoss
+	data.PingRttAvg = stats.AvgRtt
+	data.PingRttMin = stats.MinRtt
+	data.PingRttMax = stats.MaxRtt
+	data.PingRttStdDev = stats.StdDevRtt
+
+	return nil
+}
+
+func (c *pingCollector) createPinger(ctx context.Context, targetAddress string) (Pinger, context.CancelFunc, error) {
+	pinger, err := c.newPinger(
+		targetAddress,
+		c.target.Host.PingCount(),
+		time.Duration(c.target.Host.PingTimeoutSeconds())*time.Second,
+		c.target.Host.PingUseIcmp())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create a child context that will be marked done either via the task's context or
+	// via the cancel function that must be invoked by the caller
+	ctx, cancelFn := context.WithCancel(ctx)
+
+	go func() {
+		<-ctx.Done()
+		// Stop is idempotent.  It can be called when the pinger completes Run naturally,
+		// with no ill effect.
+		pinger.Stop()
+	}()
+
+	return pinger, cancelFn, nil
+}
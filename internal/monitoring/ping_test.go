@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+	"github.com/avanha/pmaas-spi/tracking"
+	probing "github.com/prometheus-community/pro-bing"
+)
+
+type fakePinger struct {
+	statistics probing.Statistics
+	err        error
+}
+
+func (p *fakePinger) Run() error {
+	return p.err
+}
+
+func (p *fakePinger) Stop() {
+}
+
+func (p *fakePinger) Statistics() *probing.Statistics {
+	return &p.statistics
+}
+
+func newTestPingCollector(pinger *fakePinger, requests *[]string) Collector {
+	hostInstance := host.NewHost("Host_1", config.Host{
+		Name:               "test",
+		IpAddress:          "192.168.1.1",
+		PingEnabled:        true,
+		PingCount:          4,
+		PingTimeoutSeconds: 10,
+	}, tracking.Config{}, nil)
+	target := &Target{Name: "test", Address: hostInstance.IpAddress(), Host: hostInstance}
+
+	newPinger := func(address string, count int, timeout time.Duration, privileged bool) (Pinger, error) {
+		*requests = append(*requests, address)
+
+		if count != 4 || timeout != 10*time.Second || privileged {
+			return nil, errors.New("unexpected settings")
+		}
+
+		return pinger, nil
+	}
+
+	return NewPingCollector(target, newPinger)
+}
+
+func TestPingCollectorCollect_Responds_RecordsStatistics(t *testing.T) {
+	requests := make([]string, 0)
+	collector := newTestPingCollector(&fakePinger{statistics: probing.Statistics{
+		PacketsSent: 4,
+		PacketsRecv: 3,
+		PacketLoss:  25.0,
+		AvgRtt:      10 * time.Millisecond,
+		MaxRtt:      20 * time.Millisecond,
+	}}, &requests)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(requests) != 1 || requests[0] != "192.168.1.1" {
+		t.Errorf("Expected a pinger for 192.168.1.1, got %v", requests)
+	}
+
+	if data.PingStatus != "OK" || data.PingPacketsSent != 4 || data.PingPacketLoss != 25.0 {
+		t.Errorf("Expected the statistics, got %q, %d, %f", data.PingStatus, data.PingPacketsSent, data.PingPacketLoss)
+	}
+
+	if data.PingRttAvg != 10*time.Millisecond || data.PingRttMax != 20*time.Millisecond {
+		t.Errorf("Expected the round trip times, got %v, %v", data.PingRttAvg, data.PingRttMax)
+	}
+}
+
+func TestPingCollectorCollect_NoResponse_ReportsTimeout(t *testing.T) {
+	requests := make([]string, 0)
+	collector := newTestPingCollector(&fakePinger{statistics: probing.Statistics{
+		PacketsSent: 4,
+		PacketLoss:  100.0,
+	}}, &requests)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if data.PingStatus != "Timeout" || data.PingPacketLoss != 100.0 {
+		t.Errorf("Expected a timeout, got %q, %f", data.PingStatus, data.PingPacketLoss)
+	}
+}
+
+func TestPingCollectorCollect_RunFails_ReturnsError(t *testing.T) {
+	requests := make([]string, 0)
+	collector := newTestPingCollector(&fakePinger{err: errors.New("permission denied")}, &requests)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err == nil || data.PingStatus != "Unable to ping: permission denied" || data.PingPacketsSent != 0 {
+		t.Errorf("Expected an error, got %q (%v)", data.PingStatus, err)
+	}
+}
@@ -0,0 +1,214 @@
+package monitoring
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+)
+
+// SchedulerLimits are the maximum numbers of scans, and of ping and SNMP collections, that run at the same time.
+// Zero means no limit.
+type SchedulerLimits struct {
+	Scans     int
+	Pings     int
+	SnmpWalks int
+}
+
+// SchedulerMetrics describes the scans the scheduler ran.  Queue wait is the time a scan waited for its scan slot
+// and for its collectors' slots.
+type SchedulerMetrics struct {
+	ActiveScans       int
+	QueuedScans       int
+	CompletedScans    int
+	TotalQueueWait    time.Duration
+	MaxQueueWait      time.Duration
+	TotalScanDuration time.Duration
+	MaxScanDuration   time.Duration
+}
+
+// AverageQueueWait returns the mean queue wait of the completed scans.
+func (m SchedulerMetrics) AverageQueueWait() time.Duration {
+	if m.CompletedScans == 0 {
+		return 0
+	}
+
+	return m.TotalQueueWait / time.Duration(m.CompletedScans)
+}
+
+// AverageScanDuration returns the mean duration of the completed scans, excluding their queue wait.
+func (m SchedulerMetrics) AverageScanDuration() time.Duration {
+	if m.CompletedScans == 0 {
+		return 0
+	}
+
+	return m.TotalScanDuration / time.Duration(m.CompletedScans)
+}
+
+// Scheduler bounds the number of scans the monitoring tasks run at the same time.  Scans, and the collections
+// within them, wait in the order they were requested, so every host gets its turn.  It is shared by the tasks, and
+// is thread safe.
+type Scheduler struct {
+	scans             *fairLimiter
+	collectorLimiters map[string]*fairLimiter
+	clock             common.Clock
+	mutex             sync.Mutex
+	metrics           SchedulerMetrics
+}
+
+func NewScheduler(limits SchedulerLimits, clock common.Clock) *Scheduler {
+	return &Scheduler{
+		scans: newFairLimiter(limits.Scans),
+		collectorLimiters: map[string]*fairLimiter{
+			common.CollectorPing: newFairLimiter(limits.Pings),
+			common.CollectorSnmp: newFairLimiter(limits.SnmpWalks),
+		},
+		clock: clock,
+	}
+}
+
+// Metrics returns a snapshot of the scheduler's metrics.
+func (s *Scheduler) Metrics() SchedulerMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.metrics
+}
+
+// runScan waits for a scan slot, then calls scan with a function that runs collections within their limits.  It
+// returns false, without calling scan, if the context is cancelled while waiting.
+func (s *Scheduler) runScan(ctx context.Context, scan func(collect collectFunc)) bool {
+	queuedTime := s.clock.Now()
+	s.updateMetrics(func(m *SchedulerMetrics) { m.QueuedScans++ })
+
+	if err := s.scans.acquire(ctx); err != nil {
+		s.updateMetrics(func(m *SchedulerMetrics) { m.QueuedScans-- })
+		return false
+	}
+
+	defer s.scans.release()
+
+	startTime := s.clock.Now()
+	scanQueueWait := startTime.Sub(queuedTime)
+	collectorQueueWait := time.Duration(0)
+	s.updateMetrics(func(m *SchedulerMetrics) {
+		m.QueuedScans--
+		m.ActiveScans++
+	})
+
+	// The collections run one at a time on the task's goroutine
+	scan(func(name string, f func() error) error {
+		limiter := s.collectorLimiters[name]
+		collectorQueuedTime := s.clock.Now()
+
+		if err := limiter.acquire(ctx); err != nil {
+			return err
+		}
+
+		defer limiter.release()
+		collectorQueueWait += s.clock.Now().Sub(collectorQueuedTime)
+
+		return f()
+	})
+
+	queueWait := scanQueueWait + collectorQueueWait
+	duration := s.clock.Now().Sub(startTime) - collectorQueueWait
+	s.updateMetrics(func(m *SchedulerMetrics) {
+		m.ActiveScans--
+		m.CompletedScans++
+		m.TotalQueueWait += queueWait
+		m.MaxQueueWait = max(m.MaxQueueWait, queueWait)
+		m.TotalScanDuration += duration
+		m.MaxScanDuration = max(m.MaxScanDuration, duration)
+	})
+
+	return true
+}
+
+func (s *Scheduler) updateMetrics(update func(m *SchedulerMetrics)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	update(&s.metrics)
+}
+
+// collectFunc runs a collection, named after its collector, once the collector's limit allows it.  It returns the
+// collection's error, or the context's error if the context is cancelled while waiting.
+type collectFunc func(name string, f func() error) error
+
+// fairLimiter is a counting semaphore that grants its slots in the order they're requested.  A nil limiter has no
+// limit.
+type fairLimiter struct {
+	mutex    sync.Mutex
+	capacity int
+	inUse    int
+	waiters  []chan struct{}
+}
+
+func newFairLimiter(capacity int) *fairLimiter {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return &fairLimiter{capacity: capacity}
+}
+
+// acquire waits for a slot.  It returns the context's error if the context is cancelled first.
+func (l *fairLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+
+	if l.inUse < l.capacity && len(l.waiters) == 0 {
+		l.inUse++
+		l.mutex.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		index := slices.Index(l.waiters, ready)
+
+		if index >= 0 {
+			l.waiters = slices.Delete(l.waiters, index, index+1)
+			l.mutex.Unlock()
+			return ctx.Err()
+		}
+
+		l.mutex.Unlock()
+
+		// The slot was granted as the context was cancelled, so pass it on
+		l.release()
+
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it to the longest waiting request, if any.
+func (l *fairLimiter) release() {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.waiters) > 0 {
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+		return
+	}
+
+	l.inUse--
+}
@@ -0,0 +1,203 @@
+package monitoring
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+)
+
+// waitFor polls until the condition is true, or fails the test after a few seconds.
+func waitFor(t *testing.T, description string, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", description)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (l *fairLimiter) waiterCount() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.waiters)
+}
+
+func TestFairLimiter_Waiters_GrantedInRequestOrder(t *testing.T) {
+	limiter := newFairLimiter(1)
+	_ = limiter.acquire(context.Background())
+	granted := make(chan int, 3)
+	var waiters sync.WaitGroup
+
+	for i := range 3 {
+		waiters.Go(func() {
+			_ = limiter.acquire(context.Background())
+			granted <- i
+			limiter.release()
+		})
+		waitFor(t, "the waiter to queue", func() bool { return limiter.waiterCount() == i+1 })
+	}
+
+	limiter.release()
+	waiters.Wait()
+	close(granted)
+	order := make([]int, 0, 3)
+
+	for i := range granted {
+		order = append(order, i)
+	}
+
+	if !slices.Equal(order, []int{0, 1, 2}) {
+		t.Errorf("Expected grants in order [0 1 2], got %v", order)
+	}
+}
+
+func TestFairLimiter_CancelledWaiter_RemovedFromQueue(t *testing.T) {
+	limiter := newFairLimiter(1)
+	_ = limiter.acquire(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+
+	go func() { result <- limiter.acquire(ctx) }()
+	waitFor(t, "the waiter to queue", func() bool { return limiter.waiterCount() == 1 })
+	cancel()
+
+	if err := <-result; err == nil {
+		t.Errorf("Expected an error for a cancelled wait")
+	}
+
+	limiter.release()
+
+	if err := limiter.acquire(context.Background()); err != nil {
+		t.Errorf("Expected the released slot to be available, got %v", err)
+	}
+}
+
+func TestNewFairLimiter_ZeroCapacity_Unlimited(t *testing.T) {
+	limiter := newFairLimiter(0)
+
+	for range 100 {
+		if err := limiter.acquire(context.Background()); err != nil {
+			t.Fatalf("Expected no limit, got %v", err)
+		}
+	}
+
+	limiter.release()
+}
+
+// runConcurrentScans runs the scans at the same time, each calling collect with the collector name, and returns
+// the maximum number of collections that ran at once.
+func runConcurrentScans(scheduler *Scheduler, scans int, collectorName string) int {
+	var running, maxRunning atomic.Int32
+	var scanners sync.WaitGroup
+
+	for range scans {
+		scanners.Go(func() {
+			scheduler.runScan(context.Background(), func(collect collectFunc) {
+				_ = collect(collectorName, func() error {
+					current := running.Add(1)
+
+					for {
+						observed := maxRunning.Load()
+
+						if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+							break
+						}
+					}
+
+					time.Sleep(5 * time.Millisecond)
+					running.Add(-1)
+
+					return nil
+				})
+			})
+		})
+	}
+
+	scanners.Wait()
+
+	return int(maxRunning.Load())
+}
+
+func TestRunScan_ScanLimit_BoundsConcurrentScans(t *testing.T) {
+	scheduler := NewScheduler(SchedulerLimits{Scans: 2}, common.SystemClock)
+
+	if maxRunning := runConcurrentScans(scheduler, 8, "other"); maxRunning > 2 {
+		t.Errorf("Expected at most 2 concurrent scans, got %d", maxRunning)
+	}
+
+	if completed := scheduler.Metrics().CompletedScans; completed != 8 {
+		t.Errorf("Expected 8 completed scans, got %d", completed)
+	}
+}
+
+func TestRunScan_PingLimit_BoundsConcurrentPings(t *testing.T) {
+	scheduler := NewScheduler(SchedulerLimits{Pings: 1}, common.SystemClock)
+
+	if maxRunning := runConcurrentScans(scheduler, 4, common.CollectorPing); maxRunning != 1 {
+		t.Errorf("Expected 1 concurrent ping, got %d", maxRunning)
+	}
+}
+
+func TestRunScan_QueuedScan_RecordsQueueWaitAndDuration(t *testing.T) {
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	scheduler := NewScheduler(SchedulerLimits{Scans: 1}, clock)
+	firstStarted := make(chan struct{})
+	releaseFirst := make(chan struct{})
+	var scanners sync.WaitGroup
+
+	scanners.Go(func() {
+		scheduler.runScan(context.Background(), func(_ collectFunc) {
+			close(firstStarted)
+			<-releaseFirst
+		})
+	})
+	<-firstStarted
+	scanners.Go(func() {
+		scheduler.runScan(context.Background(), func(_ collectFunc) { clock.Advance(time.Second) })
+	})
+	waitFor(t, "the second scan to queue", func() bool { return scheduler.Metrics().QueuedScans == 1 })
+
+	clock.Advance(2 * time.Second)
+	close(releaseFirst)
+	scanners.Wait()
+	metrics := scheduler.Metrics()
+
+	if metrics.CompletedScans != 2 || metrics.ActiveScans != 0 || metrics.QueuedScans != 0 {
+		t.Errorf("Expected 2 completed scans and none active or queued, got %+v", metrics)
+	}
+
+	if metrics.MaxQueueWait != 2*time.Second || metrics.AverageQueueWait() != time.Second {
+		t.Errorf("Expected a max queue wait of 2s and an average of 1s, got %v and %v",
+			metrics.MaxQueueWait, metrics.AverageQueueWait())
+	}
+
+	if metrics.MaxScanDuration != 2*time.Second || metrics.TotalScanDuration != 3*time.Second {
+		t.Errorf("Expected a max scan duration of 2s and a total of 3s, got %v and %v",
+			metrics.MaxScanDuration, metrics.TotalScanDuration)
+	}
+}
+
+func TestRunScan_CancelledWhileQueued_DoesNotScan(t *testing.T) {
+	scheduler := NewScheduler(SchedulerLimits{Scans: 1}, common.SystemClock)
+	_ = scheduler.scans.acquire(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	scanned := false
+
+	if scheduler.runScan(ctx, func(_ collectFunc) { scanned = true }) || scanned {
+		t.Errorf("Expected a cancelled scan not to run")
+	}
+
+	if queued := scheduler.Metrics().QueuedScans; queued != 0 {
+		t.Errorf("Expected no queued scans, got %d", queued)
+	}
+}
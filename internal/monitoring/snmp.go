@@ -0,0 +1,1145 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/gosnmp/gosnmp"
+)
+
+var oids = [...]string{
+	oidSysUptime, // Uptime
+}
+
+// maxBulkVariables is the number of variables a GetBulk request asks for, shared by the columns it walks.  It's the
+// max-repetitions gosnmp uses to bulk walk a single OID, so responses stay within the size devices handle.
+const maxBulkVariables = 50
+
+// maxInterfaceIndex is the largest interface index accepted, as a sanity check.
+const maxInterfaceIndex = 1000
+
+// fullInterfaceWalkInterval is the longest the collector polls the configured interfaces' rows without walking the
+// interface tables, to find interfaces that have appeared, and indexes that have changed.
+const fullInterfaceWalkInterval = 15 * time.Minute
+
+// snmpCollector retrieves the host's uptime, interfaces and addresses via SNMP.  It remembers whether the host
+// supports bulk walks, and the number of interfaces, between scans.  Once a walk of the interface tables has found
+// the host's configured interfaces, it only polls their rows, until the next full walk is due.
+type snmpCollector struct {
+	target             *Target
+	useBulkWalk        bool
+	lastInterfaceCount int
+	polledInterfaces   []polledInterface
+	lastFullWalkTime   time.Time
+	lastUptimeSeconds  uint64
+	port               uint16
+	timeout            time.Duration
+	retries            int
+}
+
+// polledInterface is a configured interface found by a walk of the interface tables.  Its name and physical address
+// confirm that its index still identifies it.
+type polledInterface struct {
+	index       int32
+	name        string
+	physAddress string
+}
+
+func newSnmpCollector(target *Target) Collector {
+	return &snmpCollector{
+		target:      target,
+		useBulkWalk: true,
+		port:        161,
+		timeout:     2 * time.Second,
+		retries:     3,
+	}
+}
+
+func (c *snmpCollector) Name() string {
+	return common.CollectorSnmp
+}
+
+func (c *snmpCollector) Enabled() bool {
+	return c.target.Host.SnmpEnabled()
+}
+
+func (c *snmpCollector) Interval() time.Duration {
+	return 0
+}
+
+func (c *snmpCollector) Collect(ctx context.Context, data *common.HostData) error {
+	fmt.Printf("monitoring task [%s]: Retrieving snmp data\n", c.target.Name)
+	target := &gosnmp.GoSNMP{
+		Context:            ctx,
+		Target:             c.target.Address,
+		Port:               c.port,
+		Transport:          "udp",
+		Community:          "public",
+		Version:            gosnmp.Version2c,
+		Timeout:            c.timeout,
+		Retries:            c.retries,
+		ExponentialTimeout: false,
+		MaxOids:            gosnmp.MaxOids,
+	}
+	pdusSent, pdusReceived, retries := 0, 0, 0
+	target.OnSent = func(*gosnmp.GoSNMP) { pdusSent++ }
+	target.OnRecv = func(*gosnmp.GoSNMP) { pdusReceived++ }
+	target.OnRetry = func(*gosnmp.GoSNMP) { retries++ }
+	defer func() { c.target.Metrics.RecordSnmpPdus(c.target.Name, pdusSent, pdusReceived, retries) }()
+	scanStartTime := time.Now()
+
+	if err := target.Connect(); err != nil {
+		fmt.Printf("monitoring task [%s]: Unable to connect: %v\n", c.target.Name, err)
+		data.SnmpStatus = fmt.Sprintf("Unable to connect: %v", err)
+		return err
+	}
+
+	defer func() {
+		if err := target.Close(); err != nil {
+			fmt.Printf("monitoring task [%s]: Error closing connection: %v\n", c.target.Name, err)
+		}
+	}()
+
+	uptimeSuccess := c.getUptime(target, data)
+	ifTableSuccess := false
+	walkInterfaces := true
+
+	if c.canPollInterfaces(uptimeSuccess, data.UptimeSeconds) {
+		var renumbered bool
+		ifTableSuccess, renumbered = c.getPolledInterfaces(target, data)
+		// If the poll failed without an answer, a walk would fail as well
+		walkInterfaces = renumbered
+	}
+
+	if uptimeSuccess {
+		c.lastUptimeSeconds = data.UptimeSeconds
+	}
+
+	if walkInterfaces {
+		ifTableSuccess = c.walkInterfaces(target, data)
+	}
+
+	if ifTableSuccess {
+		ipAddressTableSuccess := c.getIpAddressTable(target, data)
+
+		if !ipAddressTableSuccess {
+			// There's no need to get the deprecated IPv4-MIB::IpAddrTable if the host supports the
+			// newer IP-MIB::IpAddressTable
+			c.getIpAddrTable(target, data)
+		}
+	}
+
+	fmt.Printf("monitoring task [%s]: snmp walk completed in %v\n", c.target.Name, time.Since(scanStartTime))
+
+	// Store the count of interfaces so we have it for next time
+	c.lastInterfaceCount = len(data.IfDataList)
+
+	if !uptimeSuccess && !ifTableSuccess {
+		data.SnmpStatus = "Failed to retrieve any data"
+		return errors.New("failed to retrieve any data")
+	}
+
+	data.SnmpStatus = "OK"
+	data.SnmpSuccess = true
+
+	return nil
+}
+
+func (c *snmpCollector) getUptime(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	result, err := target.Get(oids[:])
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Error retrieving values: %v\n", c.target.Name, err)
+		return false
+	}
+
+	//fmt.Printf("monitoring task [%s]: retrieved values: %v\n", c.target.Name, result)
+
+	var hostUptimeSeconds uint64
+
+	for _, variable := range result.Variables {
+		switch variable.Name {
+		case oidSysUptime:
+			value := gosnmp.ToBigInt(variable.Value)
+
+			if value.IsUint64() {
+				hostUptimeSeconds = value.Uint64() / 100
+			}
+
+		default:
+			printSNMPData(variable)
+		}
+	}
+
+	data.UptimeSeconds = hostUptimeSeconds
+
+	return true
+}
+
+type ifDataSetter[T any] func(T, *common.IfData)
+
+type parserSpec struct {
+	valueType    int
+	stringSetter ifDataSetter[string]
+	int32Setter  ifDataSetter[int32]
+	uint32Setter ifDataSetter[uint32]
+	uint64Setter ifDataSetter[uint64]
+}
+
+var ifTableParserMap = map[string]*parserSpec{
+	oidIfTableIfDescr: {valueType: ValueTypeStringBytes, stringSetter: func(value string, data *common.IfData) {
+		data.Name = value
+	}},
+	oidIfTableIfInOctets: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.InOctets = value
+	}},
+	oidIfTableIfOutOctets: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.OutOctets = value
+	}},
+	oidIfTableIfInUcastPkts: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.InUcastPkts = value
+	}},
+	oidIfTableIfOutUcastPkts: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.OutUcastPkts = value
+	}},
+	oidIfTableIfInErrors: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.InErrors = value
+	}},
+	oidIfTableIfOutErrors: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.OutErrors = value
+	}},
+	oidIfTableIfInDiscards: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.InDiscards = value
+	}},
+	oidIfTableIfOutDiscards: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.OutDiscards = value
+	}},
+	oidIfTableIfMtu: {valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IfData) {
+		data.Mtu = value
+	}},
+	oidIfTableIfSpeed: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.Speed = value
+	}},
+	oidIfTableIfPhysAddress: {valueType: ValueTypePhysicalAddress, stringSetter: func(value string, data *common.IfData) {
+		data.PhysAddress = value
+	}},
+	oidIfTableIfAdminStatus: {valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IfData) {
+		data.AdminStatus = value
+	}},
+	oidIfTableIfOperStatus: {valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IfData) {
+		data.OperStatus = value
+	}},
+	oidIfTableIfLastChange: {valueType: ValueTypeTimeTicks, uint32Setter: func(value uint32, data *common.IfData) {
+		data.LastChangeSeconds = value
+	}},
+}
+
+// ifTableColumns are the ifTable columns walked: ifIndex, which defines the interfaces, and the parsed columns.
+var ifTableColumns = append([]string{oidIfTableIfIndex}, slices.Sorted(maps.Keys(ifTableParserMap))...)
+
+var ifXTableParserMap = map[string]*parserSpec{
+	oidIfXTableIfHCInOctets: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCInOctets = value
+	}},
+	oidIfXTableIfHCInUcastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCInUcastPkts = value
+	}},
+	oidIfXTableIfHCInMulticastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCInMulticastPkts = value
+	}},
+	oidIfXTableIfHCInBroadcastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCInBroadcastPkts = value
+	}},
+	oidIfXTableIfHCOutOctets: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCOutOctets = value
+	}},
+	oidIfXTableIfHCOutUcastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCOutUcastPkts = value
+	}},
+	oidIfXTableIfHCOutMulticastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCOutMulticastPkts = value
+	}},
+	oidIfXTableIfHCOutBroadcastPkts: {valueType: ValueTypeUint64, uint64Setter: func(value uint64, data *common.IfData) {
+		data.HCOutBroadcastPkts = value
+	}},
+	oidIfXTableIfHighSpeed: {valueType: ValueTypeUint32, uint32Setter: func(value uint32, data *common.IfData) {
+		data.HighSpeed = value
+	}},
+}
+
+var ifXTableColumns = slices.Sorted(maps.Keys(ifXTableParserMap))
+
+var dot3StatsTableParserMap = map[string]*parserSpec{
+	oidDot3StatsDuplexStatus: {valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IfData) {
+		data.DuplexStatus = value
+	}},
+}
+
+var dot3StatsTableColumns = slices.Sorted(maps.Keys(dot3StatsTableParserMap))
+
+// polledColumns are the columns retrieved for each of the configured interfaces, when only their rows are polled.
+var polledColumns = slices.Concat(ifTableColumns, ifXTableColumns, dot3StatsTableColumns)
+
+type ipAddressMapEntrySetter[T any] func(T, *common.IpMapEntry)
+
+type ipAddressTableParserSpec struct {
+	valueType    int
+	int32Setter  ipAddressMapEntrySetter[int32]
+	stringSetter ipAddressMapEntrySetter[string]
+}
+
+var ipAddressTableParserMap = map[string]*ipAddressTableParserSpec{
+	oidIpAddressTableIpAddressIfIndex: {
+		valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.IfIndex = value
+		},
+	},
+	oidIpAddressTableIpAddressOrigin: {
+		valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.Origin = value
+		},
+	},
+	oidIpAddressTableIpAddressType: {
+		valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.Type = value
+		},
+	},
+	oidIpAddressTableIpAddressPrefix: {
+		valueType: ValueTypeString, stringSetter: func(value string, data *common.IpMapEntry) {
+			data.PrefixTableIndex = value
+		},
+	},
+	oidIpAddressTableIpAddressStatus: {
+		valueType: ValueTypeInt32, int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.Status = value
+		},
+	},
+}
+
+var ipV4AddrTableParserMap = map[string]*ipAddressTableParserSpec{
+	oidIpAddrTableIpAddEntAddr: {
+		valueType: ValueTypeString,
+		stringSetter: func(value string, data *common.IpMapEntry) {
+			address := net.ParseIP(value)
+
+			if address == nil {
+				return
+			}
+
+			address = address.To4()
+
+			if address == nil {
+				return
+			}
+
+			data.IpVersion = 4
+			data.Address = address
+		},
+	},
+	oidIpAddrTableIpAddEntIfIndex: {valueType: ValueTypeInt32,
+		int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.IfIndex = value
+		},
+	},
+	oidIpAddrTableIpAddEntNetMask: {valueType: ValueTypeString,
+		stringSetter: func(value string, data *common.IpMapEntry) {
+			data.NetMask = value
+		},
+	},
+	oidIpAddrTableIpAddEntBcastAddr: {valueType: ValueTypeInt32,
+		int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.BcastAddress = value
+		},
+	},
+	oidIpAddrTableIpAdEntReasmMaxSize: {
+		valueType: ValueTypeInt32,
+		int32Setter: func(value int32, data *common.IpMapEntry) {
+			data.ReasmMaxSize = value
+		}},
+}
+
+// walkInterfaces walks the interface tables, and remembers the configured interfaces it finds, to poll their rows
+// until the next walk.
+func (c *snmpCollector) walkInterfaces(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	if !c.getIfTable(target, data, c.lastInterfaceCount) {
+		return false
+	}
+
+	c.getIfXTable(target, data)
+	c.getDot3StatsTable(target, data)
+	c.polledInterfaces = c.polledInterfaces[:0]
+	c.lastFullWalkTime = c.clock().Now()
+
+	if c.target.Host == nil {
+		return true
+	}
+
+	for i := range data.IfDataList {
+		ifData := &data.IfDataList[i]
+
+		if ifData.Index != 0 && c.target.Host.MonitorsInterface(ifData) {
+			c.polledInterfaces = append(c.polledInterfaces, polledInterface{
+				index:       ifData.Index,
+				name:        ifData.Name,
+				physAddress: ifData.PhysAddress,
+			})
+		}
+	}
+
+	return true
+}
+
+// canPollInterfaces returns true if the configured interfaces' rows can be polled instead of walking the interface
+// tables.  The tables are walked periodically, and when the host has restarted, since it may have numbered its
+// interfaces differently.
+func (c *snmpCollector) canPollInterfaces(uptimeSuccess bool, uptimeSeconds uint64) bool {
+	return len(c.polledInterfaces) > 0 &&
+		uptimeSuccess && uptimeSeconds >= c.lastUptimeSeconds &&
+		c.clock().Now().Sub(c.lastFullWalkTime) < fullInterfaceWalkInterval
+}
+
+// clock returns the host's clock, which times the interface walks.
+func (c *snmpCollector) clock() common.Clock {
+	if c.target.Host == nil {
+		return common.SystemClock
+	}
+
+	return c.target.Host.Clock()
+}
+
+// getPolledInterfaces retrieves the rows of the configured interfaces with GET requests, each with up to MaxOids
+// OIDs.  It returns false if the rows couldn't be retrieved, and renumbered is true if an interface's index no
+// longer identifies it, so the interface tables need to be walked.
+func (c *snmpCollector) getPolledInterfaces(target *gosnmp.GoSNMP, data *common.HostData) (bool, bool) {
+	requestOids := make([]string, 0, len(c.polledInterfaces)*len(polledColumns))
+	interfaceCount := c.lastInterfaceCount
+
+	for _, polled := range c.polledInterfaces {
+		interfaceCount = max(interfaceCount, int(polled.index))
+
+		for _, column := range polledColumns {
+			requestOids = append(requestOids, column+"."+strconv.Itoa(int(polled.index)))
+		}
+	}
+
+	// Interfaces that aren't polled are left empty, so addresses can still be assigned by interface index
+	ifData := make([]common.IfData, interfaceCount)
+
+	for batch := range slices.Chunk(requestOids, max(1, target.MaxOids)) {
+		response, err := target.Get(batch)
+
+		if err == nil && response.Error != gosnmp.NoError {
+			err = fmt.Errorf("request failed with error status %v", response.Error)
+		}
+
+		if err != nil {
+			fmt.Printf("monitoring task [%s]: Error retrieving interfaces: %v\n", c.target.Name, err)
+			return false, false
+		}
+
+		for _, variable := range response.Variables {
+			c.processPolledInterfaceData(variable, ifData)
+		}
+	}
+
+	for _, polled := range c.polledInterfaces {
+		actual := &ifData[polled.index-1]
+
+		if actual.Index != polled.index ||
+			(polled.name != "" && actual.Name != polled.name) ||
+			(polled.physAddress != "" && actual.PhysAddress != polled.physAddress) {
+			fmt.Printf("monitoring task [%s]: Interface index %d no longer identifies %s %s, walking interfaces\n",
+				c.target.Name, polled.index, polled.name, polled.physAddress)
+			return false, true
+		}
+	}
+
+	data.IfDataList = ifData
+
+	return true, false
+}
+
+func (c *snmpCollector) processPolledInterfaceData(dataUnit gosnmp.SnmpPDU, interfaces []common.IfData) {
+	if isEndOfColumn(dataUnit) {
+		// The interface is gone, or the host doesn't support the table
+		return
+	}
+
+	column, index, ok := c.parseIfOid(dataUnit.Name)
+
+	if !ok {
+		return
+	}
+
+	if column == oidIfTableIfIndex {
+		ifIndex := int32(gosnmp.ToBigInt(dataUnit.Value).Int64())
+
+		if ifIndex == index && index >= 1 && int(index) <= len(interfaces) {
+			interfaces[index-1].Index = index
+		}
+
+		return
+	}
+
+	c.processIfTableDetail("IfTable", ifTableParserMap, column, index, dataUnit, interfaces)
+	c.processIfTableDetail("IfXTable", ifXTableParserMap, column, index, dataUnit, interfaces)
+	c.processIfTableDetail("Dot3StatsTable", dot3StatsTableParserMap, column, index, dataUnit, interfaces)
+}
+
+func (c *snmpCollector) getIfTable(target *gosnmp.GoSNMP, data *common.HostData, previousInterfaceCount int) bool {
+	// The slice grows to include every interface index found, and is written to by interface index
+	ifData := make([]common.IfData, 0, previousInterfaceCount)
+	dataUnitCount := 0
+
+	var walkFn = func(dataUnit gosnmp.SnmpPDU) error {
+		dataUnitCount++
+		return c.processIfTableData(dataUnit, &ifData)
+	}
+
+	triedBulkWalk := c.useBulkWalk
+
+	// Not all devices support bulk walks, so we use a single walk instead, if needed
+	if c.useBulkWalk {
+		err := c.walkColumns(target, ifTableColumns, previousInterfaceCount, walkFn)
+
+		if err == nil {
+			// Since we succeeded with bulkwalk, we're done!
+			data.IfDataList = trimInterfaces(ifData)
+
+			return dataUnitCount > 0
+		}
+
+		fmt.Printf("monitoring task [%s]: Error retrieving ifTable via BulkWalk: %v\n", c.target.Name, err)
+		c.useBulkWalk = false
+		c.target.Metrics.RecordBulkWalkFallback(c.target.Name)
+	}
+
+	// Recreate ifTable to avoid any partial data from an incomplete bulk walk
+	ifData = make([]common.IfData, 0, len(ifData))
+	dataUnitCount = 0
+
+	walkStartTime := time.Time{}
+
+	if triedBulkWalk {
+		fmt.Printf("monitoring task [%s]: Retrieving ifTable via Walk\n", c.target.Name)
+		walkStartTime = time.Now()
+	}
+
+	err := c.walkColumns(target, ifTableColumns, previousInterfaceCount, walkFn)
+
+	if err == nil {
+		if triedBulkWalk {
+			fmt.Printf("monitoring task [%s]: Successfully retrieved ifTable via Walk in %v\n", c.target.Name, time.Since(walkStartTime))
+		}
+	} else {
+		fmt.Printf("monitoring task [%s]: Error retrieving ifTable via Walk: %v\n", c.target.Name, err)
+		return false
+	}
+
+	data.IfDataList = trimInterfaces(ifData)
+
+	return dataUnitCount > 0
+}
+
+func (c *snmpCollector) getIfXTable(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	var walkFn = func(dataUnit gosnmp.SnmpPDU) error {
+		return c.processIfXTableData(dataUnit, data.IfDataList)
+	}
+
+	err := c.walkColumns(target, ifXTableColumns, len(data.IfDataList), walkFn)
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Error retrieving ifXTable: %v\n", c.target.Name, err)
+		return false
+	}
+
+	return true
+}
+
+// getDot3StatsTable retrieves the duplex status of Ethernet interfaces.  Devices without the EtherLike-MIB return
+// no data, which leaves the status unknown.
+func (c *snmpCollector) getDot3StatsTable(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	var walkFn = func(dataUnit gosnmp.SnmpPDU) error {
+		c.processIfColumnData("Dot3StatsTable", dot3StatsTableParserMap, dataUnit, data.IfDataList)
+		return nil
+	}
+
+	err := c.walkColumns(target, dot3StatsTableColumns, len(data.IfDataList), walkFn)
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Error retrieving dot3StatsTable: %v\n", c.target.Name, err)
+		return false
+	}
+
+	return true
+}
+
+func (c *snmpCollector) getIpAddressTable(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	var ipTable = make(map[string]*common.IpMapEntry)
+
+	var walkFn = func(dataUnit gosnmp.SnmpPDU) error {
+		return c.processIpAddressTableData(dataUnit, ipTable)
+	}
+
+	var err error = nil
+
+	if c.useBulkWalk {
+		err = target.BulkWalk(oidIpAddressTable, walkFn)
+	} else {
+		err = target.Walk(oidIpAddressTable, walkFn)
+	}
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Error retrieving ipAddressTable: %v\n", c.target.Name, err)
+		return false
+	}
+
+	// Integrate ipMapEntry instances onto the referenced interfaces
+	for key, ipMapEntry := range ipTable {
+		if ipMapEntry.IfIndex > 0 && ipMapEntry.IfIndex <= int32(len(data.IfDataList)) {
+			err := populateIpAddressAndVersion(key, ipMapEntry)
+
+			if err == nil && (ipMapEntry.IpVersion == 4 || ipMapEntry.IpVersion == 6) {
+				if ipMapEntry.Type == ipAddressTableIpAddressTypeBroadcast {
+					// Ignore broadcast addresses
+				} else {
+					data.IfDataList[ipMapEntry.IfIndex-1].IpAddresses =
+						append(data.IfDataList[ipMapEntry.IfIndex-1].IpAddresses, *ipMapEntry)
+				}
+			} else {
+				fmt.Printf("monitoring task [%s]: invalid ipAddressTable entry %s: %v\n",
+					c.target.Name, key, err)
+			}
+		} else {
+			fmt.Printf("monitoring task [%s]: ipMapEntry.IfIndex (%d) is out of range (%d)\n",
+				c.target.Name, ipMapEntry.IfIndex, len(data.IfDataList))
+		}
+	}
+
+	return len(ipTable) > 0
+}
+
+func populateIpAddressAndVersion(key string, ipMapEntry *common.IpMapEntry) error {
+	keyParts := strings.Split(key, ".")
+
+	if len(keyParts) < 6 {
+		return fmt.Errorf("invalid ipAddressTable key %s", key)
+	}
+
+	if keyParts[0] == "1" && keyParts[1] == "4" && len(keyParts) == 6 {
+		address, err := buildIpAddress(keyParts[2:])
+
+		if err != nil {
+			return fmt.Errorf("invalid IPv4 address: %s: %w", key, err)
+		}
+
+		ipMapEntry.IpVersion = 4
+		ipMapEntry.Address = address
+	} else if keyParts[0] == "2" && keyParts[1] == "16" && len(keyParts) == 18 {
+		address, err := buildIpAddress(keyParts[2:])
+
+		if err != nil {
+			return fmt.Errorf("invalid IPv6 address: %s: %w", key, err)
+		}
+
+		ipMapEntry.IpVersion = 6
+		ipMapEntry.Address = address
+	} else {
+		return fmt.Errorf("invalid ipAddressTable key %s", key)
+	}
+
+	return nil
+}
+
+func buildIpAddress(keyParts []string) (net.IP, error) {
+	bytes := make([]byte, len(keyParts))
+
+	for i, value := range keyParts {
+		b, err := strconv.Atoi(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid string in IP address OID: %w", err)
+		}
+
+		bytes[i] = byte(b)
+	}
+
+	ip := net.IP(bytes)
+
+	return ip, nil
+}
+
+func (c *snmpCollector) getIpAddrTable(target *gosnmp.GoSNMP, data *common.HostData) bool {
+	var ipTable = make(map[string]*common.IpMapEntry)
+
+	var walkFn = func(dataUnit gosnmp.SnmpPDU) error {
+		return c.processIpAddrTableData(dataUnit, ipTable)
+	}
+
+	var err error = nil
+
+	if c.useBulkWalk {
+		err = target.BulkWalk(oidIpAddrTable, walkFn)
+	} else {
+		err = target.Walk(oidIpAddrTable, walkFn)
+	}
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Error retrieving ipAddrTable: %v\n", c.target.Name, err)
+		return false
+	}
+
+	// Integrate ipMapEntry instances onto the referenced interfaces
+	for _, ipMapEntry := range ipTable {
+		if ipMapEntry.IfIndex > 0 && ipMapEntry.IfIndex <= int32(len(data.IfDataList)) {
+			data.IfDataList[ipMapEntry.IfIndex-1].IpAddresses =
+				append(data.IfDataList[ipMapEntry.IfIndex-1].IpAddresses, *ipMapEntry)
+		} else {
+			fmt.Printf("monitoring task [%s]: ipMapEntry.IfIndex (%d) is out of range (%d)\n",
+				c.target.Name, ipMapEntry.IfIndex, len(data.IfDataList))
+		}
+	}
+
+	return true
+}
+
+func (c *snmpCollector) processIpAddressTableData(
+	dataUnit gosnmp.SnmpPDU, ipMap map[string]*common.IpMapEntry) error {
+	for oid, spec := range ipAddressTableParserMap {
+		if strings.HasPrefix(dataUnit.Name, oid) {
+			id := parseIpIdentifier(oid, dataUnit.Name)
+
+			mapEntry, ok := ipMap[id]
+
+			if !ok {
+				mapEntry = &common.IpMapEntry{}
+				ipMap[id] = mapEntry
+			}
+
+			switch spec.valueType {
+			case ValueTypeInt32:
+				value, ok := parseInt32Value(dataUnit)
+				if ok {
+					spec.int32Setter(value, mapEntry)
+				}
+				break
+			case ValueTypeString:
+				value, ok := parseStringValue(dataUnit)
+				if ok {
+					spec.stringSetter(value, mapEntry)
+				}
+				break
+			default:
+				return fmt.Errorf("unsupported value type %d for oid %s", spec.valueType, oid)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+func (c *snmpCollector) processIpAddrTableData(
+	dataUnit gosnmp.SnmpPDU, ipMap map[string]*common.IpMapEntry) error {
+	for oid, spec := range ipV4AddrTableParserMap {
+		if strings.HasPrefix(dataUnit.Name, oid) {
+			id := parseIpIdentifier(oid, dataUnit.Name)
+
+			mapEntry, ok := ipMap[id]
+
+			if !ok {
+				mapEntry = &common.IpMapEntry{}
+				ipMap[id] = mapEntry
+			}
+
+			switch spec.valueType {
+			case ValueTypeString:
+				value, ok := parseStringValue(dataUnit)
+				if ok {
+					spec.stringSetter(value, mapEntry)
+				}
+				break
+			case ValueTypeInt32:
+				value, ok := parseInt32Value(dataUnit)
+				if ok {
+					spec.int32Setter(value, mapEntry)
+				}
+				break
+			default:
+				return fmt.Errorf("unsupported value type %d for oid %s", spec.valueType, oid)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+func parseIpIdentifier(oidPrefix string, oid string) string {
+	return oid[len(oidPrefix):]
+}
+
+func (c *snmpCollector) processIfTableData(dataUnit gosnmp.SnmpPDU, interfaces *[]common.IfData) error {
+	//printSNMPData(dataUnit)
+	column, index, ok := c.parseIfOid(dataUnit.Name)
+
+	if !ok {
+		return nil
+	}
+
+	if column == oidIfTableIfIndex {
+		var ifIndex = int32(gosnmp.ToBigInt(dataUnit.Value).Int64())
+		// Sanity check: Don't allow more than 1,000 interfaces.  Values that aren't integers convert to zero.
+		if ifIndex < 1 || ifIndex > maxInterfaceIndex {
+			return fmt.Errorf("interface index %d is out of range", ifIndex)
+		}
+
+		growInterfaces(interfaces, ifIndex)
+		(*interfaces)[ifIndex-1].Index = ifIndex
+
+		return nil
+	}
+
+	// The columns are walked in parallel, so a column with missing rows runs ahead of ifIndex, and can reach an
+	// interface first.  Interfaces ifIndex never reaches are trimmed after the walk.
+	if index >= 1 && index <= maxInterfaceIndex {
+		growInterfaces(interfaces, index)
+	}
+
+	c.processIfTableDetail("IfTable", ifTableParserMap, column, index, dataUnit, *interfaces)
+
+	return nil
+}
+
+// growInterfaces extends the slice of interfaces to include the interface with the index.
+func growInterfaces(interfaces *[]common.IfData, index int32) {
+	var currentCount = int32(len(*interfaces))
+
+	if index > currentCount {
+		if index-currentCount == 1 {
+			*interfaces = append(*interfaces, common.IfData{})
+		} else {
+			var additional = make([]common.IfData, index-currentCount)
+			*interfaces = slices.Concat(*interfaces, additional)
+		}
+	}
+}
+
+// trimInterfaces removes the trailing interfaces that only columns other than ifIndex reported.
+func trimInterfaces(interfaces []common.IfData) []common.IfData {
+	for len(interfaces) > 0 && interfaces[len(interfaces)-1].Index == 0 {
+		interfaces = interfaces[:len(interfaces)-1]
+	}
+
+	return interfaces
+}
+
+func (c *snmpCollector) processIfXTableData(dataUnit gosnmp.SnmpPDU, interfaces []common.IfData) error {
+	c.processIfColumnData("IfXTable", ifXTableParserMap, dataUnit, interfaces)
+
+	return nil
+}
+
+func (c *snmpCollector) processIfColumnData(
+	tableName string,
+	parserMap map[string]*parserSpec,
+	dataUnit gosnmp.SnmpPDU,
+	interfaces []common.IfData) {
+	column, index, ok := c.parseIfOid(dataUnit.Name)
+
+	if ok {
+		c.processIfTableDetail(tableName, parserMap, column, index, dataUnit, interfaces)
+	}
+}
+
+// processIfTableDetail parses the value of an interface's column, if the parser map has a parser for the column.
+func (c *snmpCollector) processIfTableDetail(
+	tableName string,
+	parserMap map[string]*parserSpec,
+	column string,
+	index int32,
+	dataUnit gosnmp.SnmpPDU,
+	interfaces []common.IfData) {
+	spec, ok := parserMap[column]
+
+	if !ok {
+		return
+	}
+
+	interfaceCount := int32(len(interfaces))
+	offset := index - 1
+
+	if offset < 0 || offset >= interfaceCount {
+		fmt.Printf(
+			"monitoring task [%s]: Interface index %d (array offset %d) in %s on oid %s is out of range (0-%d)\n",
+			c.target.Name, index, offset, tableName, column, interfaceCount-1)
+		return
+	}
+
+	switch spec.valueType {
+	case ValueTypeString:
+		value, ok := parseStringValue(dataUnit)
+		if ok {
+			spec.stringSetter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypeStringBytes:
+		value, ok := parseStringBytesValue(dataUnit)
+		if ok {
+			spec.stringSetter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypeInt32:
+		value, ok := parseInt32Value(dataUnit)
+		if ok {
+			spec.int32Setter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypeUint32:
+		value, ok := parseUint32Value(dataUnit)
+		if ok {
+			spec.uint32Setter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypeUint64:
+		value, ok := parseUint64Value(dataUnit)
+		if ok {
+			spec.uint64Setter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypeTimeTicks:
+		value, ok := parseTimeTicksValue(dataUnit)
+		if ok {
+			spec.uint32Setter(value, &interfaces[offset])
+		}
+		break
+	case ValueTypePhysicalAddress:
+		value, ok := parsePhysicalAddressValue(dataUnit)
+		if ok {
+			spec.stringSetter(value, &interfaces[offset])
+		}
+		break
+	}
+}
+
+// parseIfOid splits the OID of an interface table value into the column's OID and the interface index.
+func (c *snmpCollector) parseIfOid(oid string) (string, int32, bool) {
+	separator := strings.LastIndexByte(oid, '.')
+
+	if separator < 0 {
+		fmt.Printf("monitoring task [%s]: Unable to parse interface index from \"%s\"\n", c.target.Name, oid)
+		return "", -1, false
+	}
+
+	index, err := strconv.ParseInt(oid[separator+1:], 10, 32)
+
+	if err != nil {
+		fmt.Printf("monitoring task [%s]: Unable to parse interface index from \"%s\"\n", c.target.Name, oid)
+		return "", -1, false
+	}
+
+	return oid[:separator], int32(index), true
+}
+
+// walkColumns walks the table columns in parallel: each request asks for the next rows of every column that hasn't
+// ended, so a table is retrieved without walking the columns we don't use.  Bulk requests share maxBulkVariables
+// between the columns, and ask for no more than one row past the expected number of rows, which is enough to see
+// the columns end.  Requests are GetNext requests, one row at a time, if bulk walks aren't supported.
+func (c *snmpCollector) walkColumns(
+	target *gosnmp.GoSNMP, columns []string, expectedRows int, walkFn gosnmp.WalkFunc) error {
+	prefixes := make([]string, len(columns))
+	cursors := slices.Clone(columns)
+	active := make([]int, len(columns))
+
+	for i, column := range columns {
+		prefixes[i] = column + "."
+		active[i] = i
+	}
+
+	for len(active) > 0 {
+		requestOids := make([]string, len(active))
+
+		for i, column := range active {
+			requestOids[i] = cursors[column]
+		}
+
+		var response *gosnmp.SnmpPacket
+		var err error
+
+		if c.useBulkWalk {
+			response, err = target.GetBulk(requestOids, 0, bulkRepetitions(len(active), expectedRows))
+		} else {
+			response, err = target.GetNext(requestOids)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if response.Error != gosnmp.NoError {
+			return fmt.Errorf("request failed with error status %v", response.Error)
+		}
+
+		if len(response.Variables) == 0 {
+			return errors.New("empty response")
+		}
+
+		// Variables are returned row by row, with one per active column, in request order.  Once a column ends,
+		// its remaining variables belong to whatever follows it.
+		ended := make([]bool, len(active))
+
+		for i, variable := range response.Variables {
+			position := i % len(active)
+			column := active[position]
+
+			if ended[position] {
+				continue
+			}
+
+			if isEndOfColumn(variable) || !strings.HasPrefix(variable.Name, prefixes[column]) {
+				ended[position] = true
+				continue
+			}
+
+			if variable.Name == cursors[column] {
+				return fmt.Errorf("OID %s is not increasing", variable.Name)
+			}
+
+			cursors[column] = variable.Name
+
+			if err := walkFn(variable); err != nil {
+				return err
+			}
+		}
+
+		remaining := make([]int, 0, len(active))
+
+		for position, column := range active {
+			if !ended[position] {
+				remaining = append(remaining, column)
+			}
+		}
+
+		active = remaining
+	}
+
+	return nil
+}
+
+// bulkRepetitions returns the max-repetitions of a GetBulk request for the number of columns.
+func bulkRepetitions(columnCount int, expectedRows int) uint32 {
+	repetitions := max(1, maxBulkVariables/columnCount)
+
+	if expectedRows > 0 {
+		repetitions = min(repetitions, expectedRows+1)
+	}
+
+	return uint32(repetitions)
+}
+
+func isEndOfColumn(variable gosnmp.SnmpPDU) bool {
+	return variable.Type == gosnmp.EndOfMibView ||
+		variable.Type == gosnmp.NoSuchObject ||
+		variable.Type == gosnmp.NoSuchInstance
+}
+
+func parseStringValue(dataUnit gosnmp.SnmpPDU) (string, bool) {
+	value, ok := dataUnit.Value.(string)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to string for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return "", false
+	}
+
+	return value, true
+}
+
+func parseStringBytesValue(dataUnit gosnmp.SnmpPDU) (string, bool) {
+	value, ok := dataUnit.Value.([]byte)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to []byte for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return "", false
+	}
+
+	return string(value), true
+}
+
+func parsePhysicalAddressValue(dataUnit gosnmp.SnmpPDU) (string, bool) {
+	value, ok := dataUnit.Value.([]byte)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to []byte for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return "", false
+	}
+
+	return net.HardwareAddr(value).String(), true
+}
+
+func parseInt32Value(dataUnit gosnmp.SnmpPDU) (int32, bool) {
+	value, ok := dataUnit.Value.(int)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value \"%v\" to int for oid \"%s\"\n",
+			dataUnit.Value, dataUnit.Value, dataUnit.Name)
+		return 0, false
+	}
+
+	return int32(value), true
+}
+
+func parseUint32Value(dataUnit gosnmp.SnmpPDU) (uint32, bool) {
+	value, ok := dataUnit.Value.(uint)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to uint for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return 0, false
+	}
+
+	return uint32(value), true
+}
+
+func parseUint64Value(dataUnit gosnmp.SnmpPDU) (uint64, bool) {
+	value, ok := dataUnit.Value.(uint64)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to uint64 for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return 0, false
+	}
+
+	return value, true
+}
+
+func parseTimeTicksValue(dataUnit gosnmp.SnmpPDU) (uint32, bool) {
+	value, ok := dataUnit.Value.(uint32)
+
+	if !ok {
+		fmt.Printf("Unable to cast %T value to uint32 for oid \"%s\"\n", dataUnit.Value, dataUnit.Name)
+		return 0, false
+	}
+
+	// TimeTicks is in 1/100ths of a second.
+	return value / 100, true
+}
+
+func printSNMPData(dataUnit gosnmp.SnmpPDU) {
+	fmt.Printf("oid: %s ", dataUnit.Name)
+	switch dataUnit.Type {
+	case gosnmp.OctetString:
+		bytes := dataUnit.Value.([]byte)
+		fmt.Printf("string: %s\n", string(bytes))
+	default:
+		// ... or often you're just interested in numeric values.
+		// ToBigInt() will return the Value as a BigInt, for plugging
+		// into your calculations.
+		fmt.Printf("number: %d\n", gosnmp.ToBigInt(dataUnit.Value))
+	}
+}
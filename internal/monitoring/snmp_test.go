@@ -0,0 +1,718 @@
+package monitoring
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+	"github.com/avanha/pmaas-plugin-netmon/internal/netinterface"
+	"github.com/avanha/pmaas-plugin-netmon/internal/snmpsim"
+	"github.com/avanha/pmaas-spi/tracking"
+	"github.com/gosnmp/gosnmp"
+)
+
+func startAgent(t testing.TB, fixture string, faults snmpsim.Faults) *snmpsim.Agent {
+	t.Helper()
+
+	records, err := snmpsim.LoadFile(fixture)
+
+	if err != nil {
+		t.Fatalf("Expected no error loading %s, got %v", fixture, err)
+	}
+
+	agent, err := snmpsim.Start(snmpsim.Config{Records: records, Faults: faults})
+
+	if err != nil {
+		t.Fatalf("Expected no error starting the agent, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = agent.Close() })
+
+	return agent
+}
+
+func createTestCollector(agent *snmpsim.Agent) *snmpCollector {
+	collector := newSnmpCollector(&Target{Name: "test", Address: agent.Address()}).(*snmpCollector)
+	collector.port = agent.Port()
+	collector.timeout = 100 * time.Millisecond
+	collector.retries = 0
+
+	return collector
+}
+
+func ipAddresses(ifData *common.IfData) []string {
+	result := make([]string, 0, len(ifData.IpAddresses))
+
+	for _, entry := range ifData.IpAddresses {
+		result = append(result, entry.Address.String())
+	}
+
+	return result
+}
+
+func TestSnmpCollectorCollect_ModernAgent_CollectsAllTables(t *testing.T) {
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !data.SnmpSuccess || data.SnmpStatus != "OK" {
+		t.Fatalf("Expected success, got %q", data.SnmpStatus)
+	}
+
+	if data.UptimeSeconds != 86400 {
+		t.Errorf("Expected an uptime of 86400, got %d", data.UptimeSeconds)
+	}
+
+	if len(data.IfDataList) != 2 || collector.lastInterfaceCount != 2 {
+		t.Fatalf("Expected 2 interfaces, got %d", len(data.IfDataList))
+	}
+
+	eth0 := &data.IfDataList[1]
+
+	if eth0.Index != 2 || eth0.Name != "eth0" || eth0.Mtu != 1500 || eth0.PhysAddress != "52:54:00:12:34:56" {
+		t.Errorf("Expected eth0 details, got %+v", eth0)
+	}
+
+	if eth0.OperStatus != 7 || eth0.AdminStatus != 1 || eth0.LastChangeSeconds != 42 {
+		t.Errorf("Expected eth0 status, got oper %d, admin %d, last change %d",
+			eth0.OperStatus, eth0.AdminStatus, eth0.LastChangeSeconds)
+	}
+
+	if eth0.InOctets != 56789 || eth0.HCInOctets != 8589934592 || eth0.HCOutOctets != 17179869184 {
+		t.Errorf("Expected eth0 counters, got %d, %d, %d", eth0.InOctets, eth0.HCInOctets, eth0.HCOutOctets)
+	}
+
+	if eth0.Speed != 4294967295 || eth0.HighSpeed != 10000 || eth0.DuplexStatus != 3 {
+		t.Errorf("Expected eth0 link details, got %d, %d, %d", eth0.Speed, eth0.HighSpeed, eth0.DuplexStatus)
+	}
+
+	if data.IfDataList[0].DuplexStatus != 0 {
+		t.Errorf("Expected no duplex status for lo, got %d", data.IfDataList[0].DuplexStatus)
+	}
+
+	// The broadcast address is ignored
+	addresses := ipAddresses(eth0)
+
+	if len(addresses) != 2 {
+		t.Fatalf("Expected 2 eth0 addresses, got %v", addresses)
+	}
+
+	for _, expected := range []string{"192.168.1.1", "fe80::5054:ff:fe12:3456"} {
+		found := false
+
+		for _, address := range addresses {
+			found = found || address == expected
+		}
+
+		if !found {
+			t.Errorf("Expected %s in %v", expected, addresses)
+		}
+	}
+
+	if agent.RequestCount(gosnmp.GetNextRequest) != 0 {
+		t.Errorf("Expected only bulk requests, got %d GetNext requests", agent.RequestCount(gosnmp.GetNextRequest))
+	}
+}
+
+func TestSnmpCollectorCollect_GetBulkUnsupported_FallsBackToWalk(t *testing.T) {
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{GetBulkUnsupported: true})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if collector.useBulkWalk {
+		t.Errorf("Expected bulk walks to be disabled")
+	}
+
+	if len(data.IfDataList) != 2 || data.IfDataList[1].Name != "eth0" || data.IfDataList[1].HCInOctets != 8589934592 {
+		t.Fatalf("Expected complete interface data, got %+v", data.IfDataList)
+	}
+
+	if len(data.IfDataList[1].IpAddresses) != 2 {
+		t.Errorf("Expected 2 eth0 addresses, got %v", ipAddresses(&data.IfDataList[1]))
+	}
+
+	// The next scan doesn't try GetBulk again
+	bulkRequests := agent.RequestCount(gosnmp.GetBulkRequest)
+	_ = collector.Collect(context.Background(), &common.HostData{})
+
+	if agent.RequestCount(gosnmp.GetBulkRequest) != bulkRequests {
+		t.Errorf("Expected %d GetBulk requests, got %d", bulkRequests, agent.RequestCount(gosnmp.GetBulkRequest))
+	}
+}
+
+func TestSnmpCollectorCollect_NoIpAddressTable_FallsBackToIpAddrTable(t *testing.T) {
+	agent := startAgent(t, "testdata/legacy.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(data.IfDataList) != 2 || data.IfDataList[1].Name != "bridge" {
+		t.Fatalf("Expected 2 interfaces, got %+v", data.IfDataList)
+	}
+
+	if len(data.IfDataList[0].IpAddresses) != 0 {
+		t.Errorf("Expected no ether1 addresses, got %v", ipAddresses(&data.IfDataList[0]))
+	}
+
+	addresses := data.IfDataList[1].IpAddresses
+
+	if len(addresses) != 1 {
+		t.Fatalf("Expected 1 bridge address, got %d", len(addresses))
+	}
+
+	if !addresses[0].Address.Equal(net.ParseIP("10.0.0.1")) || addresses[0].IpVersion != 4 ||
+		addresses[0].NetMask != "255.255.255.0" {
+		t.Errorf("Expected 10.0.0.1/255.255.255.0, got %+v", addresses[0])
+	}
+}
+
+func TestSnmpCollectorCollect_BadTypes_SkipsValues(t *testing.T) {
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{
+		BadTypes: []string{oidIfXTable + ".1.6", oidIfTable + ".1.7"},
+	})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(data.IfDataList) != 2 {
+		t.Fatalf("Expected 2 interfaces, got %d", len(data.IfDataList))
+	}
+
+	eth0 := &data.IfDataList[1]
+
+	if eth0.HCInOctets != 0 || eth0.AdminStatus != 0 {
+		t.Errorf("Expected the values with bad types to be skipped, got %d, %d", eth0.HCInOctets, eth0.AdminStatus)
+	}
+
+	if eth0.HCOutOctets != 17179869184 || eth0.OperStatus != 7 {
+		t.Errorf("Expected the other values, got %d, %d", eth0.HCOutOctets, eth0.OperStatus)
+	}
+}
+
+func TestSnmpCollectorCollect_BadIfIndexType_FailsIfTable(t *testing.T) {
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{
+		BadTypes: []string{oidIfTable + ".1.1"},
+	})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(data.IfDataList) != 0 {
+		t.Errorf("Expected no interfaces, got %d", len(data.IfDataList))
+	}
+
+	if !data.SnmpSuccess || data.UptimeSeconds != 86400 {
+		t.Errorf("Expected the uptime to be retrieved, got %q", data.SnmpStatus)
+	}
+}
+
+func TestSnmpCollectorCollect_TruncatedIfTable_IgnoresOutOfRangeRows(t *testing.T) {
+	// Only the first ifIndex is served, so the other tables reference an unknown interface
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{
+		TruncateAfter: map[string]int{oidIfTable: 1},
+	})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !data.SnmpSuccess {
+		t.Fatalf("Expected success, got %q", data.SnmpStatus)
+	}
+
+	if len(data.IfDataList) != 1 || data.IfDataList[0].Index != 1 {
+		t.Fatalf("Expected 1 interface, got %+v", data.IfDataList)
+	}
+
+	if data.IfDataList[0].HCInOctets != 1234 {
+		t.Errorf("Expected the ifXTable values of interface 1, got %d", data.IfDataList[0].HCInOctets)
+	}
+
+	if addresses := ipAddresses(&data.IfDataList[0]); len(addresses) != 1 || addresses[0] != "127.0.0.1" {
+		t.Errorf("Expected only 127.0.0.1, got %v", addresses)
+	}
+}
+
+func TestSnmpCollectorCollect_NoResponse_ReportsFailure(t *testing.T) {
+	agent := startAgent(t, "testdata/router.snmpwalk", snmpsim.Faults{DropPrefixes: []string{".1"}})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	err := collector.Collect(context.Background(), &data)
+
+	if err == nil || data.SnmpSuccess || data.SnmpStatus != "Failed to retrieve any data" {
+		t.Errorf("Expected failure, got %q (%v)", data.SnmpStatus, err)
+	}
+}
+
+func TestSnmpCollectorCollect_Switch_CollectsAllInterfaces(t *testing.T) {
+	agent := startAgent(t, "testdata/switch.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	if err := collector.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(data.IfDataList) != 200 {
+		t.Fatalf("Expected 200 interfaces, got %d", len(data.IfDataList))
+	}
+
+	for i := range data.IfDataList {
+		if data.IfDataList[i].Index != int32(i+1) || data.IfDataList[i].Name == "" {
+			t.Fatalf("Expected interface %d, got %+v", i+1, data.IfDataList[i])
+		}
+	}
+
+	vlan := &data.IfDataList[52]
+
+	if vlan.Name != "Vlan1" || vlan.HighSpeed != 1000 || vlan.HCInOctets == 0 || vlan.DuplexStatus != 0 {
+		t.Errorf("Expected Vlan1 details, got %+v", vlan)
+	}
+
+	if addresses := ipAddresses(vlan); len(addresses) != 1 || addresses[0] != "10.0.1.2" {
+		t.Errorf("Expected Vlan1 to have 10.0.1.2, got %v", addresses)
+	}
+
+	if uplink := &data.IfDataList[48]; uplink.Name != "TenGigabitEthernet1/1/1" || uplink.DuplexStatus == 0 {
+		t.Errorf("Expected the first uplink's details, got %+v", uplink)
+	}
+}
+
+func TestWalkColumns_Switch_ReturnsOnlyRequestedColumns(t *testing.T) {
+	agent := startAgent(t, "testdata/switch.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	target := connectTestTarget(t, agent)
+	columns := []string{oidIfXTableIfHCInOctets, oidIfXTableIfHighSpeed}
+	counts := make(map[string]int)
+
+	err := collector.walkColumns(target, columns, 0, func(dataUnit gosnmp.SnmpPDU) error {
+		column, _, _ := collector.parseIfOid(dataUnit.Name)
+		counts[column]++
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(counts) != 2 || counts[oidIfXTableIfHCInOctets] != 200 || counts[oidIfXTableIfHighSpeed] != 200 {
+		t.Errorf("Expected 200 values of each column, got %v", counts)
+	}
+
+	// 25 rows of both columns per request
+	if requests := agent.RequestCount(gosnmp.GetBulkRequest); requests != 9 {
+		t.Errorf("Expected 9 GetBulk requests, got %d", requests)
+	}
+}
+
+func TestSnmpCollectorCollect_SparseColumns_AssignsValuesByIndex(t *testing.T) {
+	// ifDescr has no rows for the first two interfaces, so it runs ahead of ifIndex, and has a row for an
+	// interface ifIndex doesn't list
+	records, _ := snmpsim.ParseSnmprec(strings.NewReader(`1.3.6.1.2.1.1.3.0|67|100
+1.3.6.1.2.1.2.2.1.1.1|2|1
+1.3.6.1.2.1.2.2.1.1.2|2|2
+1.3.6.1.2.1.2.2.1.1.3|2|3
+1.3.6.1.2.1.2.2.1.2.3|4|eth2
+1.3.6.1.2.1.2.2.1.2.4|4|ghost
+1.3.6.1.2.1.2.2.1.4.1|2|1500
+1.3.6.1.2.1.2.2.1.4.2|2|9000
+1.3.6.1.2.1.2.2.1.4.3|2|1400`))
+	agent, err := snmpsim.Start(snmpsim.Config{Records: records})
+
+	if err != nil {
+		t.Fatalf("Expected no error starting the agent, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = agent.Close() })
+	collector := createTestCollector(agent)
+	data := common.HostData{}
+
+	if err := collector.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(data.IfDataList) != 3 {
+		t.Fatalf("Expected 3 interfaces, got %+v", data.IfDataList)
+	}
+
+	if data.IfDataList[2].Name != "eth2" || data.IfDataList[2].Mtu != 1400 || data.IfDataList[0].Name != "" {
+		t.Errorf("Expected eth2 to be the third interface, got %+v", data.IfDataList)
+	}
+
+	if data.IfDataList[1].Mtu != 9000 {
+		t.Errorf("Expected an MTU of 9000 for the second interface, got %d", data.IfDataList[1].Mtu)
+	}
+}
+
+func TestBulkRepetitions_ColumnsAndExpectedRows_SharesVariables(t *testing.T) {
+	tests := []struct {
+		columns      int
+		expectedRows int
+		expected     uint32
+	}{
+		{1, 0, 50},
+		{16, 0, 3},
+		{16, 200, 3},
+		{2, 4, 5},
+		{60, 0, 1},
+	}
+
+	for _, test := range tests {
+		if actual := bulkRepetitions(test.columns, test.expectedRows); actual != test.expected {
+			t.Errorf("Expected %d repetitions for %d columns and %d rows, got %d",
+				test.expected, test.columns, test.expectedRows, actual)
+		}
+	}
+}
+
+// createTestHost creates a host with interfaces configured by name.
+func createTestHost(interfaceNames ...string) *host.Host {
+	result := host.NewHost("Host_1", config.Host{Name: "test", SnmpEnabled: true}, tracking.Config{}, nil)
+
+	for _, name := range interfaceNames {
+		result.AddNetInterface(config.GetInterfaceNameKey(name), netinterface.CreateNetInterface("Host_1",
+			"Interface_"+name, tracking.Config{},
+			config.NetInterface{Name: name, IdentificationMode: config.InterfaceByName},
+			data.RateHistoryRetention{}, nil))
+	}
+
+	return result
+}
+
+func startRecordsAgent(t *testing.T, snmprec string) *snmpsim.Agent {
+	t.Helper()
+
+	records, err := snmpsim.ParseSnmprec(strings.NewReader(snmprec))
+
+	if err != nil {
+		t.Fatalf("Expected no error parsing the records, got %v", err)
+	}
+
+	agent, err := snmpsim.Start(snmpsim.Config{Records: records})
+
+	if err != nil {
+		t.Fatalf("Expected no error starting the agent, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = agent.Close() })
+
+	return agent
+}
+
+func TestSnmpCollectorCollect_ConfiguredInterfaces_PollsTheirRows(t *testing.T) {
+	agent := startAgent(t, "testdata/switch.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	collector.target.Host = createTestHost("GigabitEthernet1/0/1", "Vlan1")
+
+	if err := collector.Collect(context.Background(), &common.HostData{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(collector.polledInterfaces) != 2 {
+		t.Fatalf("Expected 2 polled interfaces, got %+v", collector.polledInterfaces)
+	}
+
+	getRequests := agent.RequestCount(gosnmp.GetRequest)
+	data := common.HostData{}
+
+	if err := collector.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// One request for the uptime, and one for both interfaces' rows
+	if requests := agent.RequestCount(gosnmp.GetRequest) - getRequests; requests != 2 {
+		t.Errorf("Expected 2 Get requests, got %d", requests)
+	}
+
+	if len(data.IfDataList) != 200 {
+		t.Fatalf("Expected 200 interfaces, got %d", len(data.IfDataList))
+	}
+
+	port, vlan := &data.IfDataList[0], &data.IfDataList[52]
+
+	if port.Name != "GigabitEthernet1/0/1" || port.DuplexStatus != 3 || port.HCInOctets == 0 || port.Mtu != 1500 {
+		t.Errorf("Expected the port's details, got %+v", port)
+	}
+
+	if vlan.Name != "Vlan1" || vlan.HighSpeed != 1000 || len(vlan.IpAddresses) != 1 {
+		t.Errorf("Expected the VLAN's details, got %+v", vlan)
+	}
+
+	if data.IfDataList[1].Index != 0 || data.IfDataList[1].Name != "" {
+		t.Errorf("Expected interfaces that aren't configured to be empty, got %+v", data.IfDataList[1])
+	}
+}
+
+func TestSnmpCollectorCollect_RenumberedInterface_WalksInterfaces(t *testing.T) {
+	before := startRecordsAgent(t, `1.3.6.1.2.1.1.3.0|67|100
+1.3.6.1.2.1.2.2.1.1.1|2|1
+1.3.6.1.2.1.2.2.1.1.2|2|2
+1.3.6.1.2.1.2.2.1.2.1|4|eth0
+1.3.6.1.2.1.2.2.1.2.2|4|eth1`)
+	after := startRecordsAgent(t, `1.3.6.1.2.1.1.3.0|67|200
+1.3.6.1.2.1.2.2.1.1.1|2|1
+1.3.6.1.2.1.2.2.1.1.2|2|2
+1.3.6.1.2.1.2.2.1.2.1|4|eth1
+1.3.6.1.2.1.2.2.1.2.2|4|eth0`)
+	collector := createTestCollector(before)
+	collector.target.Host = createTestHost("eth0")
+	_ = collector.Collect(context.Background(), &common.HostData{})
+	collector.port = after.Port()
+	data := common.HostData{}
+
+	if err := collector.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if after.RequestCount(gosnmp.GetBulkRequest) == 0 {
+		t.Errorf("Expected the interfaces to be walked")
+	}
+
+	if len(data.IfDataList) != 2 || data.IfDataList[1].Name != "eth0" {
+		t.Fatalf("Expected eth0 to be the second interface, got %+v", data.IfDataList)
+	}
+
+	if len(collector.polledInterfaces) != 1 || collector.polledInterfaces[0].index != 2 {
+		t.Errorf("Expected eth0 to be polled at index 2, got %+v", collector.polledInterfaces)
+	}
+}
+
+func TestCanPollInterfaces_Conditions_RequireRecentWalkAndNoRestart(t *testing.T) {
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	testHost := createTestHost("eth0")
+	testHost.SetClock(clock)
+	collector := &snmpCollector{
+		target:            &Target{Name: "test", Host: testHost},
+		polledInterfaces:  []polledInterface{{index: 1, name: "eth0"}},
+		lastFullWalkTime:  clock.Now(),
+		lastUptimeSeconds: 100,
+	}
+
+	if !collector.canPollInterfaces(true, 100) {
+		t.Errorf("Expected polling after a recent walk")
+	}
+
+	if collector.canPollInterfaces(true, 99) {
+		t.Errorf("Expected no polling after a restart")
+	}
+
+	if collector.canPollInterfaces(false, 0) {
+		t.Errorf("Expected no polling without the uptime")
+	}
+
+	clock.Advance(fullInterfaceWalkInterval - time.Second)
+
+	if !collector.canPollInterfaces(true, 100) {
+		t.Errorf("Expected polling until a walk is due")
+	}
+
+	clock.Advance(time.Second)
+
+	if collector.canPollInterfaces(true, 100) {
+		t.Errorf("Expected no polling once a walk is due")
+	}
+
+	collector.lastFullWalkTime = clock.Now()
+	collector.polledInterfaces = nil
+
+	if collector.canPollInterfaces(true, 100) {
+		t.Errorf("Expected no polling without configured interfaces")
+	}
+}
+
+// connectTestTarget connects to the agent the way the collector does.
+func connectTestTarget(t testing.TB, agent *snmpsim.Agent) *gosnmp.GoSNMP {
+	t.Helper()
+
+	target := &gosnmp.GoSNMP{
+		Target:    agent.Address(),
+		Port:      agent.Port(),
+		Transport: "udp",
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+
+	if err := target.Connect(); err != nil {
+		t.Fatalf("Expected no error connecting, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = target.Close() })
+
+	return target
+}
+
+// prefixScan finds the column of the value by matching its OID against the prefix of every column, the baseline
+// for the benchmarks.
+func prefixScan(prefixes map[string]string, oid string) (string, int32, bool) {
+	for prefix, column := range prefixes {
+		if strings.HasPrefix(oid, prefix) {
+			index, err := strconv.ParseInt(oid[len(prefix):], 10, 32)
+			return column, int32(index), err == nil
+		}
+	}
+
+	return "", -1, false
+}
+
+func columnPrefixes(parserMap map[string]*parserSpec) map[string]string {
+	result := make(map[string]string)
+
+	for column := range parserMap {
+		result[column+"."] = column
+	}
+
+	return result
+}
+
+// BenchmarkGetIfTables_WholeTables is the baseline for BenchmarkGetIfTables_UsedColumns: it bulk walks the whole
+// ifTable and ifXTable, and matches every value against the prefixes of the parsed columns.
+func BenchmarkGetIfTables_WholeTables(b *testing.B) {
+	agent := startAgent(b, "testdata/switch.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	target := connectTestTarget(b, agent)
+	ifTablePrefixes := columnPrefixes(ifTableParserMap)
+	ifXTablePrefixes := columnPrefixes(ifXTableParserMap)
+
+	for b.Loop() {
+		interfaces := make([]common.IfData, 0)
+
+		err := target.BulkWalk(oidIfTable, func(dataUnit gosnmp.SnmpPDU) error {
+			if strings.HasPrefix(dataUnit.Name, oidIfTableIfIndex+".") {
+				return collector.processIfTableData(dataUnit, &interfaces)
+			}
+
+			if column, index, ok := prefixScan(ifTablePrefixes, dataUnit.Name); ok {
+				collector.processIfTableDetail("IfTable", ifTableParserMap, column, index, dataUnit, interfaces)
+			}
+
+			return nil
+		})
+
+		if err == nil {
+			err = target.BulkWalk(oidIfXTable, func(dataUnit gosnmp.SnmpPDU) error {
+				if column, index, ok := prefixScan(ifXTablePrefixes, dataUnit.Name); ok {
+					collector.processIfTableDetail("IfXTable", ifXTableParserMap, column, index, dataUnit, interfaces)
+				}
+
+				return nil
+			})
+		}
+
+		if err != nil || len(interfaces) != 200 {
+			b.Fatalf("Expected 200 interfaces, got %d (%v)", len(interfaces), err)
+		}
+	}
+
+	b.ReportMetric(float64(agent.RequestCount(gosnmp.GetBulkRequest))/float64(b.N), "requests/op")
+}
+
+func BenchmarkGetIfTables_UsedColumns(b *testing.B) {
+	agent := startAgent(b, "testdata/switch.snmprec", snmpsim.Faults{})
+	collector := createTestCollector(agent)
+	target := connectTestTarget(b, agent)
+
+	for b.Loop() {
+		data := common.HostData{}
+
+		if !collector.getIfTable(target, &data, collector.lastInterfaceCount) || len(data.IfDataList) != 200 {
+			b.Fatalf("Expected 200 interfaces, got %d", len(data.IfDataList))
+		}
+
+		if !collector.getIfXTable(target, &data) {
+			b.Fatalf("Expected the ifXTable to be retrieved")
+		}
+
+		collector.lastInterfaceCount = len(data.IfDataList)
+	}
+
+	b.ReportMetric(float64(agent.RequestCount(gosnmp.GetBulkRequest))/float64(b.N), "requests/op")
+}
+
+// interfaceTableDataUnits returns the ifTable and ifXTable values of the switch, as they're received.
+func interfaceTableDataUnits(b *testing.B) []gosnmp.SnmpPDU {
+	records, err := snmpsim.LoadFile("testdata/switch.snmprec")
+
+	if err != nil {
+		b.Fatalf("Expected no error loading the switch, got %v", err)
+	}
+
+	result := make([]gosnmp.SnmpPDU, 0, len(records))
+
+	for _, record := range records {
+		if strings.HasPrefix(record.Oid, oidIfTable+".") || strings.HasPrefix(record.Oid, oidIfXTable+".") {
+			result = append(result, gosnmp.SnmpPDU{Name: record.Oid, Type: record.Type, Value: record.Value})
+		}
+	}
+
+	return result
+}
+
+func BenchmarkDispatch_PrefixScan(b *testing.B) {
+	dataUnits := interfaceTableDataUnits(b)
+	prefixes := columnPrefixes(ifTableParserMap)
+
+	for prefix, column := range columnPrefixes(ifXTableParserMap) {
+		prefixes[prefix] = column
+	}
+
+	for b.Loop() {
+		for _, dataUnit := range dataUnits {
+			_, _, _ = prefixScan(prefixes, dataUnit.Name)
+		}
+	}
+}
+
+func BenchmarkDispatch_ColumnLookup(b *testing.B) {
+	dataUnits := interfaceTableDataUnits(b)
+	collector := &snmpCollector{target: &Target{Name: "benchmark"}}
+
+	for b.Loop() {
+		for _, dataUnit := range dataUnits {
+			if column, _, ok := collector.parseIfOid(dataUnit.Name); ok {
+				_, _ = ifTableParserMap[column]
+				_, _ = ifXTableParserMap[column]
+			}
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+type fakeCollector struct {
+	name     string
+	enabled  bool
+	interval time.Duration
+	err      error
+	runs     int
+}
+
+func (c *fakeCollector) Name() string {
+	return c.name
+}
+
+func (c *fakeCollector) Enabled() bool {
+	return c.enabled
+}
+
+func (c *fakeCollector) Interval() time.Duration {
+	return c.interval
+}
+
+func (c *fakeCollector) Collect(_ context.Context, data *common.HostData) error {
+	c.runs++
+	data.UptimeSeconds = uint64(c.runs)
+
+	return c.err
+}
+
+func newTestTask(hostConfig config.Host, collectors ...Collector) (*Task, *[]common.HostData) {
+	hostConfig.Name = "test"
+	hostConfig.IpAddress = "127.0.0.1"
+	results := make([]common.HostData, 0)
+	task := CreateTask(context.Background(), host.NewHost("Host_1", hostConfig, tracking.Config{}, nil),
+		NewScheduler(SchedulerLimits{}, common.SystemClock), NewMetrics(),
+		func(_ *host.Host, hostData common.HostData) {
+			results = append(results, hostData)
+		})
+	task.collectors = collectors
+
+	return &task, &results
+}
+
+func TestCreateTask_BuiltInCollectors_CreatedInOrder(t *testing.T) {
+	task, _ := newTestTask(config.Host{})
+	task.collectors = createCollectors(&task.target)
+	names := make([]string, len(task.collectors))
+
+	for i, collector := range task.collectors {
+		names[i] = collector.Name()
+	}
+
+	expected := []string{common.CollectorPing, common.CollectorSnmp, common.CollectorCertificate}
+
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestScan_EnabledCollectors_RecordsResults(t *testing.T) {
+	ok := &fakeCollector{name: "ok", enabled: true}
+	failing := &fakeCollector{name: "failing", enabled: true, err: errors.New("refused")}
+	disabled := &fakeCollector{name: "disabled", enabled: false}
+	task, results := newTestTask(config.Host{}, ok, failing, disabled)
+
+	task.scan(false)
+
+	if len(*results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(*results))
+	}
+
+	collectorResults := (*results)[0].CollectorResults
+
+	if len(collectorResults) != 2 || disabled.runs != 0 {
+		t.Fatalf("Expected results for 2 collectors, got %v", collectorResults)
+	}
+
+	if collectorResults["ok"].Err != nil || collectorResults["ok"].StartTime.IsZero() {
+		t.Errorf("Expected a successful result, got %+v", collectorResults["ok"])
+	}
+
+	if collectorResults["failing"].Err == nil || collectorResults["failing"].Err.Error() != "refused" {
+		t.Errorf("Expected the collector's error, got %+v", collectorResults["failing"])
+	}
+
+	if !(*results)[0].Collected("ok") || (*results)[0].Collected("disabled") {
+		t.Errorf("Expected only enabled collectors to be collected")
+	}
+}
+
+func TestScan_ManualClock_TimesScanWithClock(t *testing.T) {
+	collector := &fakeCollector{name: "fake", enabled: true}
+	task, results := newTestTask(config.Host{}, collector)
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	task.clock = clock
+
+	task.scan(false)
+	clock.Advance(time.Minute)
+	task.scan(false)
+
+	if len(*results) != 2 || !(*results)[1].LastUpdateTime.Equal(clock.Now()) {
+		t.Fatalf("Expected the second scan at %v, got %v", clock.Now(), *results)
+	}
+
+	if !task.lastCollectionTimes["fake"].Equal(clock.Now()) {
+		t.Errorf("Expected the last collection at %v, got %v", clock.Now(), task.lastCollectionTimes["fake"])
+	}
+}
+
+func TestCollectorDue_DisabledInConfig_NotDue(t *testing.T) {
+	hostConfig := config.Host{}
+	hostConfig.ConfigureCollector("fake").Disable()
+	collector := &fakeCollector{name: "fake", enabled: true}
+	task, _ := newTestTask(hostConfig, collector)
+
+	if task.collectorDue(collector, time.Now(), false) {
+		t.Errorf("Expected a disabled collector not to be due")
+	}
+}
+
+func TestCollectorDue_Interval_WaitsForInterval(t *testing.T) {
+	collector := &fakeCollector{name: "fake", enabled: true, interval: 5 * time.Minute}
+	task, _ := newTestTask(config.Host{}, collector)
+	now := time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	if !task.collectorDue(collector, now, false) {
+		t.Errorf("Expected the first run to be due")
+	}
+
+	task.lastCollectionTimes["fake"] = now
+
+	if task.collectorDue(collector, now.Add(4*time.Minute), false) {
+		t.Errorf("Expected the collector not to be due after 4 minutes")
+	}
+
+	// Scans drift, so a scan slightly before the interval elapses still runs the collector
+	if !task.collectorDue(collector, now.Add(5*time.Minute-time.Second), false) {
+		t.Errorf("Expected the collector to be due after 5 minutes")
+	}
+}
+
+func TestCollectorDue_ConfiguredInterval_OverridesCollectorInterval(t *testing.T) {
+	hostConfig := config.Host{}
+	hostConfig.ConfigureCollector("fake").WithIntervalSeconds(3600)
+	collector := &fakeCollector{name: "fake", enabled: true}
+	task, _ := newTestTask(hostConfig, collector)
+	now := time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+	task.lastCollectionTimes["fake"] = now
+
+	if task.collectorDue(collector, now.Add(time.Minute), false) {
+		t.Errorf("Expected the collector not to be due after a minute")
+	}
+
+	if !task.collectorDue(collector, now.Add(time.Hour), false) {
+		t.Errorf("Expected the collector to be due after an hour")
+	}
+}
+
+func TestCollectorDue_RequestedScan_IgnoresInterval(t *testing.T) {
+	collector := &fakeCollector{name: "fake", enabled: true, interval: time.Hour}
+	task, _ := newTestTask(config.Host{}, collector)
+	now := time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+	task.lastCollectionTimes["fake"] = now
+
+	if !task.collectorDue(collector, now.Add(time.Minute), true) {
+		t.Errorf("Expected the collector to be due for a requested scan")
+	}
+}
+
+func TestWaitForTick_ScanRequestedTwice_ReturnsOneRequestedScan(t *testing.T) {
+	task, _ := newTestTask(config.Host{})
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	_ = task.host.RequestScan()
+	_ = task.host.RequestScan()
+	run, requested := task.waitForTick(ticker)
+
+	if !run || !requested {
+		t.Errorf("Expected a requested scan, got run = %v, requested = %v", run, requested)
+	}
+
+	if len(task.scanRequests) != 0 {
+		t.Errorf("Expected the requests to be coalesced, got %d pending", len(task.scanRequests))
+	}
+}
+
+func TestWaitForTick_ScanRequestedSoonAfterScan_WaitsForSpacing(t *testing.T) {
+	task, _ := newTestTask(config.Host{})
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	task.clock = clock
+	ctx, cancel := context.WithCancel(context.Background())
+	task.ctx = ctx
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	task.scan(false)
+	_ = task.host.RequestScan()
+
+	// The task is cancelled while it waits out the spacing
+	time.AfterFunc(50*time.Millisecond, cancel)
+	startTime := time.Now()
+	run, _ := task.waitForTick(ticker)
+
+	if run {
+		t.Errorf("Expected the task to be cancelled while waiting")
+	}
+
+	if elapsed := time.Since(startTime); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected the requested scan to wait, waited %v", elapsed)
+	}
+}
+
+func TestWaitForTick_ScanRequestedAfterSpacing_DoesNotWait(t *testing.T) {
+	task, _ := newTestTask(config.Host{})
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	task.clock = clock
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	task.scan(false)
+	clock.Advance(minRequestedScanSpacing)
+	_ = task.host.RequestScan()
+	startTime := time.Now()
+	run, requested := task.waitForTick(ticker)
+
+	if !run || !requested {
+		t.Errorf("Expected a requested scan, got run = %v, requested = %v", run, requested)
+	}
+
+	if elapsed := time.Since(startTime); elapsed > time.Second {
+		t.Errorf("Expected the requested scan not to wait, waited %v", elapsed)
+	}
+}
+
+func TestRegisterCollector_NewAndExistingNames_AddsOrReplaces(t *testing.T) {
+	original := slices.Clone(collectorRegistry)
+	t.Cleanup(func() { collectorRegistry = original })
+	replacement := &fakeCollector{name: common.CollectorSnmp}
+	custom := &fakeCollector{name: "tcp"}
+
+	RegisterCollector(common.CollectorSnmp, func(*Target) Collector { return replacement })
+	RegisterCollector("tcp", func(*Target) Collector { return custom })
+	collectors := createCollectors(&Target{Name: "test"})
+
+	if len(collectors) != 4 {
+		t.Fatalf("Expected 4 collectors, got %d", len(collectors))
+	}
+
+	if collectors[1] != replacement || collectors[3] != custom {
+		t.Errorf("Expected the replaced and custom collectors, got %v", collectors)
+	}
+}
+
+// fakePingCollector reports its packet loss as the ping result.
+type fakePingCollector struct {
+	fakeCollector
+	packetLoss float64
+}
+
+func (c *fakePingCollector) Collect(ctx context.Context, data *common.HostData) error {
+	data.PingPacketLoss = c.packetLoss
+
+	return c.fakeCollector.Collect(ctx, data)
+}
+
+func TestUpdateBackoff_FailedPings_BacksOffAtDownThreshold(t *testing.T) {
+	task, _ := newTestTask(config.Host{ReachabilityDownThreshold: 2})
+
+	task.updateBackoff(nil, 100)
+
+	if task.backoffInterval != 0 {
+		t.Errorf("Expected no backoff after 1 failed ping, got %v", task.backoffInterval)
+	}
+
+	task.updateBackoff(nil, 100)
+
+	if task.backoffInterval != time.Minute {
+		t.Errorf("Expected a backoff of 1m after 2 failed pings, got %v", task.backoffInterval)
+	}
+
+	task.updateBackoff(nil, 50)
+
+	if task.backoffInterval != 0 || task.failedPings != 0 {
+		t.Errorf("Expected the backoff to end, got %v after %d failed pings", task.backoffInterval, task.failedPings)
+	}
+}
+
+func TestExtendBackoff_Repeated_DoublesToCap(t *testing.T) {
+	task, _ := newTestTask(config.Host{ReachabilityDownThreshold: 1})
+	task.updateBackoff(nil, 100)
+	expected := []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute,
+		maxBackoffInterval, maxBackoffInterval}
+
+	for i, interval := range expected {
+		task.extendBackoff()
+
+		if task.backoffInterval != interval {
+			t.Errorf("Expected a backoff of %v after %d extensions, got %v", interval, i+1, task.backoffInterval)
+		}
+	}
+}
+
+func TestUpdateBackoff_PingError_CountsAsFailed(t *testing.T) {
+	task, _ := newTestTask(config.Host{ReachabilityDownThreshold: 1})
+
+	task.updateBackoff(errors.New("refused"), 0)
+
+	if task.backoffInterval != time.Minute {
+		t.Errorf("Expected a backoff of 1m, got %v", task.backoffInterval)
+	}
+}
+
+func TestScan_UnreachableHost_BacksOffSnmpButKeepsPinging(t *testing.T) {
+	ping := &fakePingCollector{fakeCollector: fakeCollector{name: common.CollectorPing, enabled: true}, packetLoss: 100}
+	snmp := &fakeCollector{name: common.CollectorSnmp, enabled: true}
+	task, results := newTestTask(config.Host{ReachabilityDownThreshold: 1}, ping, snmp)
+	clock := common.NewManualClock(time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+	task.clock = clock
+
+	// SNMP runs in the first scan, then every 2 minutes
+	for range 4 {
+		task.scan(false)
+		clock.Advance(time.Minute)
+	}
+
+	if ping.runs != 4 || snmp.runs != 2 {
+		t.Errorf("Expected 4 pings and 2 SNMP collections, got %d and %d", ping.runs, snmp.runs)
+	}
+
+	last := (*results)[len(*results)-1]
+
+	if !last.SnmpBackoff || last.SnmpInterval != 4*time.Minute {
+		t.Errorf("Expected an SNMP backoff of 4m, got %v, %v", last.SnmpBackoff, last.SnmpInterval)
+	}
+
+	// The host responds, so SNMP runs in the same scan
+	ping.packetLoss = 0
+	task.scan(false)
+	last = (*results)[len(*results)-1]
+
+	if snmp.runs != 3 || !last.Collected(common.CollectorSnmp) {
+		t.Errorf("Expected SNMP to run once the host responds, got %d runs", snmp.runs)
+	}
+
+	if last.SnmpBackoff || last.SnmpInterval != time.Minute {
+		t.Errorf("Expected no backoff and a 1m interval, got %v, %v", last.SnmpBackoff, last.SnmpInterval)
+	}
+}
@@ -61,3 +61,9 @@ func (s *stub) TrackingConfig() tracking.Config {
 		s.entityWrapperReference.Load(),
 		func(target entities.NetworkInterface) tracking.Config { return target.TrackingConfig() })
 }
+
+func (s *stub) SetHistoryRepo(trackingHistoryRepo tracking.TrackableHistoryRepo) error {
+	return common.ThreadSafeEntityWrapperExecValueFunc(
+		s.entityWrapperReference.Load(),
+		func(target entities.NetworkInterface) error { return target.SetHistoryRepo(trackingHistoryRepo) })
+}
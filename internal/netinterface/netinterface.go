@@ -3,6 +3,7 @@ package netinterface
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"net"
 	"slices"
 	"time"
@@ -14,37 +15,50 @@ import (
 	"github.com/avanha/pmaas-plugin-netmon/internal/common"
 	"github.com/avanha/pmaas-spi"
 	spicommon "github.com/avanha/pmaas-spi/common"
-	"github.com/avanha/pmaas-spi/events"
 	"github.com/avanha/pmaas-spi/tracking"
 
 	commonslices "github.com/avanha/pmaas-common/slices"
 )
 
 func CreateNetInterface(hostId string,
-	id string, trackingConfig tracking.Config, netInterface config.NetInterface) *NetInterface {
+	id string, trackingConfig tracking.Config, netInterface config.NetInterface,
+	historyRetention data.RateHistoryRetention, container spi.IPMAASContainer) *NetInterface {
 	return &NetInterface{
 		id:             id,
 		hostId:         hostId,
 		config:         netInterface,
 		trackingConfig: trackingConfig,
+		container:      container,
+		clock:          common.SystemClock,
 		data: data.NetInterfaceData{
-			Name: netInterface.TrackingName(),
+			Name:            netInterface.TrackingName(),
+			BytesInHistory:  data.NewRateHistory(historyRetention),
+			BytesOutHistory: data.NewRateHistory(historyRetention),
 		},
-		onAddressChangeListeners: netInterface.OnAddressChangeListeners(),
+		eventListeners: netInterface.EventListeners(),
 	}
 }
 
 type NetInterface struct {
-	id                                          string
-	hostId                                      string
-	trackingConfig                              tracking.Config
-	config                                      config.NetInterface
-	data                                        data.NetInterfaceData
-	pmaasEntityId                               string
-	hostPmaasEntityId                           string
-	stub                                        *stub
-	onAddressChangeListeners                    []func(event netmonevents.HostInterfaceAddressChangeEvent)
-	onAddressChangeListenersEventReceiverHandle int
+	id                                string
+	hostId                            string
+	trackingConfig                    tracking.Config
+	container                         spi.IPMAASContainer
+	clock                             common.Clock
+	trackingHistoryRepo               tracking.TrackableHistoryRepo
+	stateLoadStarted                  bool
+	config                            config.NetInterface
+	data                              data.NetInterfaceData
+	pmaasEntityId                     string
+	hostPmaasEntityId                 string
+	stub                              *stub
+	eventListeners                    []config.EventListener
+	eventListenersEventReceiverHandle int
+}
+
+// SetClock replaces the clock used to time samples.
+func (n *NetInterface) SetClock(clock common.Clock) {
+	n.clock = clock
 }
 
 func (n *NetInterface) Id() string {
@@ -55,15 +69,118 @@ func (n *NetInterface) TrackingConfig() tracking.Config {
 	return n.trackingConfig
 }
 
+// Data returns a sample with a copy of the interface's data, since the stub passes it to other goroutines.
 func (n *NetInterface) Data() tracking.DataSample {
 	return tracking.DataSample{
 		LastUpdateTime: n.data.LastUpdateTime,
-		Data:           n.data,
+		Data:           n.InterfaceData(),
 	}
 }
 
+// InterfaceData returns a copy of the interface's data, which is safe to read on other goroutines.
 func (n *NetInterface) InterfaceData() data.NetInterfaceData {
-	return n.data
+	result := n.data
+	result.BytesInHistory = n.data.BytesInHistory.Clone()
+	result.BytesOutHistory = n.data.BytesOutHistory.Clone()
+	result.Percentiles = n.data.Percentiles.Clone()
+
+	return result
+}
+
+// CurrentInterfaceData returns the interface's data without copying it.  It must only be read on the plugin
+// goroutine, and must not be modified.
+func (n *NetInterface) CurrentInterfaceData() *data.NetInterfaceData {
+	return &n.data
+}
+
+func (n *NetInterface) SetHistoryRepo(trackingHistoryRepo tracking.TrackableHistoryRepo) error {
+	newlyAvailable := n.trackingHistoryRepo == nil && trackingHistoryRepo != nil
+	n.trackingHistoryRepo = trackingHistoryRepo
+
+	if newlyAvailable && !n.stateLoadStarted {
+		n.stateLoadStarted = true
+		go n.loadStateFromHistory(trackingHistoryRepo)
+	}
+
+	return nil
+}
+
+func (n *NetInterface) loadStateFromHistory(historyRepo tracking.TrackableHistoryRepo) {
+	result := historyRepo.GetMostRecentSample()
+
+	if result.Error != nil {
+		fmt.Printf("NetInterface [%s]: Unable to load most recent sample: %v\n", n.id, result.Error)
+		return
+	}
+
+	err := n.container.EnqueueOnPluginGoRoutine(func() {
+		n.initFromSample(result.Result.Data.(data.NetInterfaceData))
+	})
+
+	if err != nil {
+		fmt.Printf("NetInterface [%s]: Unable to process retrieved most recent sample: %v\n", n.id, err)
+	}
+}
+
+// initFromSample restores the state that has to survive restarts: the bandwidth percentiles and the billing
+// cycle totals.
+func (n *NetInterface) initFromSample(interfaceData data.NetInterfaceData) {
+	n.restorePercentiles(interfaceData.BandwidthPercentiles)
+	n.restoreDataUsage(&interfaceData)
+}
+
+// restorePercentiles restores the bandwidth percentiles, but only if no percentile sample was completed since the
+// interface was created.
+func (n *NetInterface) restorePercentiles(encodedPercentiles string) {
+	percentiles, err := data.DecodeBandwidthPercentiles(encodedPercentiles)
+
+	if err != nil {
+		fmt.Printf("NetInterface [%s]: Unable to decode bandwidth percentiles: %v\n", n.id, err)
+		return
+	}
+
+	current := &n.data.Percentiles
+
+	if percentiles.In.PeriodStart.IsZero() || current.In.Samples != 0 || current.Out.Samples != 0 {
+		return
+	}
+
+	// Keep the interval in progress, it may already hold samples
+	if current.In.IntervalCount != 0 {
+		percentiles.In.IntervalStart, percentiles.In.IntervalSum, percentiles.In.IntervalCount =
+			current.In.IntervalStart, current.In.IntervalSum, current.In.IntervalCount
+		percentiles.Out.IntervalStart, percentiles.Out.IntervalSum, percentiles.Out.IntervalCount =
+			current.Out.IntervalStart, current.Out.IntervalSum, current.Out.IntervalCount
+	}
+
+	n.data.Percentiles = percentiles
+	n.data.BandwidthPercentiles = data.EncodeBandwidthPercentiles(&n.data.Percentiles)
+}
+
+// restoreDataUsage adds the billing cycle totals of the sample to those counted since the interface was created,
+// if they belong to the same cycle.  The data cap percentages the restored usage already reached don't raise
+// events again.
+func (n *NetInterface) restoreDataUsage(interfaceData *data.NetInterfaceData) {
+	if interfaceData.BillingCycleStart.IsZero() {
+		return
+	}
+
+	if n.data.BillingCycleStart.IsZero() {
+		n.data.BillingCycleStart = interfaceData.BillingCycleStart
+		n.data.BillingCycleEnd = data.BillingCycleEnd(interfaceData.BillingCycleStart, n.config.BillingCycleStartDay)
+	} else if !n.data.BillingCycleStart.Equal(interfaceData.BillingCycleStart) {
+		return
+	}
+
+	n.data.CycleBytesIn += interfaceData.CycleBytesIn
+	n.data.CycleBytesOut += interfaceData.CycleBytesOut
+
+	n.data.DataCap = n.config.DataCap
+
+	if n.data.DataCap != 0 {
+		n.data.DataCapUsage = dataCapUsage(n.config.DataCapDirection, n.data.CycleBytesIn, n.data.CycleBytesOut)
+		n.data.DataCapPercentReached = max(n.data.DataCapPercentReached, n.dataCapPercentReached())
+	}
 }
 
 func (n *NetInterface) PmaasEntityId() string {
@@ -123,11 +240,12 @@ func (n *NetInterface) Update(
 	hostEvent *netmonevents.HostEvent,
 	events *[]any) {
 	hostInterfaceEvent := netmonevents.HostInterfaceEvent{
-		HostEvent:    *hostEvent,
-		NetInterface: n.pmaasEntityId,
+		HostEvent:        *hostEvent,
+		NetInterface:     n.pmaasEntityId,
+		NetInterfaceName: n.data.Name,
 	}
 
-	now := time.Now()
+	now := n.clock.Now()
 	var elapsedSeconds uint64 = 0
 
 	if !n.data.LastUpdateTime.IsZero() {
@@ -145,26 +263,131 @@ func (n *NetInterface) Update(
 		n.data.PhysAddress = ifData.PhysAddress
 	}
 
+	var deltaPackets uint64 = 0
+
+	if elapsedSeconds != 0 {
+		// Computed before the traffic stats update replaces the packet counters
+		deltaPackets = counterIncrease(hostData.UptimeSeconds, elapsedSeconds,
+			n.data.PacketsIn, ifData.GetAllInPackets(), ifData.GetAllInPacketsMaxValue()) +
+			counterIncrease(hostData.UptimeSeconds, elapsedSeconds,
+				n.data.PacketsOut, ifData.GetAllOutPackets(), ifData.GetAllOutPacketsMaxValue())
+	}
+
 	n.updateStatus(ifData, &hostInterfaceEvent, events)
+	n.updateLastChangeTime(hostData, ifData)
+	n.updateLinkProperties(ifData, &hostInterfaceEvent, events)
 	n.updateIpAddresses(ifData, &hostInterfaceEvent, events)
 	n.updateTrafficStats(hostData.UptimeSeconds, elapsedSeconds, ifData, &hostInterfaceEvent, events)
-	n.updateErrorStats(ifData, &hostInterfaceEvent, events)
-	n.updateDiscardStats(ifData, &hostInterfaceEvent, events)
+	n.updatePacketRates(hostData.UptimeSeconds, elapsedSeconds, ifData, &hostInterfaceEvent, events)
+	n.updateErrorStats(hostData.UptimeSeconds, elapsedSeconds, deltaPackets, ifData, &hostInterfaceEvent, events)
+	n.updateDiscardStats(hostData.UptimeSeconds, elapsedSeconds, ifData, &hostInterfaceEvent, events)
 }
 
+// updateStatus updates the operational and administrative status, and raises an event when either changes.  The
+// event includes both, so listeners can tell an interface that was disabled from one that failed.
 func (n *NetInterface) updateStatus(ifData *common.IfData, hostInterfaceEvent *netmonevents.HostInterfaceEvent, events *[]any) {
 	currentStatus := n.data.Status
 	newStatus := describeStatus(ifData.OperStatus)
+	currentAdminStatus := n.data.AdminStatus
+	newAdminStatus := describeAdminStatus(ifData.AdminStatus)
 
-	if newStatus != currentStatus {
+	if newStatus != currentStatus || newAdminStatus != currentAdminStatus {
 		event := netmonevents.HostInterfaceStatusChangeEvent{
 			HostInterfaceEvent: *hostInterfaceEvent,
 			OldValue:           currentStatus,
 			NewValue:           newStatus,
+			OldAdminStatus:     currentAdminStatus,
+			NewAdminStatus:     newAdminStatus,
 		}
 		*events = append(*events, event)
 	}
 	n.data.Status = newStatus
+	n.data.AdminStatus = newAdminStatus
+}
+
+// lastChangeTolerance absorbs the jitter of the last change time, which is derived from the device's uptime and
+// the time of the scan, so it differs slightly from scan to scan.
+const lastChangeTolerance = 5 * time.Second
+
+// updateLastChangeTime converts ifLastChange, the device uptime at which the interface entered its current state,
+// to wall-clock time.  A value of zero means the state was entered before the device's SNMP agent started, so
+// the device's boot time is used.
+func (n *NetInterface) updateLastChangeTime(hostData *common.HostData, ifData *common.IfData) {
+	if hostData.UptimeSeconds == 0 || uint64(ifData.LastChangeSeconds) > hostData.UptimeSeconds {
+		// Either the uptime is unknown, or it wrapped around since the change
+		return
+	}
+
+	scanTime := hostData.LastUpdateTime
+
+	if scanTime.IsZero() {
+		scanTime = n.data.LastUpdateTime
+	}
+
+	lastChangeTime := scanTime.Add(
+		-time.Duration(hostData.UptimeSeconds-uint64(ifData.LastChangeSeconds)) * time.Second).Truncate(time.Second)
+	difference := lastChangeTime.Sub(n.data.LastChangeTime)
+
+	if difference > lastChangeTolerance || difference < -lastChangeTolerance {
+		n.data.LastChangeTime = lastChangeTime
+	}
+}
+
+// updateLinkProperties updates the speed, duplex status and MTU, and raises events when they change.  Values the
+// device doesn't report are left as they are, and no events are raised for the first values reported.
+func (n *NetInterface) updateLinkProperties(
+	ifData *common.IfData,
+	hostInterfaceEvent *netmonevents.HostInterfaceEvent,
+	events *[]any) {
+	if newSpeed := ifData.GetSpeed(); newSpeed != 0 && newSpeed != n.data.Speed {
+		if n.data.Speed != 0 {
+			*events = append(*events, netmonevents.HostInterfaceSpeedChangeEvent{
+				HostInterfaceEvent: *hostInterfaceEvent,
+				OldValue:           n.data.Speed,
+				NewValue:           newSpeed,
+			})
+		}
+
+		n.data.Speed = newSpeed
+	}
+
+	if newDuplex := describeDuplexStatus(ifData.DuplexStatus); newDuplex != "" && newDuplex != n.data.Duplex {
+		if n.data.Duplex != "" {
+			*events = append(*events, netmonevents.HostInterfaceDuplexChangeEvent{
+				HostInterfaceEvent: *hostInterfaceEvent,
+				OldValue:           n.data.Duplex,
+				NewValue:           newDuplex,
+			})
+		}
+
+		n.data.Duplex = newDuplex
+	}
+
+	if ifData.Mtu > 0 && ifData.Mtu != n.data.Mtu {
+		if n.data.Mtu != 0 {
+			*events = append(*events, netmonevents.HostInterfaceMtuChangeEvent{
+				HostInterfaceEvent: *hostInterfaceEvent,
+				OldValue:           n.data.Mtu,
+				NewValue:           ifData.Mtu,
+			})
+		}
+
+		n.data.Mtu = ifData.Mtu
+	}
+}
+
+// describeDuplexStatus returns the name of a dot3StatsDuplexStatus value, or empty if the device didn't report it.
+func describeDuplexStatus(duplexStatus int32) string {
+	switch duplexStatus {
+	case 0:
+		return ""
+	case 2:
+		return "Half"
+	case 3:
+		return "Full"
+	default:
+		return "Unknown"
+	}
 }
 
 func (n *NetInterface) updateIpAddresses(ifData *common.IfData, hostInterfaceEvent *netmonevents.HostInterfaceEvent, events *[]any) {
@@ -179,7 +402,7 @@ func (n *NetInterface) updateIpAddresses(ifData *common.IfData, hostInterfaceEve
 
 	if len(newIpv4Addresses) > 0 && !slices.Equal(currentIpv4Addresses, newIpv4Addresses) {
 		n.data.IpV4Addresses = newIpv4Addresses
-		n.data.LastIpV4AddressChangeTime = time.Now()
+		n.data.LastIpV4AddressChangeTime = n.clock.Now()
 	}
 
 	currentIpAddresses := n.data.IpAddresses
@@ -199,7 +422,7 @@ func (n *NetInterface) updateIpAddresses(ifData *common.IfData, hostInterfaceEve
 		}
 		*events = append(*events, event)
 		n.data.IpAddresses = newAddresses
-		n.data.LastIpAddressesChangeTime = time.Now()
+		n.data.LastIpAddressesChangeTime = n.clock.Now()
 	}
 }
 
@@ -215,14 +438,37 @@ func IpAddressSortFunc(a, b common.IpMapEntry) int {
 	return bytes.Compare(a.Address, b.Address)
 }
 
+// describeStatus returns the name of an ifOperStatus value.
 func describeStatus(operStatus int32) string {
 	switch operStatus {
 	case 1:
-		return "Up"
+		return data.InterfaceStatusUp
 	case 2:
-		return "Down"
+		return data.InterfaceStatusDown
+	case 3:
+		return data.InterfaceStatusTesting
+	case 5:
+		return data.InterfaceStatusDormant
+	case 6:
+		return data.InterfaceStatusNotPresent
+	case 7:
+		return data.InterfaceStatusLowerLayerDown
 	default:
-		return "Unknown"
+		return data.InterfaceStatusUnknown
+	}
+}
+
+// describeAdminStatus returns the name of an ifAdminStatus value.
+func describeAdminStatus(adminStatus int32) string {
+	switch adminStatus {
+	case 1:
+		return data.InterfaceStatusUp
+	case 2:
+		return data.InterfaceStatusDown
+	case 3:
+		return data.InterfaceStatusTesting
+	default:
+		return data.InterfaceStatusUnknown
 	}
 }
 
@@ -239,30 +485,34 @@ func (n *NetInterface) updateTrafficStats(
 	newBytesOut := ifData.GetOutOctets()
 	newPacketsIn := ifData.GetAllInPackets()
 	newPacketsOut := ifData.GetAllOutPackets()
+	var deltaBytesIn, deltaBytesOut uint64
 
 	if elapsedSeconds != 0 {
-		var deltaBytesIn, deltaBytesOut uint64
-
-		if uptimeSeconds <= elapsedSeconds {
-			// The device restarted, so counters reset
-			deltaBytesIn = newBytesIn
-			deltaBytesOut = newBytesOut
-		} else if newBytesIn < currentBytesIn || newBytesOut < currentBytesOut {
-			// This indicates a rollover.  We need to grab the max value from ifData since it can differ by source:
-			// ifTable uses 32-bit vaues, while ifXTable uses 64-bit values.
-			deltaBytesIn = ifData.GetInOctetsMaxValue() - currentBytesIn + newBytesIn
-			deltaBytesOut = ifData.GetOutOctetsMaxValue() - currentBytesOut + newBytesOut
-		} else {
-			deltaBytesIn = newBytesIn - currentBytesIn
-			deltaBytesOut = newBytesOut - currentBytesOut
+		// The max value comes from ifData since it differs by source: ifTable uses 32-bit values, while ifXTable
+		// uses 64-bit values.
+		deltaBytesIn = counterIncrease(uptimeSeconds, elapsedSeconds,
+			currentBytesIn, newBytesIn, ifData.GetInOctetsMaxValue())
+		deltaBytesOut = counterIncrease(uptimeSeconds, elapsedSeconds,
+			currentBytesOut, newBytesOut, ifData.GetOutOctetsMaxValue())
+
+		n.data.BytesInRate = deltaBytesIn / elapsedSeconds
+		n.data.BytesOutRate = deltaBytesOut / elapsedSeconds
+		n.data.BytesInHistory.Add(n.data.LastUpdateTime, float64(deltaBytesIn)/float64(elapsedSeconds))
+		n.data.BytesOutHistory.Add(n.data.LastUpdateTime, float64(deltaBytesOut)/float64(elapsedSeconds))
+		n.updatePercentiles(float64(deltaBytesIn)/float64(elapsedSeconds),
+			float64(deltaBytesOut)/float64(elapsedSeconds))
+
+		if n.data.Speed != 0 {
+			// Utilization is based on the busier direction, since interfaces are normally full duplex
+			bitsPerSecond := float64(max(deltaBytesIn, deltaBytesOut)*8) / float64(elapsedSeconds)
+			n.data.Utilization = 100.0 * bitsPerSecond / float64(n.data.Speed)
 		}
 
-		n.data.BytesInRateHistory[n.data.CurrentHistoryIndex] = deltaBytesIn / elapsedSeconds
-		n.data.BytesOutRateHistory[n.data.CurrentHistoryIndex] = deltaBytesOut / elapsedSeconds
-
 		n.updateDailyTotals(n.data.LastUpdateTime, elapsedSeconds, deltaBytesIn, deltaBytesOut)
 	}
 
+	n.updateDataUsage(deltaBytesIn, deltaBytesOut, hostInterfaceEvent, events)
+
 	if currentBytesIn != newBytesIn ||
 		currentBytesOut != newBytesOut ||
 		currentPacketsIn != newPacketsIn ||
@@ -286,6 +536,151 @@ func (n *NetInterface) updateTrafficStats(
 	}
 }
 
+// updatePacketRates computes the packet rates per cast type over the elapsed interval, and raises an event when
+// the broadcast rate crosses the configured storm threshold.  Multicast and broadcast packets are only counted
+// by ifXTable, so their rates stay zero for devices that don't support it.
+func (n *NetInterface) updatePacketRates(
+	uptimeSeconds uint64, elapsedSeconds uint64,
+	ifData *common.IfData,
+	hostInterfaceEvent *netmonevents.HostInterfaceEvent,
+	events *[]any) {
+	n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.UnicastPacketsIn, ifData.GetInUcastPkts(),
+		ifData.GetInUcastPktsMaxValue(), &n.data.UnicastInRateHistory)
+	n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.UnicastPacketsOut, ifData.GetOutUcastPkts(),
+		ifData.GetOutUcastPktsMaxValue(), &n.data.UnicastOutRateHistory)
+	n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.MulticastPacketsIn, ifData.HCInMulticastPkts,
+		math.MaxUint64, &n.data.MulticastInRateHistory)
+	n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.MulticastPacketsOut, ifData.HCOutMulticastPkts,
+		math.MaxUint64, &n.data.MulticastOutRateHistory)
+	broadcastInRate := n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.BroadcastPacketsIn,
+		ifData.HCInBroadcastPkts, math.MaxUint64, &n.data.BroadcastInRateHistory)
+	broadcastOutRate := n.updatePacketRate(uptimeSeconds, elapsedSeconds, &n.data.BroadcastPacketsOut,
+		ifData.HCOutBroadcastPkts, math.MaxUint64, &n.data.BroadcastOutRateHistory)
+
+	if elapsedSeconds == 0 {
+		return
+	}
+
+	if crossesThreshold(max(broadcastInRate, broadcastOutRate), n.config.BroadcastStormThreshold,
+		&n.data.BroadcastStorm) {
+		*events = append(*events, netmonevents.HostInterfaceBroadcastStormEvent{
+			HostInterfaceEvent: *hostInterfaceEvent,
+			BroadcastInRate:    broadcastInRate,
+			BroadcastOutRate:   broadcastOutRate,
+			Threshold:          n.config.BroadcastStormThreshold,
+			Active:             n.data.BroadcastStorm,
+		})
+	}
+}
+
+// updatePacketRate records the rate of a packet counter in its history, and stores the counter's new value.
+// Returns the rate, or zero if no time elapsed since the previous sample.
+func (n *NetInterface) updatePacketRate(
+	uptimeSeconds uint64, elapsedSeconds uint64,
+	counter *uint64, newValue uint64, maxValue uint64,
+	history *[data.NetInterfaceDataHistorySize]float64) float64 {
+	rate := 0.0
+
+	if elapsedSeconds != 0 {
+		rate = float64(counterIncrease(uptimeSeconds, elapsedSeconds, *counter, newValue, maxValue)) /
+			float64(elapsedSeconds)
+		history[n.data.CurrentHistoryIndex] = rate
+	}
+
+	*counter = newValue
+
+	return rate
+}
+
+// updatePercentiles adds the rates to the 95th percentile trackers.  The persisted state is only updated when a
+// 5-minute sample completes, since that's when the result can change.
+func (n *NetInterface) updatePercentiles(bytesInRate float64, bytesOutRate float64) {
+	periodStart := data.BillingCycleStart(n.data.LastUpdateTime, n.config.BillingCycleStartDay)
+	samples := n.data.Percentiles.In.Samples
+
+	n.data.Percentiles.In.Add(n.data.LastUpdateTime, bytesInRate, periodStart)
+	n.data.Percentiles.Out.Add(n.data.LastUpdateTime, bytesOutRate, periodStart)
+
+	if n.data.Percentiles.In.Samples != samples || n.data.BandwidthPercentiles == "" {
+		n.data.BandwidthPercentiles = data.EncodeBandwidthPercentiles(&n.data.Percentiles)
+	}
+}
+
+// updateDataUsage adds the transferred bytes to the billing cycle totals, starting over when a new cycle starts,
+// and raises an event when the usage reaches one of the configured percentages of the data cap.  The bytes of an
+// interval that spans the start of a cycle are counted in the new cycle.
+func (n *NetInterface) updateDataUsage(
+	deltaBytesIn uint64, deltaBytesOut uint64,
+	hostInterfaceEvent *netmonevents.HostInterfaceEvent,
+	events *[]any) {
+	cycleStart := data.BillingCycleStart(n.data.LastUpdateTime, n.config.BillingCycleStartDay)
+
+	if !cycleStart.Equal(n.data.BillingCycleStart) {
+		n.data.BillingCycleStart = cycleStart
+		n.data.BillingCycleEnd = data.BillingCycleEnd(cycleStart, n.config.BillingCycleStartDay)
+		n.data.CycleBytesIn = 0
+		n.data.CycleBytesOut = 0
+		n.data.DataCapPercentReached = 0
+	}
+
+	n.data.CycleBytesIn += deltaBytesIn
+	n.data.CycleBytesOut += deltaBytesOut
+	n.data.DataCap = n.config.DataCap
+
+	if n.data.DataCap == 0 {
+		return
+	}
+
+	n.data.DataCapUsage = dataCapUsage(n.config.DataCapDirection, n.data.CycleBytesIn, n.data.CycleBytesOut)
+	n.data.ProjectedDataCapUsage = data.ProjectedUsage(n.data.DataCapUsage,
+		n.data.BillingCycleStart, n.data.BillingCycleEnd, n.data.LastUpdateTime)
+	percentReached := n.dataCapPercentReached()
+
+	if percentReached > n.data.DataCapPercentReached {
+		n.data.DataCapPercentReached = percentReached
+		*events = append(*events, netmonevents.HostInterfaceDataCapEvent{
+			HostInterfaceEvent: *hostInterfaceEvent,
+			Usage:              n.data.DataCapUsage,
+			DataCap:            n.data.DataCap,
+			Percent:            percentReached,
+			ProjectedUsage:     n.data.ProjectedDataCapUsage,
+			CycleStart:         n.data.BillingCycleStart,
+			CycleEnd:           n.data.BillingCycleEnd,
+		})
+	}
+}
+
+// dataCapPercentReached returns the highest configured alert percentage the usage has reached, or zero if none.
+func (n *NetInterface) dataCapPercentReached() float64 {
+	alertPercents := n.config.DataCapAlertPercents
+
+	if len(alertPercents) == 0 {
+		alertPercents = config.DefaultDataCapAlertPercents
+	}
+
+	usagePercent := n.data.GetDataCapUsagePercent()
+	result := 0.0
+
+	for _, percent := range alertPercents {
+		if usagePercent >= percent {
+			result = max(result, percent)
+		}
+	}
+
+	return result
+}
+
+func dataCapUsage(direction config.DataCapDirection, bytesIn uint64, bytesOut uint64) uint64 {
+	switch direction {
+	case config.DataCapIn:
+		return bytesIn
+	case config.DataCapOut:
+		return bytesOut
+	default:
+		return bytesIn + bytesOut
+	}
+}
+
 // updateDailyTotals tracks the total incoming and outgoing bytes on a daily basis.
 // It uses linear interpolation to accurately distribute the traffic across days
 // if the given time interval crosses one or more midnight boundaries.
@@ -334,108 +729,133 @@ func stepDailyHistoryIndex(index uint) uint {
 	return index + 1
 }
 
-func (n *NetInterface) updateErrorStats(ifData *common.IfData, hostInterfaceEvent *netmonevents.HostInterfaceEvent, events *[]any) {
-	currentErrorsIn := n.data.ErrorsIn
-	currentErrorsOut := n.data.ErrorsOut
+// updateErrorStats computes the error rates and the error ratio over the elapsed interval, and raises an event
+// when the combined rate crosses the configured threshold.  deltaPackets is the number of packets sent and
+// received over the same interval.
+func (n *NetInterface) updateErrorStats(
+	uptimeSeconds uint64, elapsedSeconds uint64, deltaPackets uint64,
+	ifData *common.IfData,
+	hostInterfaceEvent *netmonevents.HostInterfaceEvent,
+	events *[]any) {
 	newErrorsIn := ifData.GetInErrors()
 	newErrorsOut := ifData.GetOutErrors()
 
-	if currentErrorsIn != newErrorsIn ||
-		currentErrorsOut != newErrorsOut {
-		event := netmonevents.HostInterfaceErrorStatsChangeEvent{
-			HostInterfaceEvent: *hostInterfaceEvent,
-			OldErrorsIn:        currentErrorsIn,
-			NewErrorsIn:        newErrorsOut,
-			OldErrorsOut:       currentErrorsOut,
-			NewErrorsOut:       newErrorsOut,
+	if elapsedSeconds != 0 {
+		deltaErrorsIn := counterIncrease(uptimeSeconds, elapsedSeconds,
+			n.data.ErrorsIn, newErrorsIn, ifData.GetErrorsMaxValue())
+		deltaErrorsOut := counterIncrease(uptimeSeconds, elapsedSeconds,
+			n.data.ErrorsOut, newErrorsOut, ifData.GetErrorsMaxValue())
+		errorsInRate := float64(deltaErrorsIn) / float64(elapsedSeconds)
+		errorsOutRate := float64(deltaErrorsOut) / float64(elapsedSeconds)
+
+		n.data.ErrorsInRateHistory[n.data.CurrentHistoryIndex] = errorsInRate
+		n.data.ErrorsOutRateHistory[n.data.CurrentHistoryIndex] = errorsOutRate
+		n.data.ErrorRate = errorsInRate + errorsOutRate
+		n.data.ErrorRatio = 0
+
+		if deltaPackets != 0 {
+			n.data.ErrorRatio = float64(deltaErrorsIn+deltaErrorsOut) / float64(deltaPackets)
+		}
+
+		if crossesThreshold(n.data.ErrorRate, n.config.ErrorRateThreshold, &n.data.ErrorRateExceeded) {
+			*events = append(*events, netmonevents.HostInterfaceErrorRateEvent{
+				HostInterfaceEvent: *hostInterfaceEvent,
+				ErrorsInRate:       errorsInRate,
+				ErrorsOutRate:      errorsOutRate,
+				ErrorRatio:         n.data.ErrorRatio,
+				Threshold:          n.config.ErrorRateThreshold,
+				Exceeded:           n.data.ErrorRateExceeded,
+			})
 		}
-		*events = append(*events, event)
-		n.data.ErrorsIn = newErrorsIn
-		n.data.ErrorsOut = newErrorsOut
 	}
+
+	n.data.ErrorsIn = newErrorsIn
+	n.data.ErrorsOut = newErrorsOut
 }
 
-func (n *NetInterface) updateDiscardStats(ifData *common.IfData, hostInterfaceEvent *netmonevents.HostInterfaceEvent, events *[]any) {
-	currentDiscardsIn := n.data.DiscardsIn
-	currentDiscardsOut := n.data.DiscardsOut
+// updateDiscardStats computes the discard rates over the elapsed interval, and raises an event when the combined
+// rate crosses the configured threshold.
+func (n *NetInterface) updateDiscardStats(
+	uptimeSeconds uint64, elapsedSeconds uint64,
+	ifData *common.IfData,
+	hostInterfaceEvent *netmonevents.HostInterfaceEvent,
+	events *[]any) {
 	newDiscardsIn := ifData.GetInDiscards()
 	newDiscardsOut := ifData.GetOutDiscards()
 
-	if currentDiscardsIn != newDiscardsIn ||
-		currentDiscardsOut != newDiscardsOut {
-		event := netmonevents.HostInterfaceDiscardStatsChangeEvent{
-			HostInterfaceEvent: *hostInterfaceEvent,
-			OldDiscardsIn:      currentDiscardsIn,
-			NewDiscardsIn:      newDiscardsIn,
-			OldDiscardsOut:     currentDiscardsOut,
-			NewDiscardsOut:     newDiscardsOut,
+	if elapsedSeconds != 0 {
+		discardsInRate := float64(counterIncrease(uptimeSeconds, elapsedSeconds,
+			n.data.DiscardsIn, newDiscardsIn, ifData.GetErrorsMaxValue())) / float64(elapsedSeconds)
+		discardsOutRate := float64(counterIncrease(uptimeSeconds, elapsedSeconds,
+			n.data.DiscardsOut, newDiscardsOut, ifData.GetErrorsMaxValue())) / float64(elapsedSeconds)
+
+		n.data.DiscardsInRateHistory[n.data.CurrentHistoryIndex] = discardsInRate
+		n.data.DiscardsOutRateHistory[n.data.CurrentHistoryIndex] = discardsOutRate
+		n.data.DiscardRate = discardsInRate + discardsOutRate
+
+		if crossesThreshold(n.data.DiscardRate, n.config.DiscardRateThreshold, &n.data.DiscardRateExceeded) {
+			*events = append(*events, netmonevents.HostInterfaceDiscardRateEvent{
+				HostInterfaceEvent: *hostInterfaceEvent,
+				DiscardsInRate:     discardsInRate,
+				DiscardsOutRate:    discardsOutRate,
+				Threshold:          n.config.DiscardRateThreshold,
+				Exceeded:           n.data.DiscardRateExceeded,
+			})
 		}
-		*events = append(*events, event)
-		n.data.DiscardsIn = newDiscardsIn
-		n.data.DiscardsOut = newDiscardsOut
 	}
+
+	n.data.DiscardsIn = newDiscardsIn
+	n.data.DiscardsOut = newDiscardsOut
 }
 
-func (n *NetInterface) RegisterConfiguredListeners(container spi.IPMAASContainer) {
-	if len(n.onAddressChangeListeners) == 0 {
-		return
+// crossesThreshold updates whether a rate exceeds its threshold, and returns true if that changed.  A threshold
+// of zero or less disables the check.
+func crossesThreshold(rate float64, threshold float64, exceeded *bool) bool {
+	if threshold <= 0 {
+		*exceeded = false
+		return false
 	}
 
-	hostPmaasEntityId := n.hostPmaasEntityId
-	interfacePmaasEntityId := n.pmaasEntityId
-
-	predicate := func(info *events.EventInfo) bool {
-		if info.SourceEntityId != hostPmaasEntityId {
-			return false
-		}
-
-		event, ok := info.Event.(netmonevents.HostInterfaceAddressChangeEvent)
+	newExceeded := rate > threshold
+	changed := newExceeded != *exceeded
+	*exceeded = newExceeded
 
-		if !ok {
-			return false
-		}
-
-		return event.NetInterface == interfacePmaasEntityId
-	}
-
-	receiver := func(info *events.EventInfo) error {
-		e := info.Event.(netmonevents.HostInterfaceAddressChangeEvent)
-		invocations := make([]func(), len(n.onAddressChangeListeners))
-		for i, listener := range n.onAddressChangeListeners {
-			invocations[i] = func() {
-				listener(e)
-			}
-		}
+	return changed
+}
 
-		err := container.EnqueueOnServerGoRoutine(invocations)
+type hostInterfaceEventSource interface {
+	GetHostInterfaceEvent() netmonevents.HostInterfaceEvent
+}
 
-		if err != nil {
-			return fmt.Errorf("error enqueuing HostInterfaceAddressChangeEvent listener invocation: %w\n", err)
-		}
+// RegisterConfiguredListeners registers the listeners added to the interface's configuration.  Interface events
+// are broadcast by the host, so only the host's events that concern this interface are passed to them.
+func (n *NetInterface) RegisterConfiguredListeners(container spi.IPMAASContainer) {
+	interfacePmaasEntityId := n.pmaasEntityId
 
-		return nil
-	}
+	handle, err := common.RegisterEventListeners(container, n.hostPmaasEntityId, n.eventListeners, func(event any) bool {
+		source, ok := event.(hostInterfaceEventSource)
 
-	handle, err := container.RegisterEventReceiver(predicate, receiver)
+		return ok && source.GetHostInterfaceEvent().NetInterface == interfacePmaasEntityId
+	})
 
 	if err != nil {
 		panic(fmt.Errorf("failed to register event receiver for interface %s: %w", n.id, err))
 	}
 
-	n.onAddressChangeListenersEventReceiverHandle = handle
+	n.eventListenersEventReceiverHandle = handle
 }
 
 func (n *NetInterface) DeregisterConfiguredListeners(container spi.IPMAASContainer) {
-	if n.onAddressChangeListenersEventReceiverHandle == 0 {
+	if n.eventListenersEventReceiverHandle == 0 {
 		return
 	}
 
-	err := container.DeregisterEventReceiver(n.onAddressChangeListenersEventReceiverHandle)
+	err := container.DeregisterEventReceiver(n.eventListenersEventReceiverHandle)
 
 	if err == nil {
-		n.onAddressChangeListenersEventReceiverHandle = 0
+		n.eventListenersEventReceiverHandle = 0
 	} else {
-		fmt.Printf("Error deregistering receiver for HostInterfaceAddressChangeEvent: %s\n", err)
+		fmt.Printf("Error deregistering receiver for interface %s listeners: %s\n", n.id, err)
 	}
 }
 
@@ -451,6 +871,21 @@ func filter[S any](inputs []S, predicate func(entry *S) bool) []S {
 	return results
 }
 
+// counterIncrease returns the increase of a counter between two samples.  If the device restarted within the
+// elapsed interval, the counter started over from zero.  Otherwise, a decrease means the counter wrapped around
+// at maxValue.  An uptime of zero means it wasn't read, so a restart can't be detected.
+func counterIncrease(uptimeSeconds uint64, elapsedSeconds uint64, current uint64, new uint64, maxValue uint64) uint64 {
+	if uptimeSeconds != 0 && uptimeSeconds <= elapsedSeconds {
+		return new
+	}
+
+	if new < current {
+		return maxValue - current + new
+	}
+
+	return new - current
+}
+
 func stepHistoryIndex(index uint) uint {
 	if index == data.NetInterfaceDataHistorySize-1 {
 		return 0
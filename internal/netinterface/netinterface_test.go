@@ -1,8 +1,18 @@
 package netinterface
 
 import (
+	"math"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/entities"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/fakecontainer"
+	"github.com/avanha/pmaas-spi/tracking"
 )
 
 func TestUpdateDailyTotals_SameDay(t *testing.T) {
@@ -100,3 +110,370 @@ func TestUpdateDailyTotals_RolloverHistoryIndex(t *testing.T) {
 		t.Errorf("Expected Day 0 DailyBytesIn 50, got %d", n.data.DailyBytesIn[0])
 	}
 }
+
+func updateSample(n *NetInterface, elapsedSeconds int, ifData *common.IfData) []any {
+	events := make([]any, 0)
+
+	if elapsedSeconds != 0 {
+		n.data.LastUpdateTime = time.Now().Add(-time.Duration(elapsedSeconds) * time.Second)
+	}
+
+	n.Update(&common.HostData{UptimeSeconds: 100000}, ifData, &netmonevents.HostEvent{}, &events)
+
+	return events
+}
+
+func countErrorRateEvents(events []any) (int, bool) {
+	count := 0
+	exceeded := false
+
+	for _, event := range events {
+		if e, ok := event.(netmonevents.HostInterfaceErrorRateEvent); ok {
+			count++
+			exceeded = e.Exceeded
+		}
+	}
+
+	return count, exceeded
+}
+
+func TestUpdateErrorStats_RateCrossesThreshold_RaisesEvents(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+		ErrorRateThreshold: 1.0,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	updateSample(n, 0, &common.IfData{InErrors: 100, InUcastPkts: 1000})
+
+	// 5 errors in 10 seconds is below the threshold
+	events := updateSample(n, 10, &common.IfData{InErrors: 105, InUcastPkts: 2000})
+
+	if count, _ := countErrorRateEvents(events); count != 0 {
+		t.Errorf("Expected no events, got %d", count)
+	}
+
+	if n.data.ErrorRate != 0.5 {
+		t.Errorf("Expected 0.5, got %f", n.data.ErrorRate)
+	}
+
+	if n.data.ErrorRatio != 0.005 {
+		t.Errorf("Expected 0.005, got %f", n.data.ErrorRatio)
+	}
+
+	// 50 errors in 10 seconds is above it
+	events = updateSample(n, 10, &common.IfData{InErrors: 155, InUcastPkts: 3000})
+
+	if count, exceeded := countErrorRateEvents(events); count != 1 || !exceeded {
+		t.Errorf("Expected one exceeded event, got %d (%t)", count, exceeded)
+	}
+
+	// Still above it
+	events = updateSample(n, 10, &common.IfData{InErrors: 255, InUcastPkts: 4000})
+
+	if count, _ := countErrorRateEvents(events); count != 0 {
+		t.Errorf("Expected no events, got %d", count)
+	}
+
+	events = updateSample(n, 10, &common.IfData{InErrors: 255, InUcastPkts: 5000})
+
+	if count, exceeded := countErrorRateEvents(events); count != 1 || exceeded {
+		t.Errorf("Expected one recovery event, got %d (%t)", count, exceeded)
+	}
+
+	if history := n.data.GetErrorsInRateHistory(4); history[0] != 0.5 || history[1] != 5 || history[2] != 10 || history[3] != 0 {
+		t.Errorf("Expected [0.5 5 10 0], got %v", history)
+	}
+}
+
+func TestUpdateErrorStats_CounterWraps_ComputesIncrease(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	updateSample(n, 0, &common.IfData{OutDiscards: math.MaxUint32 - 5})
+	updateSample(n, 10, &common.IfData{OutDiscards: 15})
+
+	if n.data.DiscardRate != 2.0 {
+		t.Errorf("Expected 2.0, got %f", n.data.DiscardRate)
+	}
+}
+
+func TestCounterIncrease_DeviceRestarted_ReturnsNewValue(t *testing.T) {
+	if result := counterIncrease(5, 10, 1000, 20, math.MaxUint32); result != 20 {
+		t.Errorf("Expected 20, got %d", result)
+	}
+}
+
+func TestCounterIncrease_UptimeUnknown_ReturnsDelta(t *testing.T) {
+	if result := counterIncrease(0, 10, 1000, 1020, math.MaxUint32); result != 20 {
+		t.Errorf("Expected 20, got %d", result)
+	}
+}
+
+func TestUpdatePacketRates_BroadcastAboveThreshold_RaisesStormEvents(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:                    "eth0",
+		IdentificationMode:      config.InterfaceByName,
+		BroadcastStormThreshold: 100,
+	}, data.DefaultRateHistoryRetention, nil)
+	countStormEvents := func(events []any) (int, bool) {
+		count := 0
+		active := false
+
+		for _, event := range events {
+			if e, ok := event.(netmonevents.HostInterfaceBroadcastStormEvent); ok {
+				count++
+				active = e.Active
+			}
+		}
+
+		return count, active
+	}
+
+	updateSample(n, 0, &common.IfData{HCInUcastPkts: 1000, HCInBroadcastPkts: 1000})
+	events := updateSample(n, 10, &common.IfData{HCInUcastPkts: 2000, HCInBroadcastPkts: 11000})
+
+	if count, active := countStormEvents(events); count != 1 || !active {
+		t.Errorf("Expected one storm event, got %d (%t)", count, active)
+	}
+
+	if rate := n.data.BroadcastInRateHistory[n.data.CurrentHistoryIndex]; rate != 1000 {
+		t.Errorf("Expected 1000, got %f", rate)
+	}
+
+	if rate := n.data.UnicastInRateHistory[n.data.CurrentHistoryIndex]; rate != 100 {
+		t.Errorf("Expected 100, got %f", rate)
+	}
+
+	events = updateSample(n, 10, &common.IfData{HCInUcastPkts: 3000, HCInBroadcastPkts: 11100})
+
+	if count, active := countStormEvents(events); count != 1 || active {
+		t.Errorf("Expected one storm ended event, got %d (%t)", count, active)
+	}
+}
+
+func TestUpdateDataUsage_UsageReachesAlertPercents_RaisesEventOncePerPercent(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+		DataCap:            1000,
+		DataCapDirection:   config.DataCapIn,
+	}, data.DefaultRateHistoryRetention, nil)
+	dataCapEvents := func(events []any) []netmonevents.HostInterfaceDataCapEvent {
+		result := make([]netmonevents.HostInterfaceDataCapEvent, 0)
+
+		for _, event := range events {
+			if e, ok := event.(netmonevents.HostInterfaceDataCapEvent); ok {
+				result = append(result, e)
+			}
+		}
+
+		return result
+	}
+
+	updateSample(n, 0, &common.IfData{InOctets: 0, OutOctets: 0})
+	events := dataCapEvents(updateSample(n, 10, &common.IfData{InOctets: 800, OutOctets: 5000}))
+
+	if len(events) != 1 || events[0].Percent != 75 || events[0].Usage != 800 {
+		t.Errorf("Expected one event at 75%% with a usage of 800, got %+v", events)
+	}
+
+	events = dataCapEvents(updateSample(n, 10, &common.IfData{InOctets: 850, OutOctets: 5000}))
+
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %+v", events)
+	}
+
+	// Skipping past 90% only reports the highest percentage reached
+	events = dataCapEvents(updateSample(n, 10, &common.IfData{InOctets: 1200, OutOctets: 5000}))
+
+	if len(events) != 1 || events[0].Percent != 100 {
+		t.Errorf("Expected one event at 100%%, got %+v", events)
+	}
+
+	if !events[0].CycleEnd.Equal(data.BillingCycleEnd(events[0].CycleStart, 1)) {
+		t.Errorf("Expected the cycle to end on %v, got %v", data.BillingCycleEnd(events[0].CycleStart, 1), events[0].CycleEnd)
+	}
+}
+
+func TestInitFromSample_SameBillingCycle_AddsUsageWithoutEvents(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+		DataCap:            1000,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	updateSample(n, 0, &common.IfData{InOctets: 0, OutOctets: 0})
+	updateSample(n, 10, &common.IfData{InOctets: 100, OutOctets: 50})
+	n.initFromSample(data.NetInterfaceData{
+		BillingCycleStart: n.data.BillingCycleStart,
+		CycleBytesIn:      500,
+		CycleBytesOut:     300,
+	})
+
+	if n.data.CycleBytesIn != 600 || n.data.CycleBytesOut != 350 {
+		t.Errorf("Expected 600 and 350, got %d and %d", n.data.CycleBytesIn, n.data.CycleBytesOut)
+	}
+
+	if n.data.DataCapPercentReached != 90 {
+		t.Errorf("Expected 90, got %f", n.data.DataCapPercentReached)
+	}
+
+	events := updateSample(n, 10, &common.IfData{InOctets: 110, OutOctets: 50})
+
+	for _, event := range events {
+		if _, ok := event.(netmonevents.HostInterfaceDataCapEvent); ok {
+			t.Errorf("Expected no data cap events, got %+v", event)
+		}
+	}
+}
+
+func TestUpdateLinkProperties_ValuesChange_RaisesEvents(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	events := updateSample(n, 0, &common.IfData{Speed: 1_000_000_000, DuplexStatus: 3, Mtu: 1500})
+
+	if len(events) != 1 {
+		t.Errorf("Expected only the status change event for the first values, got %+v", events)
+	}
+
+	events = updateSample(n, 10, &common.IfData{Speed: 100_000_000, DuplexStatus: 2, Mtu: 9000})
+	changes := 0
+
+	for _, event := range events {
+		switch e := event.(type) {
+		case netmonevents.HostInterfaceSpeedChangeEvent:
+			changes++
+
+			if e.OldValue != 1_000_000_000 || e.NewValue != 100_000_000 {
+				t.Errorf("Expected 1000000000 to 100000000, got %d to %d", e.OldValue, e.NewValue)
+			}
+		case netmonevents.HostInterfaceDuplexChangeEvent:
+			changes++
+
+			if e.OldValue != "Full" || e.NewValue != "Half" {
+				t.Errorf("Expected Full to Half, got %s to %s", e.OldValue, e.NewValue)
+			}
+		case netmonevents.HostInterfaceMtuChangeEvent:
+			changes++
+
+			if e.OldValue != 1500 || e.NewValue != 9000 {
+				t.Errorf("Expected 1500 to 9000, got %d to %d", e.OldValue, e.NewValue)
+			}
+		}
+	}
+
+	if changes != 3 {
+		t.Errorf("Expected 3 change events, got %d", changes)
+	}
+}
+
+func TestUpdateLinkProperties_HighSpeedInterface_UsesIfHighSpeed(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	updateSample(n, 0, &common.IfData{Speed: math.MaxUint32, HighSpeed: 10_000})
+
+	if n.data.Speed != 10_000_000_000 {
+		t.Errorf("Expected 10000000000, got %d", n.data.Speed)
+	}
+}
+
+func TestUpdateStatus_AdminStatusChanges_RaisesEventWithBothStatuses(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, nil)
+
+	updateSample(n, 0, &common.IfData{AdminStatus: 1, OperStatus: 7})
+
+	if n.data.Status != data.InterfaceStatusLowerLayerDown || !n.data.IsFailed() {
+		t.Errorf("Expected a failed interface with status %s, got %s", data.InterfaceStatusLowerLayerDown, n.data.Status)
+	}
+
+	events := updateSample(n, 10, &common.IfData{AdminStatus: 2, OperStatus: 2})
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %+v", events)
+	}
+
+	event := events[0].(netmonevents.HostInterfaceStatusChangeEvent)
+
+	if event.OldAdminStatus != "Up" || event.NewAdminStatus != "Down" || event.NewValue != "Down" || !event.IsAdminDown() {
+		t.Errorf("Expected a change to admin down, got %+v", event)
+	}
+
+	if n.data.IsFailed() {
+		t.Errorf("Expected an admin down interface not to be failed")
+	}
+}
+
+func TestUpdateLastChangeTime_ConvertsUptimeToWallClock(t *testing.T) {
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, nil)
+	scanTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	n.updateLastChangeTime(&common.HostData{LastUpdateTime: scanTime, UptimeSeconds: 86400},
+		&common.IfData{LastChangeSeconds: 3600})
+	expected := scanTime.Add(-23 * time.Hour)
+
+	if !n.data.LastChangeTime.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, n.data.LastChangeTime)
+	}
+
+	// Scan jitter doesn't change the time
+	n.updateLastChangeTime(&common.HostData{LastUpdateTime: scanTime.Add(62 * time.Second), UptimeSeconds: 86460},
+		&common.IfData{LastChangeSeconds: 3600})
+
+	if !n.data.LastChangeTime.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, n.data.LastChangeTime)
+	}
+}
+
+func TestStubData_ReadDuringUpdate_ReturnsCopy(t *testing.T) {
+	container := fakecontainer.New()
+	defer container.Close()
+	n := CreateNetInterface("Host_1", "Interface_1", tracking.Config{}, config.NetInterface{
+		Name:               "eth0",
+		IdentificationMode: config.InterfaceByName,
+	}, data.DefaultRateHistoryRetention, container)
+	var stub entities.NetworkInterface
+	_ = container.RunOnPluginGoRoutine(func() { stub = n.GetStub(container) })
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			_ = container.RunOnPluginGoRoutine(func() {
+				updateSample(n, 60, &common.IfData{HCInOctets: uint64(i) * 60_000, HCOutOctets: uint64(i) * 6_000})
+			})
+		}
+	}()
+
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+
+		// Reads the history and percentiles, which the updates modify, off the plugin's goroutine
+		sample := stub.Data().Data.(data.NetInterfaceData)
+		sample.BytesInHistory.Series(time.Hour, 60, time.Now())
+		_ = slices.Clone(sample.Percentiles.In.Largest)
+	}
+
+	if sample := stub.Data().Data.(data.NetInterfaceData); sample.BytesIn != 99*60_000 {
+		t.Errorf("Expected %d bytes in, got %d", 99*60_000, sample.BytesIn)
+	}
+}
@@ -0,0 +1,232 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+)
+
+const queueSize = 100
+const deliveryLogSize = 200
+const maxRetryBackoff = 10 * time.Minute
+
+// Dispatcher sends events to the configured notification channels.  Each channel is served by its own goroutine,
+// so a slow or unavailable channel doesn't hold up the plugin, or the other channels.
+type Dispatcher struct {
+	workers     []*worker
+	hostGroups  map[string][]string
+	logMutex    sync.Mutex
+	deliveryLog []data.NotificationDelivery
+}
+
+type worker struct {
+	name               string
+	sink               sink
+	eventTypes         []reflect.Type
+	hosts              []string
+	hostGroups         []string
+	maxAttempts        int
+	retryBackoff       time.Duration
+	rateLimitPerMinute int
+	sendTimes          []time.Time
+	queue              chan *Notification
+	dispatcher         *Dispatcher
+}
+
+// NewDispatcher creates a Dispatcher for the configured channels.  hostGroups maps host names to the groups they
+// belong to.  Invalid channels are skipped and reported via the returned error, but the returned Dispatcher is
+// always usable.
+func NewDispatcher(channels []config.NotificationChannel, hostGroups map[string][]string) (*Dispatcher, error) {
+	var errs []error
+	dispatcher := &Dispatcher{
+		workers:     make([]*worker, 0, len(channels)),
+		hostGroups:  hostGroups,
+		deliveryLog: make([]data.NotificationDelivery, 0),
+	}
+
+	for i := range channels {
+		channel := &channels[i]
+		channelSink, err := newSink(channel)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notification channel %s: %w", channel.Name, err))
+			continue
+		}
+
+		dispatcher.workers = append(dispatcher.workers, &worker{
+			name:               channel.Name,
+			sink:               channelSink,
+			eventTypes:         slices.Clone(channel.EventTypes),
+			hosts:              slices.Clone(channel.Hosts),
+			hostGroups:         slices.Clone(channel.HostGroups),
+			maxAttempts:        max(1, channel.MaxAttempts),
+			retryBackoff:       time.Duration(channel.RetryBackoffSeconds) * time.Second,
+			rateLimitPerMinute: channel.RateLimitPerMinute,
+			queue:              make(chan *Notification, queueSize),
+			dispatcher:         dispatcher,
+		})
+	}
+
+	return dispatcher, errors.Join(errs...)
+}
+
+// Start starts a goroutine per channel, which runs until the context is done.  The goroutines are added to the
+// WaitGroup.
+func (d *Dispatcher) Start(ctx context.Context, waitGroup *sync.WaitGroup) {
+	for _, w := range d.workers {
+		waitGroup.Go(func() { w.run(ctx) })
+	}
+}
+
+// Notify queues the event for delivery through every channel subscribed to it.  It never blocks.  If a channel's
+// queue is full, the notification is dropped for that channel.
+func (d *Dispatcher) Notify(event any) {
+	if len(d.workers) == 0 {
+		return
+	}
+
+	notification, ok := newNotification(event, time.Now())
+
+	if !ok {
+		return
+	}
+
+	eventType := reflect.TypeOf(event)
+
+	for _, w := range d.workers {
+		if !w.subscribedTo(eventType, notification.HostName, d.hostGroups[notification.HostName]) {
+			continue
+		}
+
+		select {
+		case w.queue <- notification:
+		default:
+			d.record(w.name, notification, 0, data.NotificationDropped, errors.New("queue full"))
+		}
+	}
+}
+
+// DeliveryLog returns the most recent deliveries, newest first.  Safe to call from any goroutine.
+func (d *Dispatcher) DeliveryLog() []data.NotificationDelivery {
+	d.logMutex.Lock()
+	defer d.logMutex.Unlock()
+
+	result := slices.Clone(d.deliveryLog)
+	slices.Reverse(result)
+
+	return result
+}
+
+func (d *Dispatcher) record(channelName string, notification *Notification, attempts int, status string, err error) {
+	delivery := data.NotificationDelivery{
+		Time:        time.Now(),
+		ChannelName: channelName,
+		EventType:   notification.EventType,
+		HostName:    notification.HostName,
+		Title:       notification.Title,
+		Attempts:    attempts,
+		Status:      status,
+	}
+
+	if err != nil {
+		delivery.Error = err.Error()
+		fmt.Printf("notification channel [%s]: %s \"%s\": %v\n", channelName, status, notification.Title, err)
+	}
+
+	d.logMutex.Lock()
+	defer d.logMutex.Unlock()
+
+	if len(d.deliveryLog) == deliveryLogSize {
+		d.deliveryLog = slices.Delete(d.deliveryLog, 0, 1)
+	}
+
+	d.deliveryLog = append(d.deliveryLog, delivery)
+}
+
+func (w *worker) subscribedTo(eventType reflect.Type, hostName string, hostGroups []string) bool {
+	if len(w.eventTypes) != 0 && !slices.Contains(w.eventTypes, eventType) {
+		return false
+	}
+
+	if len(w.hosts) == 0 && len(w.hostGroups) == 0 {
+		return true
+	}
+
+	if slices.Contains(w.hosts, hostName) {
+		return true
+	}
+
+	return slices.ContainsFunc(w.hostGroups, func(group string) bool { return slices.Contains(hostGroups, group) })
+}
+
+func (w *worker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-w.queue:
+			w.deliver(ctx, notification)
+		}
+	}
+}
+
+// deliver sends the notification, retrying with exponential backoff until it succeeds, the attempts are
+// exhausted, or the context is done.
+func (w *worker) deliver(ctx context.Context, notification *Notification) {
+	if !w.allow(time.Now()) {
+		w.dispatcher.record(w.name, notification, 0, data.NotificationRateLimited, nil)
+		return
+	}
+
+	backoff := w.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := w.sink.send(ctx, notification)
+
+		if err == nil {
+			w.dispatcher.record(w.name, notification, attempt, data.NotificationDelivered, nil)
+			return
+		}
+
+		if attempt >= w.maxAttempts {
+			w.dispatcher.record(w.name, notification, attempt, data.NotificationFailed, err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			w.dispatcher.record(w.name, notification, attempt, data.NotificationFailed, err)
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// allow applies the rate limit, using a sliding one-minute window.
+func (w *worker) allow(now time.Time) bool {
+	if w.rateLimitPerMinute <= 0 {
+		return true
+	}
+
+	windowStart := now.Add(-time.Minute)
+	w.sendTimes = slices.DeleteFunc(w.sendTimes, func(sendTime time.Time) bool {
+		return !sendTime.After(windowStart)
+	})
+
+	if len(w.sendTimes) >= w.rateLimitPerMinute {
+		return false
+	}
+
+	w.sendTimes = append(w.sendTimes, now)
+
+	return true
+}
@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+)
+
+type fakeSink struct {
+	mutex    sync.Mutex
+	failures int
+	sent     []*Notification
+}
+
+func (s *fakeSink) send(_ context.Context, notification *Notification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+
+	s.sent = append(s.sent, notification)
+
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, channel config.NotificationChannel, sink sink) *Dispatcher {
+	dispatcher, err := NewDispatcher(
+		[]config.NotificationChannel{channel},
+		map[string][]string{"router": {"network"}, "server": {"servers"}})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dispatcher.workers[0].sink = sink
+	dispatcher.workers[0].retryBackoff = time.Millisecond
+
+	return dispatcher
+}
+
+func webhookChannel() *config.NotificationChannel {
+	pluginConfig := config.PluginConfig{}
+
+	return pluginConfig.AddWebhookChannel("hook", "http://localhost/hook")
+}
+
+func TestNewDispatcher_InvalidChannel_SkippedAndReported(t *testing.T) {
+	dispatcher, err := NewDispatcher([]config.NotificationChannel{
+		{Name: "no url", Type: config.NotificationChannelWebhook},
+		*webhookChannel(),
+	}, nil)
+
+	if err == nil {
+		t.Errorf("Expected an error for the invalid channel")
+	}
+
+	if len(dispatcher.workers) != 1 {
+		t.Errorf("Expected 1 worker, got %d", len(dispatcher.workers))
+	}
+}
+
+func TestNotify_Filters_OnlySubscribedEventsQueued(t *testing.T) {
+	channel := webhookChannel().ForEvents(netmonevents.HostReachabilityChangeEvent{}).ForHostGroups("network")
+	dispatcher := newTestDispatcher(t, *channel, &fakeSink{})
+
+	dispatcher.Notify(reachabilityEvent("router"))
+	dispatcher.Notify(reachabilityEvent("server"))
+	dispatcher.Notify(netmonevents.HostFlappingChangeEvent{HostEvent: reachabilityEvent("router").HostEvent})
+
+	if queued := len(dispatcher.workers[0].queue); queued != 1 {
+		t.Errorf("Expected 1 queued notification, got %d", queued)
+	}
+}
+
+func TestDeliver_TransientFailures_Retried(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	dispatcher := newTestDispatcher(t, *webhookChannel(), sink)
+
+	dispatcher.workers[0].deliver(context.Background(), testNotification(t))
+
+	if len(sink.sent) != 1 {
+		t.Errorf("Expected 1 sent notification, got %d", len(sink.sent))
+	}
+
+	log := dispatcher.DeliveryLog()
+
+	if len(log) != 1 || log[0].Status != data.NotificationDelivered || log[0].Attempts != 3 {
+		t.Errorf("Expected delivered after 3 attempts, got %v", log)
+	}
+}
+
+func TestDeliver_AttemptsExhausted_RecordedAsFailed(t *testing.T) {
+	channel := webhookChannel()
+	channel.MaxAttempts = 2
+	dispatcher := newTestDispatcher(t, *channel, &fakeSink{failures: 5})
+
+	dispatcher.workers[0].deliver(context.Background(), testNotification(t))
+
+	log := dispatcher.DeliveryLog()
+
+	if len(log) != 1 || log[0].Status != data.NotificationFailed || log[0].Error != "unavailable" {
+		t.Errorf("Expected a failed delivery, got %v", log)
+	}
+}
+
+func TestDeliver_RateLimitExceeded_Dropped(t *testing.T) {
+	channel := webhookChannel()
+	channel.RateLimitPerMinute = 2
+	sink := &fakeSink{}
+	dispatcher := newTestDispatcher(t, *channel, sink)
+
+	for i := 0; i < 3; i++ {
+		dispatcher.workers[0].deliver(context.Background(), testNotification(t))
+	}
+
+	if len(sink.sent) != 2 {
+		t.Errorf("Expected 2 sent notifications, got %d", len(sink.sent))
+	}
+
+	if log := dispatcher.DeliveryLog(); log[0].Status != data.NotificationRateLimited {
+		t.Errorf("Expected the newest delivery to be rate limited, got %v", log[0])
+	}
+}
+
+func TestStart_NotificationsDeliveredUntilStopped(t *testing.T) {
+	sink := &fakeSink{}
+	dispatcher := newTestDispatcher(t, *webhookChannel(), sink)
+	ctx, cancel := context.WithCancel(context.Background())
+	waitGroup := sync.WaitGroup{}
+	dispatcher.Start(ctx, &waitGroup)
+
+	dispatcher.Notify(reachabilityEvent("router"))
+
+	for i := 0; i < 100 && len(dispatcher.DeliveryLog()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	waitGroup.Wait()
+
+	if log := dispatcher.DeliveryLog(); len(log) != 1 || log[0].Status != data.NotificationDelivered {
+		t.Errorf("Expected 1 delivered notification, got %v", log)
+	}
+}
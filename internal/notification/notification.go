@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/eventtext"
+)
+
+type hostEventSource interface {
+	GetHostEvent() netmonevents.HostEvent
+}
+
+// Notification is the human-readable form of an event, as sent through the notification channels.  It's also
+// the value webhook body templates are executed with.
+type Notification struct {
+	EventType string
+	HostName  string
+	Title     string
+	Message   string
+	Time      time.Time
+	Event     any
+}
+
+// newNotification describes an event.  Returns false for events that are not host events.
+func newNotification(event any, now time.Time) (*Notification, bool) {
+	source, ok := event.(hostEventSource)
+
+	if !ok {
+		return nil, false
+	}
+
+	hostName := source.GetHostEvent().Name
+	summary, message := eventtext.Describe(event)
+
+	return &Notification{
+		EventType: reflect.TypeOf(event).Name(),
+		HostName:  hostName,
+		Title:     fmt.Sprintf("netmon: %s %s", hostName, summary),
+		Message:   message,
+		Time:      now,
+		Event:     event,
+	}, true
+}
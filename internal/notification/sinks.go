@@ -0,0 +1,308 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+)
+
+// sink sends a notification through a single channel.  Errors are retried by the caller.
+type sink interface {
+	send(ctx context.Context, notification *Notification) error
+}
+
+func newSink(channel *config.NotificationChannel) (sink, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch channel.Type {
+	case config.NotificationChannelWebhook:
+		return newWebhookSink(channel, client)
+	case config.NotificationChannelEmail:
+		if channel.SmtpAddress == "" || channel.From == "" || len(channel.To) == 0 {
+			return nil, fmt.Errorf("an SMTP address, sender and at least one recipient are required")
+		}
+
+		return &emailSink{
+			address:  channel.SmtpAddress,
+			username: channel.SmtpUsername,
+			password: channel.SmtpPassword,
+			from:     channel.From,
+			to:       channel.To,
+			timeout:  30 * time.Second,
+		}, nil
+	case config.NotificationChannelNtfy:
+		if channel.Url == "" {
+			return nil, fmt.Errorf("a topic URL is required")
+		}
+
+		return &ntfySink{url: channel.Url, token: channel.Token, client: client}, nil
+	case config.NotificationChannelGotify:
+		if channel.Url == "" || channel.Token == "" {
+			return nil, fmt.Errorf("a server URL and application token are required")
+		}
+
+		return &gotifySink{url: strings.TrimSuffix(channel.Url, "/") + "/message", token: channel.Token, client: client}, nil
+	default:
+		return nil, fmt.Errorf("invalid channel type %d", channel.Type)
+	}
+}
+
+type webhookSink struct {
+	url          string
+	headers      map[string]string
+	bodyTemplate *template.Template
+	client       *http.Client
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		result, err := json.Marshal(value)
+		return string(result), err
+	},
+}
+
+func newWebhookSink(channel *config.NotificationChannel, client *http.Client) (*webhookSink, error) {
+	if channel.Url == "" {
+		return nil, fmt.Errorf("a URL is required")
+	}
+
+	result := &webhookSink{url: channel.Url, headers: channel.Headers, client: client}
+
+	if channel.BodyTemplate != "" {
+		bodyTemplate, err := template.New(channel.Name).Funcs(webhookTemplateFuncs).Parse(channel.BodyTemplate)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+
+		result.bodyTemplate = bodyTemplate
+	}
+
+	return result, nil
+}
+
+func (s *webhookSink) send(ctx context.Context, notification *Notification) error {
+	var body bytes.Buffer
+
+	if s.bodyTemplate == nil {
+		if err := json.NewEncoder(&body).Encode(notification); err != nil {
+			return fmt.Errorf("unable to encode notification: %w", err)
+		}
+	} else if err := s.bodyTemplate.Execute(&body, notification); err != nil {
+		return fmt.Errorf("unable to execute body template: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, &body)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	for name, value := range s.headers {
+		request.Header.Set(name, value)
+	}
+
+	return doRequest(s.client, request)
+}
+
+// ntfySink publishes to an ntfy topic, with the message as the body and the title in a header.
+type ntfySink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (s *ntfySink) send(ctx context.Context, notification *Notification) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(notification.Message))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Title", notification.Title)
+
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	return doRequest(s.client, request)
+}
+
+// gotifySink posts to a Gotify server's message endpoint.
+type gotifySink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+func (s *gotifySink) send(ctx context.Context, notification *Notification) error {
+	body, err := json.Marshal(gotifyMessage{Title: notification.Title, Message: notification.Message, Priority: 5})
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Gotify-Key", s.token)
+
+	return doRequest(s.client, request)
+}
+
+func doRequest(client *http.Client, request *http.Request) error {
+	response, err := client.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+
+	return nil
+}
+
+// emailSink sends notifications through an SMTP server.  The whole session, from connecting to sending the
+// message, is bounded by the timeout, and ends early if the context is cancelled, so a server that stops responding
+// can't hold up the dispatcher.
+type emailSink struct {
+	address  string
+	username string
+	password string
+	from     string
+	to       []string
+	timeout  time.Duration
+}
+
+func (s *emailSink) send(ctx context.Context, notification *Notification) error {
+	host, _, err := net.SplitHostPort(s.address)
+
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address: %w", err)
+	}
+
+	var auth smtp.Auth
+
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + s.from + "\r\n")
+	message.WriteString("To: " + strings.Join(s.to, ", ") + "\r\n")
+	message.WriteString("Subject: " + notification.Title + "\r\n")
+	message.WriteString("Date: " + notification.Time.Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(notification.Message + "\r\n")
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	// Closing the connection interrupts the session if the context is cancelled before the deadline
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	defer func() { _ = client.Close() }()
+
+	return s.sendMessage(client, host, auth, message.String())
+}
+
+// sendMessage runs the SMTP session the way smtp.SendMail does, using TLS if the server supports it.
+func (s *emailSink) sendMessage(client *smtp.Client, host string, auth smtp.Auth, message string) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("the SMTP server doesn't support authentication")
+		}
+
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return err
+	}
+
+	for _, recipient := range s.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write([]byte(message)); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
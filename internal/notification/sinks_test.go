@@ -0,0 +1,267 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	spievents "github.com/avanha/pmaas-spi/events"
+)
+
+func reachabilityEvent(hostName string) netmonevents.HostReachabilityChangeEvent {
+	return netmonevents.HostReachabilityChangeEvent{
+		HostEvent: netmonevents.HostEvent{EntityEvent: spievents.EntityEvent{Name: hostName}},
+		OldValue:  data.ReachabilityReachable,
+		NewValue:  data.ReachabilityUnreachable,
+	}
+}
+
+func testNotification(t *testing.T) *Notification {
+	notification, ok := newNotification(reachabilityEvent("router"), time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC))
+
+	if !ok {
+		t.Fatalf("Expected a notification")
+	}
+
+	return notification
+}
+
+func TestNewNotification_ReachabilityChange_Described(t *testing.T) {
+	notification := testNotification(t)
+
+	if notification.Title != "netmon: router is Down" {
+		t.Errorf("Expected \"netmon: router is Down\", got \"%s\"", notification.Title)
+	}
+
+	if notification.EventType != "HostReachabilityChangeEvent" {
+		t.Errorf("Expected HostReachabilityChangeEvent, got %s", notification.EventType)
+	}
+
+	if _, ok := newNotification("not a host event", time.Now()); ok {
+		t.Errorf("Expected no notification for a non-host event")
+	}
+}
+
+func TestWebhookSink_BodyTemplate_SendsRenderedBody(t *testing.T) {
+	var body string
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		bytes, _ := io.ReadAll(request.Body)
+		body = string(bytes)
+		header = request.Header.Get("X-Api-Key")
+	}))
+	defer server.Close()
+
+	channel := (&config.NotificationChannel{Name: "hook", Type: config.NotificationChannelWebhook, Url: server.URL}).
+		WithHeader("X-Api-Key", "secret").
+		WithBodyTemplate(`{"text": {{json .Title}}}`)
+	webhook, err := newSink(channel)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := webhook.send(context.Background(), testNotification(t)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if body != `{"text": "netmon: router is Down"}` {
+		t.Errorf("Unexpected body %s", body)
+	}
+
+	if header != "secret" {
+		t.Errorf("Expected header secret, got \"%s\"", header)
+	}
+}
+
+func TestWebhookSink_ErrorStatus_ReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook, _ := newSink(&config.NotificationChannel{Type: config.NotificationChannelWebhook, Url: server.URL})
+
+	if err := webhook.send(context.Background(), testNotification(t)); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestGotifySink_SendsMessage(t *testing.T) {
+	var message gotifyMessage
+	var path, token string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		path = request.URL.Path
+		token = request.Header.Get("X-Gotify-Key")
+		_ = json.NewDecoder(request.Body).Decode(&message)
+	}))
+	defer server.Close()
+
+	gotify, _ := newSink(&config.NotificationChannel{
+		Type: config.NotificationChannelGotify, Url: server.URL + "/", Token: "app-token"})
+
+	if err := gotify.send(context.Background(), testNotification(t)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "/message" || token != "app-token" || message.Title != "netmon: router is Down" {
+		t.Errorf("Unexpected request to %s with token %s and message %v", path, token, message)
+	}
+}
+
+func TestNtfySink_SendsTitleHeader(t *testing.T) {
+	var title, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		title = request.Header.Get("Title")
+		authorization = request.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	ntfy, _ := newSink(&config.NotificationChannel{Type: config.NotificationChannelNtfy, Url: server.URL, Token: "tk"})
+
+	if err := ntfy.send(context.Background(), testNotification(t)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if title != "netmon: router is Down" || authorization != "Bearer tk" {
+		t.Errorf("Unexpected title \"%s\" and authorization \"%s\"", title, authorization)
+	}
+}
+
+// runFakeSmtpServer accepts a single SMTP session and sends the received message to the returned channel.
+func runFakeSmtpServer(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+
+	messages := make(chan string, 1)
+
+	go func() {
+		defer func() { _ = listener.Close() }()
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+		reader := textproto.NewReader(bufio.NewReader(conn))
+		writer := textproto.NewWriter(bufio.NewWriter(conn))
+		_ = writer.PrintfLine("220 localhost ready")
+
+		for {
+			line, err := reader.ReadLine()
+
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+			switch command {
+			case "EHLO", "HELO":
+				_ = writer.PrintfLine("250 localhost")
+			case "DATA":
+				_ = writer.PrintfLine("354 go ahead")
+				lines, _ := reader.ReadDotLines()
+				messages <- strings.Join(lines, "\n")
+				_ = writer.PrintfLine("250 ok")
+			case "QUIT":
+				_ = writer.PrintfLine("221 bye")
+				return
+			default:
+				_ = writer.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), messages
+}
+
+func TestEmailSink_SendsMessage(t *testing.T) {
+	address, messages := runFakeSmtpServer(t)
+	pluginConfig := config.PluginConfig{}
+	channel := pluginConfig.AddEmailChannel("email", address, "netmon@example.com", "ops@example.com")
+	email, err := newSink(channel)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := email.send(context.Background(), testNotification(t)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, "Subject: netmon: router is Down") {
+			t.Errorf("Expected subject in message, got %s", message)
+		}
+
+		if !strings.Contains(message, "To: ops@example.com") {
+			t.Errorf("Expected recipient in message, got %s", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected a message")
+	}
+}
+
+func TestEmailSink_ServerNotResponding_TimesOut(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+
+	defer func() { _ = listener.Close() }()
+
+	// Accept the connection, but never send the greeting
+	go func() {
+		conn, err := listener.Accept()
+
+		if err == nil {
+			defer func() { _ = conn.Close() }()
+			_, _ = io.Copy(io.Discard, conn)
+		}
+	}()
+
+	pluginConfig := config.PluginConfig{}
+	channel := pluginConfig.AddEmailChannel("email", listener.Addr().String(), "netmon@example.com", "ops@example.com")
+	email, _ := newSink(channel)
+	email.(*emailSink).timeout = 100 * time.Millisecond
+	start := time.Now()
+
+	if err := email.send(context.Background(), testNotification(t)); err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the send to time out, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	email.(*emailSink).timeout = time.Minute
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start = time.Now()
+
+	if err := email.send(ctx, testNotification(t)); err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the send to end when the context was cancelled, took %v", elapsed)
+	}
+}
@@ -0,0 +1,269 @@
+package snmpsim
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Record is a single object served by the agent.  Value holds the Go type gosnmp marshals for Type: int for
+// Integer, uint32 for Counter32, Gauge32 and TimeTicks, uint64 for Counter64, []byte for OctetString, and string
+// for ObjectIdentifier and IPAddress.
+type Record struct {
+	Oid   string
+	Type  gosnmp.Asn1BER
+	Value any
+
+	subIds []uint32
+}
+
+// LoadFile reads records from a fixture file.  Files ending in .snmprec are read in the snmprec format used by
+// snmpsim; any other file is read as the output of net-snmp's snmpwalk -On.
+func LoadFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	if strings.HasSuffix(path, ".snmprec") {
+		return ParseSnmprec(file)
+	}
+
+	return ParseSnmpwalk(file)
+}
+
+// ParseSnmprec parses records in the snmprec format, one "oid|tag|value" per line, where tag is the numeric BER
+// tag of the type.  A tag with an "x" suffix, such as 4x, has a hex-encoded value.  Empty lines and lines starting
+// with # are ignored.
+func ParseSnmprec(reader io.Reader) ([]Record, error) {
+	result := make([]Record, 0)
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 3)
+
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d: expected oid|tag|value, got %q", lineNumber, line)
+		}
+
+		tag, hexEncoded := strings.CutSuffix(parts[1], "x")
+		tagValue, err := strconv.Atoi(tag)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid tag %q: %w", lineNumber, parts[1], err)
+		}
+
+		value := parts[2]
+
+		if hexEncoded {
+			decoded, err := hex.DecodeString(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid hex value %q: %w", lineNumber, value, err)
+			}
+
+			value = string(decoded)
+		}
+
+		record, err := newRecord(parts[0], gosnmp.Asn1BER(tagValue), value)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		result = append(result, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+var snmpwalkLinePattern = regexp.MustCompile(`^(\S+) = (?:([A-Za-z0-9-]+): ?)?(.*)$`)
+var snmpwalkEnumPattern = regexp.MustCompile(`^\S+\((-?\d+)\)$`)
+var snmpwalkTimeTicksPattern = regexp.MustCompile(`^\((\d+)\)`)
+
+var snmpwalkTypes = map[string]gosnmp.Asn1BER{
+	"INTEGER":    gosnmp.Integer,
+	"STRING":     gosnmp.OctetString,
+	"Hex-STRING": gosnmp.OctetString,
+	"OID":        gosnmp.ObjectIdentifier,
+	"IpAddress":  gosnmp.IPAddress,
+	"Counter32":  gosnmp.Counter32,
+	"Gauge32":    gosnmp.Gauge32,
+	"Timeticks":  gosnmp.TimeTicks,
+	"Counter64":  gosnmp.Counter64,
+}
+
+// ParseSnmpwalk parses the output of net-snmp's snmpwalk with numeric OIDs (-On), such as
+// ".1.3.6.1.2.1.2.2.1.2.1 = STRING: lo".  Values without a type, such as "", are read as empty strings.
+func ParseSnmpwalk(reader io.Reader) ([]Record, error) {
+	result := make([]Record, 0)
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := snmpwalkLinePattern.FindStringSubmatch(line)
+
+		if match == nil {
+			return nil, fmt.Errorf("line %d: unrecognized line %q", lineNumber, line)
+		}
+
+		typeName, value := match[2], match[3]
+
+		if typeName == "" {
+			typeName = "STRING"
+		}
+
+		asnType, ok := snmpwalkTypes[typeName]
+
+		if !ok {
+			return nil, fmt.Errorf("line %d: unsupported type %q", lineNumber, typeName)
+		}
+
+		switch typeName {
+		case "STRING":
+			value = strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+		case "Hex-STRING":
+			decoded, err := hex.DecodeString(strings.ReplaceAll(value, " ", ""))
+
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid hex value %q: %w", lineNumber, value, err)
+			}
+
+			value = string(decoded)
+		case "INTEGER":
+			// Enumerations are written as name(value)
+			if enumMatch := snmpwalkEnumPattern.FindStringSubmatch(value); enumMatch != nil {
+				value = enumMatch[1]
+			}
+		case "Timeticks":
+			if ticksMatch := snmpwalkTimeTicksPattern.FindStringSubmatch(value); ticksMatch != nil {
+				value = ticksMatch[1]
+			}
+		}
+
+		record, err := newRecord(match[1], asnType, value)
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		result = append(result, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// newRecord creates a record from the textual representation of its value.
+func newRecord(oid string, asnType gosnmp.Asn1BER, value string) (Record, error) {
+	subIds, err := parseOid(oid)
+
+	if err != nil {
+		return Record{}, err
+	}
+
+	record := Record{Oid: formatOid(subIds), Type: asnType, subIds: subIds}
+
+	switch asnType {
+	case gosnmp.Integer:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid integer %q for %s: %w", value, oid, err)
+		}
+
+		record.Value = int(parsed)
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid unsigned integer %q for %s: %w", value, oid, err)
+		}
+
+		record.Value = uint32(parsed)
+	case gosnmp.Counter64:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid counter %q for %s: %w", value, oid, err)
+		}
+
+		record.Value = parsed
+	case gosnmp.OctetString, gosnmp.Opaque:
+		record.Value = []byte(value)
+	case gosnmp.ObjectIdentifier, gosnmp.IPAddress:
+		record.Value = value
+	case gosnmp.Null:
+		record.Value = nil
+	default:
+		return Record{}, fmt.Errorf("unsupported type %v for %s", asnType, oid)
+	}
+
+	return record, nil
+}
+
+func parseOid(oid string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	result := make([]uint32, len(parts))
+
+	for i, part := range parts {
+		subId, err := strconv.ParseUint(part, 10, 32)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid oid %q: %w", oid, err)
+		}
+
+		result[i] = uint32(subId)
+	}
+
+	return result, nil
+}
+
+func formatOid(subIds []uint32) string {
+	var builder strings.Builder
+
+	for _, subId := range subIds {
+		builder.WriteByte('.')
+		builder.WriteString(strconv.FormatUint(uint64(subId), 10))
+	}
+
+	return builder.String()
+}
+
+// sortRecords sorts the records in lexicographic OID order, the order of a walk.
+func sortRecords(records []Record) {
+	slices.SortFunc(records, func(a, b Record) int { return slices.Compare(a.subIds, b.subIds) })
+}
@@ -0,0 +1,468 @@
+// Package snmpsim is an in-process SNMP agent for tests.  It answers v2c and v3 Get, GetNext and GetBulk requests
+// on a loopback UDP port, serving records loaded from fixture files, and can simulate misbehaving devices.
+package snmpsim
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// DefaultEngineID is the authoritative engine ID of agents configured without one.
+const DefaultEngineID = "\x80\x00\x1f\x88\x04snmpsim"
+
+// V3User is the USM user accepted by an agent for SNMPv3 requests.
+type V3User struct {
+	UserName       string
+	AuthProtocol   gosnmp.SnmpV3AuthProtocol
+	AuthPassphrase string
+	PrivProtocol   gosnmp.SnmpV3PrivProtocol
+	PrivPassphrase string
+}
+
+// Faults makes the agent misbehave, to simulate devices with limited or broken SNMP support.
+type Faults struct {
+	// GetBulkUnsupported drops GetBulk requests, the way SNMPv1-only agents do, so they time out.
+	GetBulkUnsupported bool
+
+	// DropPrefixes drops requests whose first variable is within any of the given subtrees, so they time out.
+	// Requests for the subtree's root OID itself, as used by walks, are dropped too.
+	DropPrefixes []string
+
+	// TruncateAfter limits the number of objects served within a subtree, so walks of it end early, as with
+	// tables an agent only partially populates.  The key is the subtree's root OID.
+	TruncateAfter map[string]int
+
+	// BadTypes serves the objects within the given subtrees as OCTET STRINGs holding their textual value,
+	// instead of their actual type.
+	BadTypes []string
+
+	// ResponseDelay delays every response.
+	ResponseDelay time.Duration
+}
+
+// Config configures an agent.
+type Config struct {
+	// Community is the community string accepted for v2c requests.  Defaults to "public".
+	Community string
+
+	// V3User enables SNMPv3 with the given user.  SNMPv3 requests are ignored if nil.
+	V3User *V3User
+
+	// EngineID is the agent's authoritative engine ID.  Defaults to DefaultEngineID.
+	EngineID string
+
+	Records []Record
+	Faults  Faults
+}
+
+// Agent is a running simulated SNMP agent.  It is safe for concurrent use.
+type Agent struct {
+	conn      *net.UDPConn
+	community string
+	v3Params  *gosnmp.GoSNMP
+	startTime time.Time
+	records   []Record
+	done      chan struct{}
+
+	mutex         sync.Mutex
+	faults        Faults
+	requestCounts map[gosnmp.PDUType]int
+}
+
+// Start starts an agent listening on a random loopback port.  The agent must be closed when no longer needed.
+func Start(config Config) (*Agent, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen: %w", err)
+	}
+
+	agent := &Agent{
+		conn:          conn,
+		community:     config.Community,
+		startTime:     time.Now(),
+		records:       slices.Clone(config.Records),
+		done:          make(chan struct{}),
+		faults:        config.Faults,
+		requestCounts: make(map[gosnmp.PDUType]int),
+	}
+
+	if agent.community == "" {
+		agent.community = "public"
+	}
+
+	for i := range agent.records {
+		if agent.records[i].subIds == nil {
+			if agent.records[i].subIds, err = parseOid(agent.records[i].Oid); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+		}
+	}
+
+	sortRecords(agent.records)
+
+	if config.V3User != nil {
+		agent.v3Params = newV3Params(config.V3User, config.EngineID)
+	}
+
+	go agent.serve()
+
+	return agent, nil
+}
+
+func newV3Params(user *V3User, engineID string) *gosnmp.GoSNMP {
+	if engineID == "" {
+		engineID = DefaultEngineID
+	}
+
+	msgFlags := gosnmp.NoAuthNoPriv
+
+	if user.AuthProtocol > gosnmp.NoAuth {
+		msgFlags = gosnmp.AuthNoPriv
+
+		if user.PrivProtocol > gosnmp.NoPriv {
+			msgFlags = gosnmp.AuthPriv
+		}
+	}
+
+	return &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      msgFlags,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    engineID,
+			AuthoritativeEngineBoots: 1,
+			UserName:                 user.UserName,
+			AuthenticationProtocol:   user.AuthProtocol,
+			AuthenticationPassphrase: user.AuthPassphrase,
+			PrivacyProtocol:          user.PrivProtocol,
+			PrivacyPassphrase:        user.PrivPassphrase,
+		},
+	}
+}
+
+// Address returns the agent's IP address.
+func (a *Agent) Address() string {
+	return a.conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+// Port returns the agent's UDP port.
+func (a *Agent) Port() uint16 {
+	return uint16(a.conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+// SetFaults replaces the agent's faults, for example to make a device fail between two scans.
+func (a *Agent) SetFaults(faults Faults) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.faults = faults
+}
+
+// RequestCount returns the number of requests of the given type received so far, including dropped ones.
+func (a *Agent) RequestCount(pduType gosnmp.PDUType) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.requestCounts[pduType]
+}
+
+// Close stops the agent and waits for it to finish.
+func (a *Agent) Close() error {
+	err := a.conn.Close()
+	<-a.done
+
+	return err
+}
+
+func (a *Agent) serve() {
+	defer close(a.done)
+
+	buffer := make([]byte, 65535)
+
+	for {
+		length, remote, err := a.conn.ReadFromUDP(buffer)
+
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			continue
+		}
+
+		response := a.handle(slices.Clone(buffer[:length]))
+
+		if response != nil {
+			_, _ = a.conn.WriteToUDP(response, remote)
+		}
+	}
+}
+
+// handle returns the encoded response to a request, or nil if the request is to be dropped.
+func (a *Agent) handle(request []byte) []byte {
+	packet, err := a.decode(request)
+
+	if err != nil || packet == nil {
+		return nil
+	}
+
+	a.mutex.Lock()
+	a.requestCounts[packet.PDUType]++
+	faults := a.faults
+	a.mutex.Unlock()
+
+	if packet.Version == gosnmp.Version3 && isDiscovery(packet) {
+		return a.encode(a.engineIDReport(packet))
+	}
+
+	if faults.drops(packet) {
+		return nil
+	}
+
+	if faults.ResponseDelay > 0 {
+		time.Sleep(faults.ResponseDelay)
+	}
+
+	packet.Variables = a.respond(packet, &faults)
+	packet.PDUType = gosnmp.GetResponse
+	packet.Error = gosnmp.NoError
+	packet.ErrorIndex = 0
+	packet.NonRepeaters = 0
+	packet.MaxRepetitions = 0
+	packet.MsgFlags &^= gosnmp.Reportable
+
+	return a.encode(packet)
+}
+
+func (a *Agent) decode(request []byte) (*gosnmp.SnmpPacket, error) {
+	version, err := messageVersion(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if version == gosnmp.Version3 {
+		if a.v3Params == nil {
+			return nil, errors.New("SNMPv3 is not enabled")
+		}
+
+		return a.v3Params.UnmarshalTrap(request, true)
+	}
+
+	header := &gosnmp.GoSNMP{Version: version, Community: a.community}
+	packet, err := header.SnmpDecodePacket(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if packet.Community != a.community {
+		return nil, fmt.Errorf("unknown community %q", packet.Community)
+	}
+
+	return packet, nil
+}
+
+// messageVersion reads the version from the start of an SNMP message, a SEQUENCE starting with the version as a
+// single byte INTEGER.
+func messageVersion(message []byte) (gosnmp.SnmpVersion, error) {
+	if len(message) < 2 || message[0] != byte(gosnmp.Sequence) {
+		return 0, errors.New("not an SNMP message")
+	}
+
+	cursor := 2
+
+	if message[1]&0x80 != 0 {
+		// Long form length, the low bits hold the number of length bytes
+		cursor += int(message[1] & 0x7f)
+	}
+
+	if len(message) < cursor+3 || message[cursor] != byte(gosnmp.Integer) || message[cursor+1] != 1 {
+		return 0, errors.New("invalid SNMP message version")
+	}
+
+	return gosnmp.SnmpVersion(message[cursor+2]), nil
+}
+
+func (a *Agent) encode(packet *gosnmp.SnmpPacket) []byte {
+	if packet.Version == gosnmp.Version3 {
+		securityParameters := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		securityParameters.AuthoritativeEngineBoots = 1
+		securityParameters.AuthoritativeEngineTime = uint32(time.Since(a.startTime) / time.Second)
+	}
+
+	result, err := packet.MarshalMsg()
+
+	if err != nil {
+		fmt.Printf("snmpsim: Unable to encode response: %v\n", err)
+		return nil
+	}
+
+	return result
+}
+
+// isDiscovery returns true for the SNMPv3 engine discovery request, which has no engine ID (RFC 3414 section 4).
+func isDiscovery(packet *gosnmp.SnmpPacket) bool {
+	securityParameters, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+
+	return ok && securityParameters.AuthoritativeEngineID == ""
+}
+
+func (a *Agent) engineIDReport(packet *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	securityParameters := packet.SecurityParameters.Copy().(*gosnmp.UsmSecurityParameters)
+	securityParameters.AuthoritativeEngineID =
+		a.v3Params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID
+
+	packet.PDUType = gosnmp.Report
+	packet.MsgFlags = gosnmp.NoAuthNoPriv
+	packet.SecurityParameters = securityParameters
+	packet.Variables = []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.6.3.15.1.1.4.0", Type: gosnmp.Counter32, Value: uint32(1)},
+	}
+
+	return packet
+}
+
+func (f *Faults) drops(packet *gosnmp.SnmpPacket) bool {
+	if f.GetBulkUnsupported && packet.PDUType == gosnmp.GetBulkRequest {
+		return true
+	}
+
+	if len(packet.Variables) == 0 {
+		return false
+	}
+
+	return slices.ContainsFunc(f.DropPrefixes, func(prefix string) bool {
+		return withinSubtree(packet.Variables[0].Name, prefix)
+	})
+}
+
+func withinSubtree(oid string, root string) bool {
+	oid = "." + strings.TrimPrefix(oid, ".")
+	root = "." + strings.Trim(root, ".")
+
+	return oid == root || strings.HasPrefix(oid, root+".")
+}
+
+func (a *Agent) respond(packet *gosnmp.SnmpPacket, faults *Faults) []gosnmp.SnmpPDU {
+	records := faults.visibleRecords(a.records)
+	result := make([]gosnmp.SnmpPDU, 0, len(packet.Variables))
+
+	switch packet.PDUType {
+	case gosnmp.GetRequest:
+		for _, variable := range packet.Variables {
+			result = append(result, faults.get(records, variable.Name))
+		}
+	case gosnmp.GetNextRequest:
+		for _, variable := range packet.Variables {
+			result = append(result, faults.getNext(records, variable.Name))
+		}
+	case gosnmp.GetBulkRequest:
+		nonRepeaters := min(int(packet.NonRepeaters), len(packet.Variables))
+
+		for _, variable := range packet.Variables[:nonRepeaters] {
+			result = append(result, faults.getNext(records, variable.Name))
+		}
+
+		repeaters := slices.Clone(packet.Variables[nonRepeaters:])
+
+		for repetition := 0; repetition < int(packet.MaxRepetitions) && len(repeaters) != 0; repetition++ {
+			for i := range repeaters {
+				next := faults.getNext(records, repeaters[i].Name)
+				result = append(result, next)
+				repeaters[i] = next
+			}
+
+			if slices.ContainsFunc(repeaters, func(pdu gosnmp.SnmpPDU) bool { return pdu.Type == gosnmp.EndOfMibView }) {
+				break
+			}
+		}
+	default:
+		for _, variable := range packet.Variables {
+			result = append(result, gosnmp.SnmpPDU{Name: variable.Name, Type: gosnmp.NoSuchObject})
+		}
+	}
+
+	return result
+}
+
+// visibleRecords returns the records that aren't hidden by TruncateAfter.
+func (f *Faults) visibleRecords(records []Record) []Record {
+	if len(f.TruncateAfter) == 0 {
+		return records
+	}
+
+	result := make([]Record, 0, len(records))
+	served := make(map[string]int)
+
+	for _, record := range records {
+		visible := true
+
+		for root, limit := range f.TruncateAfter {
+			if withinSubtree(record.Oid, root) {
+				served[root]++
+				visible = visible && served[root] <= limit
+			}
+		}
+
+		if visible {
+			result = append(result, record)
+		}
+	}
+
+	return result
+}
+
+func (f *Faults) get(records []Record, oid string) gosnmp.SnmpPDU {
+	subIds, err := parseOid(oid)
+
+	if err == nil {
+		index, found := slices.BinarySearchFunc(records, subIds, func(record Record, target []uint32) int {
+			return slices.Compare(record.subIds, target)
+		})
+
+		if found {
+			return f.pdu(&records[index])
+		}
+	}
+
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+}
+
+func (f *Faults) getNext(records []Record, oid string) gosnmp.SnmpPDU {
+	subIds, err := parseOid(oid)
+
+	if err == nil {
+		index, found := slices.BinarySearchFunc(records, subIds, func(record Record, target []uint32) int {
+			return slices.Compare(record.subIds, target)
+		})
+
+		if found {
+			index++
+		}
+
+		if index < len(records) {
+			return f.pdu(&records[index])
+		}
+	}
+
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+}
+
+func (f *Faults) pdu(record *Record) gosnmp.SnmpPDU {
+	if slices.ContainsFunc(f.BadTypes, func(root string) bool { return withinSubtree(record.Oid, root) }) &&
+		record.Type != gosnmp.OctetString {
+		return gosnmp.SnmpPDU{Name: record.Oid, Type: gosnmp.OctetString, Value: []byte(fmt.Sprint(record.Value))}
+	}
+
+	return gosnmp.SnmpPDU{Name: record.Oid, Type: record.Type, Value: record.Value}
+}
@@ -0,0 +1,240 @@
+package snmpsim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func startAgent(t *testing.T, config Config) *Agent {
+	t.Helper()
+
+	records, err := LoadFile("testdata/agent.snmprec")
+
+	if err != nil {
+		t.Fatalf("Expected no error loading the fixture, got %v", err)
+	}
+
+	config.Records = records
+	agent, err := Start(config)
+
+	if err != nil {
+		t.Fatalf("Expected no error starting the agent, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = agent.Close() })
+
+	return agent
+}
+
+func connect(t *testing.T, agent *Agent, client *gosnmp.GoSNMP) *gosnmp.GoSNMP {
+	t.Helper()
+
+	client.Target = agent.Address()
+	client.Port = agent.Port()
+	client.Timeout = 200 * time.Millisecond
+	client.Retries = 0
+
+	if client.Version == 0 {
+		client.Version = gosnmp.Version2c
+		client.Community = "public"
+	}
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Expected no error connecting, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = client.Conn.Close() })
+
+	return client
+}
+
+func walkNames(t *testing.T, walk func(string, gosnmp.WalkFunc) error, rootOid string) []string {
+	t.Helper()
+
+	names := make([]string, 0)
+	err := walk(rootOid, func(pdu gosnmp.SnmpPDU) error {
+		names = append(names, pdu.Name)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error walking %s, got %v", rootOid, err)
+	}
+
+	return names
+}
+
+func TestParseSnmpwalk_MatchesSnmprec(t *testing.T) {
+	snmprec, err := LoadFile("testdata/agent.snmprec")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	snmpwalk, err := LoadFile("testdata/agent.snmpwalk")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(snmpwalk) != len(snmprec)+1 {
+		t.Fatalf("Expected %d records, got %d", len(snmprec)+1, len(snmpwalk))
+	}
+
+	if snmpwalk[0].Value != uint32(123456) || snmpwalk[0].Type != gosnmp.TimeTicks {
+		t.Errorf("Expected TimeTicks 123456, got %v %v", snmpwalk[0].Type, snmpwalk[0].Value)
+	}
+
+	if string(snmpwalk[3].Value.([]byte)) != string(snmprec[3].Value.([]byte)) {
+		t.Errorf("Expected %v, got %v", snmprec[3].Value, snmpwalk[3].Value)
+	}
+
+	if snmpwalk[4].Value != 1 {
+		t.Errorf("Expected the enumeration value 1, got %v", snmpwalk[4].Value)
+	}
+}
+
+func TestAgent_V2cWalks_ServeRecordsInOrder(t *testing.T) {
+	agent := startAgent(t, Config{})
+	client := connect(t, agent, &gosnmp.GoSNMP{})
+
+	bulkNames := walkNames(t, client.BulkWalk, ".1.3.6.1.2.1.2.2")
+	names := walkNames(t, client.Walk, ".1.3.6.1.2.1.2.2")
+	expected := []string{".1.3.6.1.2.1.2.2.1.1.1", ".1.3.6.1.2.1.2.2.1.2.1", ".1.3.6.1.2.1.2.2.1.6.1",
+		".1.3.6.1.2.1.2.2.1.10.1"}
+
+	if len(bulkNames) != len(expected) || len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v and %v", expected, bulkNames, names)
+	}
+
+	for i := range expected {
+		if bulkNames[i] != expected[i] || names[i] != expected[i] {
+			t.Errorf("Expected %s, got %s and %s", expected[i], bulkNames[i], names[i])
+		}
+	}
+
+	result, err := client.Get([]string{".1.3.6.1.2.1.1.3.0", ".1.3.6.1.2.1.1.5.0"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Variables[0].Value != uint32(123456) || result.Variables[1].Type != gosnmp.NoSuchObject {
+		t.Errorf("Expected the uptime and no such object, got %v", result.Variables)
+	}
+}
+
+func TestAgent_WrongCommunity_DoesNotRespond(t *testing.T) {
+	agent := startAgent(t, Config{})
+	client := connect(t, agent, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "private"})
+
+	if _, err := client.Get([]string{".1.3.6.1.2.1.1.3.0"}); err == nil {
+		t.Errorf("Expected a timeout")
+	}
+}
+
+func TestAgent_GetBulkUnsupported_BulkWalkTimesOut(t *testing.T) {
+	agent := startAgent(t, Config{Faults: Faults{GetBulkUnsupported: true}})
+	client := connect(t, agent, &gosnmp.GoSNMP{})
+
+	if err := client.BulkWalk(".1.3.6.1.2.1.2.2", func(gosnmp.SnmpPDU) error { return nil }); err == nil {
+		t.Errorf("Expected a timeout")
+	}
+
+	if names := walkNames(t, client.Walk, ".1.3.6.1.2.1.2.2"); len(names) != 4 {
+		t.Errorf("Expected 4 objects, got %v", names)
+	}
+
+	if agent.RequestCount(gosnmp.GetBulkRequest) != 1 {
+		t.Errorf("Expected 1 GetBulk request, got %d", agent.RequestCount(gosnmp.GetBulkRequest))
+	}
+}
+
+func TestAgent_Faults_TruncateDropAndBadTypes(t *testing.T) {
+	agent := startAgent(t, Config{Faults: Faults{
+		TruncateAfter: map[string]int{".1.3.6.1.2.1.2.2": 2},
+		BadTypes:      []string{".1.3.6.1.2.1.2.2.1.1"},
+	}})
+	client := connect(t, agent, &gosnmp.GoSNMP{})
+	var pdus []gosnmp.SnmpPDU
+
+	err := client.BulkWalk(".1.3.6.1.2.1.2.2", func(pdu gosnmp.SnmpPDU) error {
+		pdus = append(pdus, pdu)
+		return nil
+	})
+
+	if err != nil || len(pdus) != 2 {
+		t.Fatalf("Expected 2 objects, got %v (%v)", pdus, err)
+	}
+
+	if pdus[0].Type != gosnmp.OctetString || string(pdus[0].Value.([]byte)) != "1" {
+		t.Errorf("Expected the index as an OCTET STRING, got %v %v", pdus[0].Type, pdus[0].Value)
+	}
+
+	agent.SetFaults(Faults{DropPrefixes: []string{".1.3.6.1.2.1.31"}})
+
+	if err := client.BulkWalk(".1.3.6.1.2.1.31.1.1", func(gosnmp.SnmpPDU) error { return nil }); err == nil {
+		t.Errorf("Expected a timeout")
+	}
+}
+
+func TestAgent_V3AuthPriv_DiscoversEngineAndResponds(t *testing.T) {
+	user := &V3User{
+		UserName:       "monitor",
+		AuthProtocol:   gosnmp.SHA,
+		AuthPassphrase: "authpassword",
+		PrivProtocol:   gosnmp.AES,
+		PrivPassphrase: "privpassword",
+	}
+	agent := startAgent(t, Config{V3User: user})
+	client := connect(t, agent, &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      gosnmp.AuthPriv,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 user.UserName,
+			AuthenticationProtocol:   user.AuthProtocol,
+			AuthenticationPassphrase: user.AuthPassphrase,
+			PrivacyProtocol:          user.PrivProtocol,
+			PrivacyPassphrase:        user.PrivPassphrase,
+		},
+	})
+
+	result, err := client.Get([]string{".1.3.6.1.2.1.1.3.0"})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Variables[0].Value != uint32(123456) {
+		t.Errorf("Expected 123456, got %v", result.Variables[0].Value)
+	}
+
+	if names := walkNames(t, client.BulkWalk, ".1.3.6.1.2.1.31"); len(names) != 1 {
+		t.Errorf("Expected 1 object, got %v", names)
+	}
+}
+
+func TestAgent_V3WrongPassphrase_DoesNotRespond(t *testing.T) {
+	agent := startAgent(t, Config{V3User: &V3User{
+		UserName:       "monitor",
+		AuthProtocol:   gosnmp.SHA,
+		AuthPassphrase: "authpassword",
+	}})
+	client := connect(t, agent, &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		SecurityModel: gosnmp.UserSecurityModel,
+		MsgFlags:      gosnmp.AuthNoPriv,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 "monitor",
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "wrongpassword",
+		},
+	})
+
+	if _, err := client.Get([]string{".1.3.6.1.2.1.1.3.0"}); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
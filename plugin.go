@@ -3,17 +3,22 @@ package netmon
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/avanha/pmaas-plugin-netmon/config"
 	"github.com/avanha/pmaas-plugin-netmon/data"
 	"github.com/avanha/pmaas-plugin-netmon/entities"
+	"github.com/avanha/pmaas-plugin-netmon/internal/alerting"
 	"github.com/avanha/pmaas-plugin-netmon/internal/common"
 	"github.com/avanha/pmaas-plugin-netmon/internal/host"
 	"github.com/avanha/pmaas-plugin-netmon/internal/http"
+	"github.com/avanha/pmaas-plugin-netmon/internal/maintenance"
 	"github.com/avanha/pmaas-plugin-netmon/internal/monitoring"
 	"github.com/avanha/pmaas-plugin-netmon/internal/netinterface"
+	"github.com/avanha/pmaas-plugin-netmon/internal/notification"
 	"github.com/avanha/pmaas-spi"
 	"github.com/avanha/pmaas-spi/tracking"
 )
@@ -27,10 +32,22 @@ type plugin struct {
 	hosts         []*host.Host
 	entityCounter int
 	httpHandler   *http.Handler
+	maintenance   *maintenance.Manager
+	alerts        *alerting.Engine
+	notifications *notification.Dispatcher
+	scheduler     *monitoring.Scheduler
+	metrics       *monitoring.Metrics
+	clock         common.Clock
 }
 
 func NewPluginConfig() config.PluginConfig {
-	return config.PluginConfig{}
+	return config.PluginConfig{
+		AdHocMaintenanceSuppressEvents: true,
+		RateHistoryRetention:           data.DefaultRateHistoryRetention,
+		MaxConcurrentScans:             16,
+		MaxConcurrentPings:             16,
+		MaxConcurrentSnmpWalks:         8,
+	}
 }
 
 type Plugin interface {
@@ -42,6 +59,7 @@ func NewPlugin(config config.PluginConfig) Plugin {
 	instance := &plugin{
 		config:      config,
 		httpHandler: http.NewHandler(),
+		clock:       common.SystemClock,
 	}
 
 	return instance
@@ -50,7 +68,8 @@ func NewPlugin(config config.PluginConfig) Plugin {
 func (p *plugin) Init(container spi.IPMAASContainer) {
 	p.container = container
 	p.processConfig()
-	p.httpHandler.Init(p.container, &entityStoreAdapter{parent: p})
+	adapter := &entityStoreAdapter{parent: p}
+	p.httpHandler.Init(p.container, adapter, adapter, adapter)
 }
 
 func (p *plugin) Start() {
@@ -91,6 +110,7 @@ func (p *plugin) processConfig() {
 		hostInstance := host.NewHost(
 			fmt.Sprintf("Host_%v", p.nextEntityId()),
 			configuredHost, hostTrackingConfig, p.container)
+		hostInstance.SetClock(p.clock)
 		p.hosts = append(p.hosts, hostInstance)
 		for key, configuredNetInterface := range configuredHost.NetInterfaces {
 			trackingConfig := defaultTrackingConfig.Clone()
@@ -106,10 +126,46 @@ func (p *plugin) processConfig() {
 				hostInstance.Id(),
 				fmt.Sprintf("NetworkInterface_%v", p.nextEntityId()),
 				trackingConfig,
-				*configuredNetInterface)
+				*configuredNetInterface,
+				p.config.RateHistoryRetention,
+				p.container)
 			hostInstance.AddNetInterface(key, netInterfaceInstance)
 		}
 	}
+
+	// Order the hosts so that parents are always processed before the hosts that depend on them
+	sortedHosts, err := host.ResolveDependencies(p.hosts)
+
+	if err != nil {
+		fmt.Printf("%T Invalid host dependencies: %v\n", p, err)
+	}
+
+	p.hosts = sortedHosts
+
+	// The manager is usable even if some windows are invalid, those are just skipped
+	p.maintenance, err = maintenance.NewManager(p.config.MaintenanceWindows, p.config.AdHocMaintenanceSuppressEvents)
+
+	if err != nil {
+		fmt.Printf("%T Invalid maintenance windows: %v\n", p, err)
+	}
+
+	p.alerts, err = alerting.NewEngine(p.config.AlertRules)
+
+	if err != nil {
+		fmt.Printf("%T Invalid alert rules: %v\n", p, err)
+	}
+
+	hostGroups := make(map[string][]string, len(p.config.Hosts))
+
+	for _, configuredHost := range p.config.Hosts {
+		hostGroups[configuredHost.Name] = configuredHost.Groups
+	}
+
+	p.notifications, err = notification.NewDispatcher(p.config.Notifications, hostGroups)
+
+	if err != nil {
+		fmt.Printf("%T Invalid notification channels: %v\n", p, err)
+	}
 }
 
 func (p *plugin) onMonitoringGoRoutinesStopped() {
@@ -131,14 +187,22 @@ func (p *plugin) startMonitoringGoRoutines() {
 
 		if err != nil {
 			fmt.Printf("Error enqueuing updateHost callback: %s\n", err)
+			p.metrics.RecordEnqueueFailure()
 		}
 	}
 
 	p.monitors = sync.WaitGroup{}
 	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.notifications.Start(p.ctx, &p.monitors)
+	p.scheduler = monitoring.NewScheduler(monitoring.SchedulerLimits{
+		Scans:     p.config.MaxConcurrentScans,
+		Pings:     p.config.MaxConcurrentPings,
+		SnmpWalks: p.config.MaxConcurrentSnmpWalks,
+	}, p.clock)
+	p.metrics = monitoring.NewMetrics()
 
 	for _, hostInstance := range p.hosts {
-		monitoringTask := monitoring.CreateTask(p.ctx, hostInstance, updateHostFunction)
+		monitoringTask := monitoring.CreateTask(p.ctx, hostInstance, p.scheduler, p.metrics, updateHostFunction)
 		p.monitors.Go(monitoringTask.Run)
 	}
 }
@@ -160,11 +224,12 @@ func (p *plugin) registerEntities() {
 			hostStubFactoryFn)
 
 		if err != nil {
-			fmt.Printf("Error registering %s: %s\n", err)
+			fmt.Printf("Error registering %s: %s\n", hostName, err)
 			continue
 		}
 
 		hostInstance.SetPmaasEntityId(hostPmaasId)
+		hostInstance.RegisterConfiguredListeners(p.container)
 
 		for networkInterfaceKey, networkInterfaceInstance := range hostInstance.NetInterfaces() {
 			networkInterfaceInstance.SetHostPmaasEntityId(hostPmaasId)
@@ -209,6 +274,7 @@ func (p *plugin) deregisterEntities() {
 			networkInterfaceInstance.CloseStubIfPresent()
 		}
 
+		hostInstance.DeregisterConfiguredListeners(p.container)
 		err := p.container.DeregisterEntity(hostInstance.PmaasEntityId())
 
 		if err == nil {
@@ -228,19 +294,96 @@ func getInterfaceName(hostInstance *host.Host, networkInterfaceKey string) strin
 func (p *plugin) updateHost(hostInstance *host.Host, data *common.HostData) {
 	fmt.Printf("updateHost %s with %v\n", hostInstance.Name(), data)
 	events := make([]any, 0, 10)
+	hostInstance.SetMaintenance(p.maintenance.Active(hostInstance.Name(), hostInstance.Groups(), data.LastUpdateTime))
 	hostInstance.Update(data, &events)
+	p.alerts.Evaluate(hostInstance, data.LastUpdateTime, &events)
 
 	// Broadcast accumulated events
 	for _, event := range events {
-		p.broadcastEvent(hostInstance.PmaasEntityId(), event)
+		p.broadcastEvent(hostInstance, event, data.LastUpdateTime)
 	}
+
+	// Hosts are kept in dependency order, so each parent's state is final by the time its dependents
+	// are refreshed
+	for _, dependentHost := range p.hosts {
+		events = events[:0]
+		dependentHost.RefreshDependencyState(data.LastUpdateTime, &events)
+
+		for _, event := range events {
+			p.broadcastEvent(dependentHost, event, data.LastUpdateTime)
+		}
+	}
+}
+
+func (p *plugin) startAdHocMaintenance(hostName string, hostGroup string, duration time.Duration) error {
+	if err := p.validateMaintenanceTarget(hostName, hostGroup); err != nil {
+		return err
+	}
+
+	return p.maintenance.StartAdHoc(hostName, hostGroup, p.clock.Now(), duration)
 }
 
-func (p *plugin) broadcastEvent(sourceEntityId string, event any) {
-	err := p.container.BroadcastEvent(sourceEntityId, event)
+func (p *plugin) endAdHocMaintenance(hostName string, hostGroup string) error {
+	return p.maintenance.EndAdHoc(hostName, hostGroup)
+}
+
+func (p *plugin) requestScan(hostName string) error {
+	index := slices.IndexFunc(p.hosts, func(h *host.Host) bool { return h.Name() == hostName })
+
+	if index < 0 {
+		return fmt.Errorf("unknown host %s", hostName)
+	}
+
+	return p.hosts[index].RequestScan()
+}
+
+func (p *plugin) validateMaintenanceTarget(hostName string, hostGroup string) error {
+	if hostName != "" {
+		if !slices.ContainsFunc(p.hosts, func(h *host.Host) bool { return h.Name() == hostName }) {
+			return fmt.Errorf("unknown host %s", hostName)
+		}
+
+		return nil
+	}
+
+	if hostGroup != "" {
+		if !slices.ContainsFunc(p.hosts, func(h *host.Host) bool { return slices.Contains(h.Groups(), hostGroup) }) {
+			return fmt.Errorf("unknown host group %s", hostGroup)
+		}
+	}
+
+	return nil
+}
+
+func (p *plugin) broadcastEvent(hostInstance *host.Host, event any, eventTime time.Time) {
+	err := p.container.BroadcastEvent(hostInstance.PmaasEntityId(), event)
 	if err != nil {
-		fmt.Printf("%T Error broadcasting event %V: %V", p, event, err)
+		fmt.Printf("%T Error broadcasting event %v: %v\n", p, event, err)
+	}
+
+	hostInstance.RecordEvent(event, eventTime)
+	p.notifications.Notify(event)
+}
+
+// queryEventJournal returns the journal entries of all hosts that match the filter, newest first.
+func (p *plugin) queryEventJournal(filter data.JournalFilter) []data.JournalEntry {
+	result := make([]data.JournalEntry, 0)
+
+	for _, hostInstance := range p.hosts {
+		for _, entry := range hostInstance.Journal() {
+			if filter.Matches(&entry) {
+				result = append(result, entry)
+			}
+		}
 	}
+
+	slices.SortStableFunc(result, func(a, b data.JournalEntry) int { return b.Time.Compare(a.Time) })
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+
+	return result
 }
 
 func (p *plugin) getStatusAndEntities() common.StatusAndEntities {
@@ -256,6 +399,10 @@ func (p *plugin) getStatusAndEntities() common.StatusAndEntities {
 	}
 
 	return common.StatusAndEntities{
-		Hosts: hostData,
+		Status: monitoring.Status(p.metrics, p.scheduler),
+		Hosts:  hostData,
+		Alerts: p.alerts.ActiveAlerts(),
+
+		NotificationDeliveries: p.notifications.DeliveryLog(),
 	}
 }
@@ -0,0 +1,360 @@
+package netmon
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	"github.com/avanha/pmaas-plugin-netmon/entities"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/fakecontainer"
+	"github.com/avanha/pmaas-plugin-netmon/internal/netinterface"
+	"github.com/avanha/pmaas-spi/tracking"
+)
+
+// newTestPlugin creates the plugin and initializes it with a fake container, on the container's plugin goroutine,
+// as the server would.
+func newTestPlugin(t *testing.T, pluginConfig config.PluginConfig) (*plugin, *fakecontainer.Container) {
+	container := fakecontainer.New()
+	t.Cleanup(container.Close)
+	p := NewPlugin(pluginConfig).(*plugin)
+	runOnPluginGoRoutine(t, container, func() { p.Init(container) })
+
+	return p, container
+}
+
+func runOnPluginGoRoutine(t *testing.T, container *fakecontainer.Container, f func()) {
+	if err := container.RunOnPluginGoRoutine(f); err != nil {
+		t.Fatalf("Expected no error running on the plugin goroutine, got %v", err)
+	}
+}
+
+// startTestPlugin starts the plugin, and stops it when the test completes, unless the test stops it first.
+func startTestPlugin(t *testing.T, p *plugin, container *fakecontainer.Container) {
+	runOnPluginGoRoutine(t, container, p.Start)
+	t.Cleanup(func() {
+		if p.ctx.Err() == nil {
+			stopTestPlugin(t, p, container)
+		}
+	})
+}
+
+// stopTestPlugin stops the plugin and runs the callbacks it returns on the plugin goroutine.
+func stopTestPlugin(t *testing.T, p *plugin, container *fakecontainer.Container) {
+	var callbacks chan func()
+	runOnPluginGoRoutine(t, container, func() { callbacks = p.Stop() })
+
+	select {
+	case callback := <-callbacks:
+		runOnPluginGoRoutine(t, container, callback)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the monitoring goroutines to stop")
+	}
+}
+
+func TestPluginInit_CreatesHostsAndAddsRoutes(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+
+	if container.Route("/plugins/netmon/") == nil {
+		t.Errorf("Expected a route for /plugins/netmon/, got routes %v", container.Routes())
+	}
+
+	if container.Route("/plugins/netmon/events.json") == nil {
+		t.Errorf("Expected a route for /plugins/netmon/events.json, got routes %v", container.Routes())
+	}
+
+	var hostNames []string
+	runOnPluginGoRoutine(t, container, func() {
+		for _, hostInstance := range p.hosts {
+			hostNames = append(hostNames, hostInstance.Name())
+		}
+	})
+
+	if !slices.Equal(hostNames, []string{"router", "server"}) {
+		t.Errorf("Expected hosts [router server], got %v", hostNames)
+	}
+}
+
+func TestPluginStart_RegistersEntities(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	startTestPlugin(t, p, container)
+
+	registered := container.Entities()
+
+	if len(registered) != 3 {
+		t.Fatalf("Expected 3 entities, got %d", len(registered))
+	}
+
+	var hostEntityId string
+	runOnPluginGoRoutine(t, container, func() { hostEntityId = p.hosts[0].PmaasEntityId() })
+
+	if registered[0].Id != hostEntityId || registered[0].EntityType != entities.HostType {
+		t.Errorf("Expected host entity %s, got %s of type %v", hostEntityId, registered[0].Id, registered[0].EntityType)
+	}
+
+	interfaceName := "host_router_interface_name:eth0"
+
+	if registered[1].EntityType != entities.NetworkInterfaceType || registered[1].Name != interfaceName {
+		t.Errorf("Expected interface entity %s, got %s of type %v", interfaceName,
+			registered[1].Name, registered[1].EntityType)
+	}
+
+	var stub any
+	var err error
+	runOnPluginGoRoutine(t, container, func() { stub, err = registered[0].StubFactoryFn() })
+
+	if err != nil {
+		t.Fatalf("Expected no error creating the stub, got %v", err)
+	}
+
+	// The stub calls the host on the plugin goroutine
+	if name := stub.(entities.Host).Name(); name != "router" {
+		t.Errorf("Expected stub name router, got %s", name)
+	}
+}
+
+func TestPluginStop_DeregistersEntitiesAndListeners(t *testing.T) {
+	pluginConfig := newScenarioConfig()
+	config.AddHostEventListener(&pluginConfig.Hosts[0],
+		func(event netmonevents.HostReachabilityChangeEvent) {}, nil)
+	p, container := newTestPlugin(t, pluginConfig)
+	startTestPlugin(t, p, container)
+
+	if container.ReceiverCount() != 1 {
+		t.Fatalf("Expected 1 event receiver, got %d", container.ReceiverCount())
+	}
+
+	stopTestPlugin(t, p, container)
+
+	if len(container.Entities()) != 0 {
+		t.Errorf("Expected no entities, got %v", container.Entities())
+	}
+
+	if container.ReceiverCount() != 0 {
+		t.Errorf("Expected no event receivers, got %d", container.ReceiverCount())
+	}
+
+	runOnPluginGoRoutine(t, container, func() {
+		if id := p.hosts[0].PmaasEntityId(); id != "" {
+			t.Errorf("Expected the host's entity id to be cleared, got %s", id)
+		}
+	})
+}
+
+func TestPluginUpdateHost_ConfiguredListeners_InvokedOnServerGoRoutine(t *testing.T) {
+	pluginConfig := NewPluginConfig()
+	router := pluginConfig.AddHost("router", "192.168.1.1")
+	eth0 := router.AddNetInterfaceByName("eth0")
+	reachabilityEvents := make([]netmonevents.HostReachabilityChangeEvent, 0)
+	config.AddHostEventListener(router,
+		func(event netmonevents.HostReachabilityChangeEvent) {
+			reachabilityEvents = append(reachabilityEvents, event)
+		},
+		config.ReachabilityChangesTo(data.ReachabilityReachable))
+	addressEvents := make([]netmonevents.HostInterfaceAddressChangeEvent, 0)
+	eth0.AddOnIpAddressChangeListener(
+		func(event netmonevents.HostInterfaceAddressChangeEvent) {
+			addressEvents = append(addressEvents, event)
+		})
+	p, container := newTestPlugin(t, pluginConfig)
+	startTestPlugin(t, p, container)
+
+	now := time.Now()
+
+	for i := range 2 {
+		hostData := common.HostData{
+			LastUpdateTime:   now.Add(time.Duration(i) * time.Minute),
+			CollectorResults: make(map[string]common.CollectorResult),
+		}
+		pingResult(0)(&hostData)
+		snmpResult(100+uint64(i)*60, common.IfData{
+			Index:       1,
+			Name:        "eth0",
+			OperStatus:  1,
+			AdminStatus: 1,
+			IpAddresses: []common.IpMapEntry{{IpVersion: 4, Address: net.ParseIP("192.168.1.1"), IfIndex: 1}},
+		})(&hostData)
+		runOnPluginGoRoutine(t, container, func() { p.updateHost(p.hosts[0], &hostData) })
+	}
+
+	if err := container.SyncServerGoRoutine(); err != nil {
+		t.Fatalf("Expected no error syncing the server goroutine, got %v", err)
+	}
+
+	if len(reachabilityEvents) != 1 {
+		t.Fatalf("Expected 1 reachability event, got %d", len(reachabilityEvents))
+	}
+
+	if reachabilityEvents[0].Name != "router" {
+		t.Errorf("Expected an event for router, got %s", reachabilityEvents[0].Name)
+	}
+
+	if len(addressEvents) != 1 {
+		t.Fatalf("Expected 1 address event, got %d", len(addressEvents))
+	}
+
+	if addressEvents[0].NetInterfaceName != "eth0" {
+		t.Errorf("Expected an event for eth0, got %s", addressEvents[0].NetInterfaceName)
+	}
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](broadcastEvents(container)) == 0 {
+		t.Errorf("Expected the reachability change to be broadcast")
+	}
+}
+
+func broadcastEvents(container *fakecontainer.Container) []any {
+	result := make([]any, 0)
+
+	for _, event := range container.Events() {
+		result = append(result, event.Event)
+	}
+
+	return result
+}
+
+func TestHostSetHistoryRepo_RestoresHostData(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	hostInstance := p.hosts[0]
+	repo := &fakecontainer.HistoryRepo{
+		Sample: tracking.DataSample{
+			Data: data.HostData{
+				Name:                  "router",
+				Reachability:          data.ReachabilityUnreachable,
+				UnreachableStartCount: 5,
+			},
+		},
+	}
+	runOnPluginGoRoutine(t, container, func() {
+		if err := hostInstance.SetHistoryRepo(repo); err != nil {
+			t.Errorf("Expected no error setting the history repo, got %v", err)
+		}
+	})
+	hostInstance.WaitForInitialLoad()
+
+	var hostData data.HostData
+	runOnPluginGoRoutine(t, container, func() { hostData = hostInstance.HostData() })
+
+	if repo.RequestCount() != 1 {
+		t.Errorf("Expected 1 request for the most recent sample, got %d", repo.RequestCount())
+	}
+
+	if hostData.Reachability != data.ReachabilityUnreachable {
+		t.Errorf("Expected reachability %d, got %d", data.ReachabilityUnreachable, hostData.Reachability)
+	}
+
+	if hostData.UnreachableStartCount != 5 {
+		t.Errorf("Expected unreachable start count 5, got %d", hostData.UnreachableStartCount)
+	}
+}
+
+func TestNetInterfaceSetHistoryRepo_RestoresDataUsage(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	cycleStart := time.Date(2023, 10, 1, 0, 0, 0, 0, time.Local)
+	repo := &fakecontainer.HistoryRepo{
+		Sample: tracking.DataSample{
+			Data: data.NetInterfaceData{
+				BillingCycleStart: cycleStart,
+				CycleBytesIn:      5000,
+				CycleBytesOut:     7000,
+			},
+		},
+	}
+
+	var netInterface *netinterface.NetInterface
+
+	for _, n := range p.hosts[0].NetInterfaces() {
+		netInterface = n
+	}
+
+	runOnPluginGoRoutine(t, container, func() {
+		if err := netInterface.SetHistoryRepo(repo); err != nil {
+			t.Errorf("Expected no error setting the history repo, got %v", err)
+		}
+	})
+
+	// Restoring the interface isn't signalled, so wait for the restored data to show up
+	var interfaceData data.NetInterfaceData
+	deadline := time.Now().Add(5 * time.Second)
+
+	for interfaceData.BillingCycleStart.IsZero() && time.Now().Before(deadline) {
+		runOnPluginGoRoutine(t, container, func() { interfaceData = netInterface.InterfaceData() })
+	}
+
+	if !interfaceData.BillingCycleStart.Equal(cycleStart) {
+		t.Fatalf("Expected billing cycle start %v, got %v", cycleStart, interfaceData.BillingCycleStart)
+	}
+
+	if interfaceData.CycleBytesIn != 5000 || interfaceData.CycleBytesOut != 7000 {
+		t.Errorf("Expected cycle bytes 5000/7000, got %d/%d", interfaceData.CycleBytesIn, interfaceData.CycleBytesOut)
+	}
+}
+
+func TestPluginScanRoute_KnownAndUnknownHosts_RequestsScan(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	handler := container.Route("/plugins/netmon/scan")
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/plugins/netmon/scan?host=router", nil))
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Errorf("Expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+	}
+
+	if pending := len(p.hosts[0].ScanRequests()); pending != 1 {
+		t.Errorf("Expected 1 pending scan request, got %d", pending)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/plugins/netmon/scan?host=unknown", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestHostStubRequestScan_RequestsScan(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	var stub entities.Host
+	runOnPluginGoRoutine(t, container, func() { stub = p.hosts[1].GetStub(container) })
+
+	if err := stub.RequestScan(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pending := len(p.hosts[1].ScanRequests()); pending != 1 {
+		t.Errorf("Expected 1 pending scan request, got %d", pending)
+	}
+}
+
+func TestPluginStatusRoute_Started_WritesStatus(t *testing.T) {
+	p, container := newTestPlugin(t, newScenarioConfig())
+	startTestPlugin(t, p, container)
+	runOnPluginGoRoutine(t, container, func() { p.metrics.RecordEnqueueFailure() })
+
+	recorder := httptest.NewRecorder()
+	container.Route("/plugins/netmon/status.json")(
+		recorder, httptest.NewRequest(http.MethodGet, "/plugins/netmon/status.json", nil))
+
+	var status data.PluginStatus
+	body := recorder.Body.String()
+
+	if err := json.Unmarshal([]byte(body), &status); err != nil {
+		t.Fatalf("Expected a JSON status, got %v", err)
+	}
+
+	if !strings.Contains(body, "\"enqueueFailures\":1") {
+		t.Errorf("Expected camelCase field names, got %s", body)
+	}
+
+	if status.EnqueueFailures != 1 {
+		t.Errorf("Expected 1 enqueue failure, got %d", status.EnqueueFailures)
+	}
+}
@@ -0,0 +1,232 @@
+package netmon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avanha/pmaas-plugin-netmon/config"
+	"github.com/avanha/pmaas-plugin-netmon/data"
+	netmonevents "github.com/avanha/pmaas-plugin-netmon/events"
+	"github.com/avanha/pmaas-plugin-netmon/internal/common"
+	"github.com/avanha/pmaas-plugin-netmon/internal/host"
+	"github.com/avanha/pmaas-spi"
+)
+
+// scenarioContainer records the broadcast events.  The container methods the scenarios don't use are left
+// unimplemented.
+type scenarioContainer struct {
+	spi.IPMAASContainer
+	events []any
+}
+
+func (c *scenarioContainer) BroadcastEvent(_ string, event any) error {
+	c.events = append(c.events, event)
+	return nil
+}
+
+// scenario feeds scripted scan results through plugin.updateHost, as the monitoring tasks would, with a manual
+// clock.
+type scenario struct {
+	t         *testing.T
+	plugin    *plugin
+	clock     *common.ManualClock
+	container *scenarioContainer
+}
+
+// The first scan is at 23:50, shortly before midnight
+var scenarioStartTime = time.Date(2023, 10, 10, 23, 49, 0, 0, time.UTC)
+
+func newScenario(t *testing.T, pluginConfig config.PluginConfig) *scenario {
+	s := &scenario{
+		t:         t,
+		plugin:    NewPlugin(pluginConfig).(*plugin),
+		clock:     common.NewManualClock(scenarioStartTime),
+		container: &scenarioContainer{},
+	}
+	s.plugin.container = s.container
+	s.plugin.clock = s.clock
+	s.plugin.processConfig()
+
+	return s
+}
+
+func (s *scenario) host(name string) *host.Host {
+	for _, hostInstance := range s.plugin.hosts {
+		if hostInstance.Name() == name {
+			return hostInstance
+		}
+	}
+
+	s.t.Fatalf("Unknown host %s", name)
+
+	return nil
+}
+
+func (s *scenario) netInterfaceData(hostName string, key string) data.NetInterfaceData {
+	for interfaceKey, netInterface := range s.host(hostName).NetInterfaces() {
+		if interfaceKey == key {
+			return netInterface.InterfaceData()
+		}
+	}
+
+	s.t.Fatalf("Unknown interface %s on host %s", key, hostName)
+
+	return data.NetInterfaceData{}
+}
+
+// scan advances the clock by a minute, then updates the host with the scan results, and returns the events
+// broadcast for the scan.
+func (s *scenario) scan(hostName string, results ...scanResult) []any {
+	now := s.clock.Advance(time.Minute)
+	hostData := common.HostData{
+		LastUpdateTime:   now,
+		CollectorResults: make(map[string]common.CollectorResult),
+	}
+
+	for _, result := range results {
+		result(&hostData)
+	}
+
+	firstEvent := len(s.container.events)
+	s.plugin.updateHost(s.host(hostName), &hostData)
+
+	return s.container.events[firstEvent:]
+}
+
+type scanResult func(hostData *common.HostData)
+
+func pingResult(packetLoss float64) scanResult {
+	return func(hostData *common.HostData) {
+		hostData.CollectorResults[common.CollectorPing] = common.CollectorResult{StartTime: hostData.LastUpdateTime}
+		hostData.PingStatus = "OK"
+		hostData.PingPacketsSent = 4
+		hostData.PingPacketLoss = packetLoss
+		hostData.PingRttAvg = 10 * time.Millisecond
+	}
+}
+
+func snmpResult(uptimeSeconds uint64, interfaces ...common.IfData) scanResult {
+	return func(hostData *common.HostData) {
+		hostData.CollectorResults[common.CollectorSnmp] = common.CollectorResult{StartTime: hostData.LastUpdateTime}
+		hostData.SnmpStatus = "OK"
+		hostData.SnmpSuccess = true
+		hostData.UptimeSeconds = uptimeSeconds
+		hostData.IfDataList = interfaces
+	}
+}
+
+func countEvents[T any](events []any) int {
+	count := 0
+
+	for _, event := range events {
+		if _, ok := event.(T); ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+func newScenarioConfig() config.PluginConfig {
+	pluginConfig := NewPluginConfig()
+	pluginConfig.AddHost("router", "192.168.1.1").AddNetInterfaceByName("eth0")
+	pluginConfig.AddHost("server", "192.168.1.2").AddDependency("router")
+
+	return pluginConfig
+}
+
+func TestScenario_HostGoesDown_ReachabilityChangesAfterThreshold(t *testing.T) {
+	s := newScenario(t, newScenarioConfig())
+	s.scan("server", pingResult(0))
+
+	for i := 0; i < 2; i++ {
+		if events := s.scan("server", pingResult(100)); countEvents[netmonevents.HostReachabilityChangeEvent](events) != 0 {
+			t.Fatalf("Expected no reachability change after %d failures, got %v", i+1, events)
+		}
+	}
+
+	events := s.scan("server", pingResult(100))
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](events) != 1 {
+		t.Fatalf("Expected a reachability change, got %v", events)
+	}
+
+	hostData := s.host("server").HostData()
+
+	if hostData.Reachability != data.ReachabilityUnreachable || hostData.UnreachableStartCount != 1 {
+		t.Errorf("Expected %d, got %d", data.ReachabilityUnreachable, hostData.Reachability)
+	}
+
+	if !hostData.LastReachabilityChangeTime.Equal(s.clock.Now()) {
+		t.Errorf("Expected the change at %v, got %v", s.clock.Now(), hostData.LastReachabilityChangeTime)
+	}
+}
+
+func TestScenario_ParentDown_ChildUnreachableDueToParent(t *testing.T) {
+	s := newScenario(t, newScenarioConfig())
+	s.scan("router", pingResult(0))
+	s.scan("server", pingResult(0))
+
+	for i := 0; i < 3; i++ {
+		s.scan("router", pingResult(100))
+	}
+
+	for i := 0; i < 3; i++ {
+		s.scan("server", pingResult(100))
+	}
+
+	hostData := s.host("server").HostData()
+
+	if hostData.RootCauseHost != "router" {
+		t.Errorf("Expected the router to be the root cause, got %q", hostData.RootCauseHost)
+	}
+}
+
+func TestScenario_TrafficAcrossMidnight_RatesAndDailyTotals(t *testing.T) {
+	s := newScenario(t, newScenarioConfig())
+	eth0 := common.IfData{Index: 1, Name: "eth0", OperStatus: 1, AdminStatus: 1, HCInOctets: 1_000_000}
+	s.scan("router", snmpResult(1000, eth0))
+
+	// 60,000 bytes per second, for 20 minutes, 10 of them before midnight
+	for i := 0; i < 20; i++ {
+		eth0.HCInOctets += 3_600_000
+		s.scan("router", snmpResult(uint64(1060+60*i), eth0))
+	}
+
+	interfaceData := s.netInterfaceData("router", config.GetInterfaceNameKey("eth0"))
+
+	if interfaceData.BytesInRate != 60_000 {
+		t.Errorf("Expected a rate of 60000, got %d", interfaceData.BytesInRate)
+	}
+
+	today := interfaceData.DailyBytesIn[interfaceData.CurrentDayIndex]
+	yesterday := interfaceData.DailyBytesIn[(interfaceData.CurrentDayIndex+data.NetInterfaceDailyHistorySize-1)%
+		data.NetInterfaceDailyHistorySize]
+
+	if today != 36_000_000 || yesterday != 36_000_000 {
+		t.Errorf("Expected 36000000 bytes on each day, got %d and %d", yesterday, today)
+	}
+
+	if !interfaceData.LastUpdateTime.Equal(s.clock.Now()) {
+		t.Errorf("Expected the last update at %v, got %v", s.clock.Now(), interfaceData.LastUpdateTime)
+	}
+}
+
+func TestScenario_SnmpNotCollected_InterfacesKeepState(t *testing.T) {
+	s := newScenario(t, newScenarioConfig())
+	eth0 := common.IfData{Index: 1, Name: "eth0", OperStatus: 1, AdminStatus: 1}
+	s.scan("router", pingResult(0), snmpResult(1000, eth0))
+	lastUpdateTime := s.clock.Now()
+
+	events := s.scan("router", pingResult(0))
+
+	if countEvents[netmonevents.HostReachabilityChangeEvent](events) != 0 {
+		t.Errorf("Expected no reachability change, got %v", events)
+	}
+
+	interfaceData := s.netInterfaceData("router", config.GetInterfaceNameKey("eth0"))
+
+	if !interfaceData.LastUpdateTime.Equal(lastUpdateTime) || interfaceData.Status != data.InterfaceStatusUp {
+		t.Errorf("Expected the interface to keep its state, got %v %s", interfaceData.LastUpdateTime, interfaceData.Status)
+	}
+}